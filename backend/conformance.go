@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/ChaiyawutTar/MyList/internal/adapters/repositories/conformance"
+	"github.com/ChaiyawutTar/MyList/internal/config"
+)
+
+const conformanceUsage = "usage: mylist conformance [memory] [sqlite] [database]"
+
+// runConformance implements the conformance subcommand, which checks that
+// the named repository adapters behave alike:
+//
+//	memory    the in-memory adapters
+//	sqlite    the SQLite adapters, on a fresh temporary database
+//	database  the adapters for DATABASE_URL, which keeps the test data,
+//	          so point it at a throwaway database
+//
+// It checks memory and sqlite by default.
+func runConformance(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		args = []string{"memory", "sqlite"}
+	}
+
+	passed := true
+	for _, name := range args {
+		var repos *storage
+		switch name {
+		case "memory":
+			repos = memoryStorage()
+		case "sqlite", "database":
+			databaseURL := cfg.DatabaseURL
+			if name == "sqlite" {
+				dir, err := os.MkdirTemp("", "mylist-conformance")
+				if err != nil {
+					return err
+				}
+				defer os.RemoveAll(dir)
+				databaseURL = "sqlite:" + filepath.Join(dir, "mylist.db")
+			}
+
+			db, err := openDatabase(databaseURL)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+			if err := migrateOnStart(db); err != nil {
+				return err
+			}
+			repos = db.storage()
+		default:
+			return errors.New(conformanceUsage)
+		}
+
+		reporter := logReporter{adapter: name}
+		for _, check := range conformance.Checks {
+			if check.Run(context.Background(), reporter, repos.conformance()) {
+				log.Printf("ok   %s: %s", name, check.Name)
+			} else {
+				passed = false
+			}
+		}
+	}
+
+	if !passed {
+		return errors.New("conformance checks failed")
+	}
+	return nil
+}
+
+// logReporter logs the failures of conformance checks.
+type logReporter struct {
+	adapter string
+}
+
+func (r logReporter) Errorf(format string, args ...interface{}) {
+	log.Printf("FAIL %s: "+format, append([]interface{}{r.adapter}, args...)...)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ChaiyawutTar/MyList/internal/adapters/repositories/conformance"
+)
+
+func TestConformanceMemory(t *testing.T) {
+	runConformanceChecks(t, memoryStorage())
+}
+
+func TestConformanceSQLite(t *testing.T) {
+	runConformanceChecks(t, openTestDatabase(t, "sqlite:"+filepath.Join(t.TempDir(), "mylist.db")))
+}
+
+// TestConformancePostgres runs the checks against TEST_DATABASE_URL, which
+// keeps the test data, so point it at a throwaway database.
+func TestConformancePostgres(t *testing.T) {
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	runConformanceChecks(t, openTestDatabase(t, databaseURL))
+}
+
+// openTestDatabase opens and migrates the database, which is closed when
+// the test ends.
+func openTestDatabase(t *testing.T, databaseURL string) *storage {
+	t.Helper()
+	db, err := openDatabase(databaseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := migrateOnStart(db); err != nil {
+		t.Fatal(err)
+	}
+	return db.storage()
+}
+
+func runConformanceChecks(t *testing.T, repos *storage) {
+	for _, check := range conformance.Checks {
+		check := check
+		t.Run(check.Name, func(t *testing.T) {
+			check.Run(context.Background(), t, repos.conformance())
+		})
+	}
+}
@@ -2,27 +2,28 @@ package http
 
 import (
     "encoding/json"
-    "fmt"
     "net/http"
+    "net/url"
 	"log"
     
     "github.com/markbates/goth/gothic"
+    "github.com/ChaiyawutTar/MyList/internal/adapters/handlers/middleware"
+    "github.com/ChaiyawutTar/MyList/internal/adapters/handlers/problem"
     "github.com/ChaiyawutTar/MyList/internal/core/domain"
     "github.com/ChaiyawutTar/MyList/internal/core/ports"
-    "github.com/ChaiyawutTar/MyList/pkg/auth"
     "github.com/go-chi/chi/v5"
 )
 
 type AuthHandler struct {
     userService ports.UserService
-    oauthManager *auth.OAuthManager
+    tokenService ports.TokenService
     frontendURL string
 }
 
-func NewAuthHandler(userService ports.UserService, oauthManager *auth.OAuthManager, frontendURL string) *AuthHandler {
+func NewAuthHandler(userService ports.UserService, tokenService ports.TokenService, frontendURL string) *AuthHandler {
     return &AuthHandler{
         userService: userService,
-        oauthManager: oauthManager,
+        tokenService: tokenService,
         frontendURL: frontendURL,
     }
 }
@@ -30,7 +31,7 @@ func NewAuthHandler(userService ports.UserService, oauthManager *auth.OAuthManag
 func (h *AuthHandler) Signup(w http.ResponseWriter, r *http.Request) {
     var req domain.SignupRequest
     if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-        http.Error(w, err.Error(), http.StatusBadRequest)
+        problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
         return
     }
 
@@ -38,7 +39,7 @@ func (h *AuthHandler) Signup(w http.ResponseWriter, r *http.Request) {
     if err != nil {
         // Log the error for debugging
         log.Printf("Signup error: %v", err)
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        problem.WriteError(w, err)
         return
     }
 
@@ -48,18 +49,65 @@ func (h *AuthHandler) Signup(w http.ResponseWriter, r *http.Request) {
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
     var req domain.LoginRequest
     if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-        http.Error(w, err.Error(), http.StatusBadRequest)
+        problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
         return
     }
 
-    resp, err := h.userService.Login(r.Context(), req)
+    result, err := h.userService.Login(r.Context(), req)
     if err != nil {
-        http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+        problem.WriteError(w, err)
         return
     }
+    writeLoginResult(w, result)
+}
 
+// writeLoginResult writes the tokens of a login, or its MFA challenge, with
+// which the client finishes the login at /auth/mfa/verify.
+func writeLoginResult(w http.ResponseWriter, result *domain.LoginResult) {
     w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(resp)
+    if result.Challenge != nil {
+        json.NewEncoder(w).Encode(result.Challenge)
+        return
+    }
+    json.NewEncoder(w).Encode(result.Auth)
+}
+
+// Refresh exchanges a refresh token for a new token pair.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+    var req domain.RefreshRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+        return
+    }
+
+    tokens, err := h.tokenService.Refresh(r.Context(), req)
+    if err != nil {
+        problem.WriteError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(tokens)
+}
+
+// Logout revokes the refresh token in the body, with its whole family, and
+// the bearer token of the request. It needs no valid access token, so a
+// client can log out after its access token expired.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+    var req domain.LogoutRequest
+    if r.ContentLength != 0 {
+        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+            problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+            return
+        }
+    }
+
+    if err := h.tokenService.Logout(r.Context(), req, middleware.BearerToken(r)); err != nil {
+        problem.WriteError(w, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
 }
 
 func (h *AuthHandler) BeginOAuth(w http.ResponseWriter, r *http.Request) {
@@ -81,24 +129,56 @@ func (h *AuthHandler) OAuthCallback(w http.ResponseWriter, r *http.Request) {
     // Complete the auth process
     user, err := gothic.CompleteUserAuth(w, r)
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        problem.Write(w, http.StatusBadRequest, "oauth_failed", err.Error())
         return
     }
     
     // Process the user data and create/login the user
-    resp, err := h.userService.OAuthLogin(r.Context(), domain.OAuthUser{
-        Provider:    user.Provider,
-        Email:       user.Email,
-        Name:        user.Name,
-        AvatarURL:   user.AvatarURL,
-        ProviderID:  user.UserID,
+    code, err := h.userService.OAuthLogin(r.Context(), domain.OAuthUser{
+        Provider:      user.Provider,
+        Email:         user.Email,
+        Name:          user.Name,
+        AvatarURL:     user.AvatarURL,
+        ProviderID:    user.UserID,
+        EmailVerified: emailVerified(user.RawData),
     })
     
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        problem.WriteError(w, err)
         return
     }
     
-    redirectURL := fmt.Sprintf("%s/callback?token=%s", h.frontendURL, resp.Token)
+    // No token goes in the URL: the frontend exchanges the short-lived
+    // code at POST /auth/oauth/exchange
+    redirectURL := h.frontendURL + "/callback?" + url.Values{"code": {code}}.Encode()
     http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+}
+
+// ExchangeOAuthCode finishes an OAuth login: it uses up the code of the
+// callback and answers like Login.
+func (h *AuthHandler) ExchangeOAuthCode(w http.ResponseWriter, r *http.Request) {
+    var req domain.OAuthExchangeRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+        return
+    }
+
+    result, err := h.userService.ExchangeOAuthCode(r.Context(), req)
+    if err != nil {
+        problem.WriteError(w, err)
+        return
+    }
+    writeLoginResult(w, result)
+}
+
+// emailVerified reports whether a provider's profile says it verified the
+// user's email: Google's userinfo has verified_email, and OpenID Connect
+// providers have email_verified.
+func emailVerified(profile map[string]interface{}) bool {
+    for _, key := range []string{"verified_email", "email_verified"} {
+        if verified, ok := profile[key].(bool); ok {
+            return verified
+        }
+    }
+    return false
 }
\ No newline at end of file
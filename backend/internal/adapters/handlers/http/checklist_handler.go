@@ -0,0 +1,156 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/middleware"
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/problem"
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+// ChecklistHandler serves the checklist of a todo. Every endpoint responds
+// with the parent todo, including its checklist and progress.
+type ChecklistHandler struct {
+	checklistService ports.ChecklistService
+}
+
+func NewChecklistHandler(checklistService ports.ChecklistService) *ChecklistHandler {
+	return &ChecklistHandler{
+		checklistService: checklistService,
+	}
+}
+
+// checklistIDs reads the todo ID and, if present, the item ID from the URL.
+func checklistIDs(r *http.Request) (todoID, itemID int, err error) {
+	todoID, err = strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return 0, 0, err
+	}
+	if param := chi.URLParam(r, "itemID"); param != "" {
+		itemID, err = strconv.Atoi(param)
+	}
+	return todoID, itemID, err
+}
+
+func writeTodo(w http.ResponseWriter, status int, todo *domain.Todo) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(todo)
+}
+
+func (h *ChecklistHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	todoID, _, err := checklistIDs(r)
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid todo ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req domain.CreateChecklistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	todo, err := h.checklistService.AddItem(r.Context(), todoID, req, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	writeTodo(w, http.StatusCreated, todo)
+}
+
+func (h *ChecklistHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	todoID, itemID, err := checklistIDs(r)
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid todo or item ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req domain.UpdateChecklistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	todo, err := h.checklistService.UpdateItem(r.Context(), todoID, itemID, req, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	writeTodo(w, http.StatusOK, todo)
+}
+
+func (h *ChecklistHandler) ToggleItem(w http.ResponseWriter, r *http.Request) {
+	todoID, itemID, err := checklistIDs(r)
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid todo or item ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	todo, err := h.checklistService.ToggleItem(r.Context(), todoID, itemID, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	writeTodo(w, http.StatusOK, todo)
+}
+
+func (h *ChecklistHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	todoID, itemID, err := checklistIDs(r)
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid todo or item ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	todo, err := h.checklistService.RemoveItem(r.Context(), todoID, itemID, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	writeTodo(w, http.StatusOK, todo)
+}
+
+func (h *ChecklistHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	todoID, _, err := checklistIDs(r)
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid todo ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req domain.ReorderChecklistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	todo, err := h.checklistService.Reorder(r.Context(), todoID, req, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	writeTodo(w, http.StatusOK, todo)
+}
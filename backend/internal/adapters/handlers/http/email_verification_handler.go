@@ -0,0 +1,50 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/middleware"
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/problem"
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+)
+
+type EmailVerificationHandler struct {
+	verificationService ports.EmailVerificationService
+}
+
+func NewEmailVerificationHandler(verificationService ports.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{
+		verificationService: verificationService,
+	}
+}
+
+// VerifyEmail verifies the address of the user a verification link was
+// sent to. It needs no access token, so the link works on any device.
+func (h *EmailVerificationHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req domain.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	if err := h.verificationService.VerifyEmail(r.Context(), req); err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResendVerification mails the current user a new verification link.
+func (h *EmailVerificationHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	if err := h.verificationService.SendVerification(r.Context(), userID); err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/middleware"
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/problem"
+)
+
+// todoETag is the strong entity tag of a todo version.
+func todoETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion returns the todo version the If-Match header asks for, or
+// 0 if any version will do: without the header, or for "*", which every
+// existing todo matches. The header may list several tags; the todo's
+// current version is picked if it is among them. Tags that name no version
+// give -1, which matches none, so the change fails with 412.
+//
+// If-Match compares tags strongly (RFC 9110, section 13.1.1), so weak tags
+// never match. A header of only weak tags, or one that is not a list of
+// entity tags, is answered with 400 rather than a 412 no retry can pass.
+// ok is false once an error has been written.
+func (h *TodoHandler) ifMatchVersion(w http.ResponseWriter, r *http.Request, todoID int) (version int, ok bool) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, true
+	}
+
+	tags, err := parseETags(value)
+	if err != nil {
+		writeInvalidIfMatch(w)
+		return 0, false
+	}
+	var versions []int
+	strong := false
+	for _, tag := range tags {
+		if tag.weak {
+			continue
+		}
+		strong = true
+		if version, err := strconv.Atoi(tag.opaque); err == nil && version >= 1 {
+			versions = append(versions, version)
+		}
+	}
+	switch {
+	case !strong:
+		writeInvalidIfMatch(w)
+		return 0, false
+	case len(versions) == 0:
+		return -1, true
+	case len(versions) == 1:
+		return versions[0], true
+	}
+
+	// The service checks a single version, so pick the current one if it
+	// is listed. A change made meanwhile still fails the check.
+	todo, err := h.todoService.GetTodoByID(r.Context(), todoID, middleware.GetUserIDFromContext(r.Context()))
+	if err != nil {
+		problem.WriteError(w, err)
+		return 0, false
+	}
+	for _, version := range versions {
+		if version == todo.Version {
+			return version, true
+		}
+	}
+	return -1, true
+}
+
+func writeInvalidIfMatch(w http.ResponseWriter) {
+	problem.Write(w, http.StatusBadRequest, "invalid_request",
+		`If-Match must be "*" or a list of the strong ETags this API sends, such as "3"`)
+}
+
+type etag struct {
+	weak   bool
+	opaque string
+}
+
+// parseETags parses a comma-separated list of entity tags, each a quoted
+// string with an optional W/ prefix (RFC 9110, section 8.8.3).
+func parseETags(value string) ([]etag, error) {
+	var tags []etag
+	for {
+		value = strings.TrimLeft(value, " \t")
+		if value == "" {
+			break
+		}
+		if value[0] == ',' {
+			// Empty list elements are allowed
+			value = value[1:]
+			continue
+		}
+
+		var tag etag
+		if strings.HasPrefix(value, "W/") {
+			tag.weak = true
+			value = value[2:]
+		}
+		if !strings.HasPrefix(value, `"`) {
+			return nil, errMalformedETag
+		}
+		end := strings.IndexByte(value[1:], '"')
+		if end < 0 {
+			return nil, errMalformedETag
+		}
+		tag.opaque = value[1 : end+1]
+		tags = append(tags, tag)
+
+		value = strings.TrimLeft(value[end+2:], " \t")
+		if value != "" && value[0] != ',' {
+			return nil, errMalformedETag
+		}
+	}
+	if len(tags) == 0 {
+		return nil, errMalformedETag
+	}
+	return tags, nil
+}
+
+var errMalformedETag = errors.New("malformed entity tag")
+
+// writeVersionConflict answers 412 Precondition Failed with the current
+// representation of the todo, so the client can merge and retry.
+func (h *TodoHandler) writeVersionConflict(w http.ResponseWriter, r *http.Request, todoID int) {
+	todo, err := h.todoService.GetTodoByID(r.Context(), todoID, middleware.GetUserIDFromContext(r.Context()))
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", todoETag(todo.Version))
+	w.WriteHeader(http.StatusPreconditionFailed)
+	json.NewEncoder(w).Encode(todo)
+}
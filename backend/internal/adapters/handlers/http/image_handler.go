@@ -2,9 +2,12 @@
 package http
 
 import (
+    "errors"
     "fmt"
     "net/http"
     "github.com/go-chi/chi/v5"
+    "github.com/ChaiyawutTar/MyList/internal/adapters/handlers/problem"
+    "github.com/ChaiyawutTar/MyList/internal/core/domain"
     "github.com/ChaiyawutTar/MyList/internal/core/ports"
 
 	"strings"
@@ -26,7 +29,7 @@ func (h *ImageHandler) ServeImage(w http.ResponseWriter, r *http.Request) {
     // Get image ID from URL
     imageID := chi.URLParam(r, "id")
     if imageID == "" {
-        http.Error(w, "Image ID is required", http.StatusBadRequest)
+        problem.Write(w, http.StatusBadRequest, "invalid_id", "image ID is required")
         return
     }
 
@@ -47,20 +50,17 @@ func (h *ImageHandler) ServeImage(w http.ResponseWriter, r *http.Request) {
     // Get image data from repository with error handling
     imageData, contentType, err := h.imageRepository.Get(r.Context(), imageID)
     if err != nil {
-        if strings.Contains(err.Error(), "not found") {
-            fmt.Printf("Image not found: %s, error: %v\n", imageID, err)
-            http.Error(w, "Image not found", http.StatusNotFound)
-        } else {
+        if !errors.Is(err, domain.ErrNotFound) {
             fmt.Printf("Error retrieving image %s: %v\n", imageID, err)
-            http.Error(w, "Failed to retrieve image", http.StatusInternalServerError)
         }
+        problem.WriteError(w, err)
         return
     }
 
     // Check if we actually got data
     if len(imageData) == 0 {
         fmt.Printf("Image data is empty for ID: %s\n", imageID)
-        http.Error(w, "Image data is empty", http.StatusNotFound)
+        problem.WriteError(w, domain.ErrImageNotFound)
         return
     }
 
@@ -0,0 +1,191 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/middleware"
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/problem"
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+type ListHandler struct {
+	listService ports.ListService
+	todoService ports.TodoService
+}
+
+func NewListHandler(listService ports.ListService, todoService ports.TodoService) *ListHandler {
+	return &ListHandler{
+		listService: listService,
+		todoService: todoService,
+	}
+}
+
+func (h *ListHandler) GetAllLists(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	lists, err := h.listService.GetAllLists(r.Context(), userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lists)
+}
+
+func (h *ListHandler) GetListByID(w http.ResponseWriter, r *http.Request) {
+	// Get list ID from URL
+	listID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid list ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	list, err := h.listService.GetListByID(r.Context(), listID, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+func (h *ListHandler) CreateList(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req domain.CreateListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	list, err := h.listService.CreateList(r.Context(), req, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(list)
+}
+
+func (h *ListHandler) UpdateList(w http.ResponseWriter, r *http.Request) {
+	// Get list ID from URL
+	listID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid list ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req domain.UpdateListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	list, err := h.listService.UpdateList(r.Context(), listID, req, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// DeleteList takes ?mode=reassign (default) to move the list's todos to the
+// inbox, or ?mode=cascade to delete them too.
+func (h *ListHandler) DeleteList(w http.ResponseWriter, r *http.Request) {
+	// Get list ID from URL
+	listID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid list ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	mode := r.URL.Query().Get("mode")
+	if err := h.listService.DeleteList(r.Context(), listID, userID, mode); err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ListHandler) GetListTodos(w http.ResponseWriter, r *http.Request) {
+	// Get list ID from URL
+	listID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid list ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	if _, err := h.listService.GetListByID(r.Context(), listID, userID); err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	query, err := parseTodoQuery(r)
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+	query.ListID = listID
+
+	page, err := h.todoService.GetAllTodos(r.Context(), userID, query)
+	if err != nil {
+		fmt.Printf("Error fetching todos: %v\n", err)
+		problem.WriteError(w, err)
+		return
+	}
+
+	setPageHeaders(w, r, page)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page.Todos)
+}
+
+func (h *ListHandler) MoveTodos(w http.ResponseWriter, r *http.Request) {
+	// Get list ID from URL
+	listID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid list ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req domain.MoveTodosRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	if err := h.listService.MoveTodos(r.Context(), listID, req, userID); err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/middleware"
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/problem"
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+)
+
+type MFAHandler struct {
+	mfaService ports.MFAService
+}
+
+func NewMFAHandler(mfaService ports.MFAService) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+	}
+}
+
+// GetStatus tells the current user whether MFA is on.
+func (h *MFAHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	status, err := h.mfaService.GetStatus(r.Context(), userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// EnrollTOTP hands out a new TOTP secret, and the otpauth:// URI to show
+// as a QR code. MFA stays off until ConfirmTOTP.
+func (h *MFAHandler) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	enrollment, err := h.mfaService.EnrollTOTP(r.Context(), userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(enrollment)
+}
+
+// ConfirmTOTP turns MFA on and returns the recovery codes, which are shown
+// only this once.
+func (h *MFAHandler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req domain.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	codes, err := h.mfaService.ConfirmTOTP(r.Context(), userID, req)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(codes)
+}
+
+func (h *MFAHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req domain.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	if err := h.mfaService.Disable(r.Context(), userID, req); err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes replaces the current user's recovery codes; the
+// old ones stop working.
+func (h *MFAHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req domain.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(r.Context(), userID, req)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(codes)
+}
+
+// VerifyLogin completes a login that answered with an MFA challenge. It
+// needs no access token; the challenge's mfa_token stands in for one.
+func (h *MFAHandler) VerifyLogin(w http.ResponseWriter, r *http.Request) {
+	var req domain.MFAVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	resp, err := h.mfaService.VerifyLogin(r.Context(), req)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/problem"
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+)
+
+type PasswordHandler struct {
+	passwordService ports.PasswordService
+}
+
+func NewPasswordHandler(passwordService ports.PasswordService) *PasswordHandler {
+	return &PasswordHandler{
+		passwordService: passwordService,
+	}
+}
+
+// ForgotPassword mails a reset link. It accepts any well-formed email, so
+// the response does not tell whether an account has it.
+func (h *PasswordHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req domain.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	if err := h.passwordService.ForgotPassword(r.Context(), req); err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ResetPassword sets a new password with the token of a reset link.
+func (h *PasswordHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req domain.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	if err := h.passwordService.ResetPassword(r.Context(), req); err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
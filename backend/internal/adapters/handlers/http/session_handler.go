@@ -0,0 +1,63 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/middleware"
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/problem"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+type SessionHandler struct {
+	sessionService ports.SessionService
+}
+
+func NewSessionHandler(sessionService ports.SessionService) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// GetSessions lists where the user is logged in.
+func (h *SessionHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	sessionID := middleware.GetSessionIDFromContext(r.Context())
+
+	sessions, err := h.sessionService.GetSessions(r.Context(), userID, sessionID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+}
+
+// RevokeSession logs one session out, which may be the current one.
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	if err := h.sessionService.RevokeSession(r.Context(), chi.URLParam(r, "id"), userID); err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeOtherSessions logs the user out everywhere else.
+func (h *SessionHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserIDFromContext(r.Context())
+	sessionID := middleware.GetSessionIDFromContext(r.Context())
+
+	revoked, err := h.sessionService.RevokeOtherSessions(r.Context(), userID, sessionID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"revoked": revoked})
+}
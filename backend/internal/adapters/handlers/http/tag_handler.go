@@ -0,0 +1,131 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/middleware"
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/problem"
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+type TagHandler struct {
+	tagService ports.TagService
+}
+
+func NewTagHandler(tagService ports.TagService) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+	}
+}
+
+func (h *TagHandler) GetAllTags(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	tags, err := h.tagService.GetAllTags(r.Context(), userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+func (h *TagHandler) CreateTag(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req domain.CreateTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	tag, err := h.tagService.CreateTag(r.Context(), req, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tag)
+}
+
+func (h *TagHandler) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	// Get tag ID from URL
+	tagID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid tag ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req domain.UpdateTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	tag, err := h.tagService.UpdateTag(r.Context(), tagID, req, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tag)
+}
+
+func (h *TagHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	// Get tag ID from URL
+	tagID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid tag ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	if err := h.tagService.DeleteTag(r.Context(), tagID, userID); err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TagHandler) MergeTags(w http.ResponseWriter, r *http.Request) {
+	// Get target tag ID from URL
+	tagID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid tag ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req domain.MergeTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	tag, err := h.tagService.MergeTags(r.Context(), tagID, req, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tag)
+}
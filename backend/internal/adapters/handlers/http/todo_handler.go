@@ -2,13 +2,18 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
 	"mime/multipart"
 	"github.com/go-chi/chi/v5"
 	"github.com/ChaiyawutTar/MyList/internal/core/domain"
 	"github.com/ChaiyawutTar/MyList/internal/core/ports"
 	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/middleware"
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/problem"
 	"fmt"
 	"time"
 )
@@ -31,12 +36,20 @@ func (h *TodoHandler) GetAllTodos(w http.ResponseWriter, r *http.Request) {
     // Check if we should include image data
     includeImages := r.URL.Query().Get("include_images") == "true"
 
-    todos, err := h.todoService.GetAllTodos(r.Context(), userID)
+    query, err := parseTodoQuery(r)
+    if err != nil {
+        problem.Write(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+        return
+    }
+
+    page, err := h.todoService.GetAllTodos(r.Context(), userID, query)
     if err != nil {
         fmt.Printf("Error fetching todos: %v\n", err)
-        http.Error(w, fmt.Sprintf("Failed to fetch todos: %v", err), http.StatusInternalServerError)
+        problem.WriteError(w, err)
         return
     }
+    todos := page.Todos
+    setPageHeaders(w, r, page)
 
     // If we're not including images, remove the image data to reduce payload size
     if !includeImages {
@@ -44,12 +57,27 @@ func (h *TodoHandler) GetAllTodos(w http.ResponseWriter, r *http.Request) {
         type LightTodo struct {
             ID          int       `json:"id"`
             UserID      int       `json:"user_id"`
+            ListID      int       `json:"list_id"`
             Title       string    `json:"title"`
             Description string    `json:"description"`
             Status      string    `json:"status"`
-            ImageID     string    `json:"image_id,omitempty"`
-            CreatedAt   time.Time `json:"created_at"`
-            UpdatedAt   time.Time `json:"updated_at"`
+            ImageID     string     `json:"image_id,omitempty"`
+            StartAt     *time.Time `json:"start_at,omitempty"`
+            DueAt       *time.Time `json:"due_at,omitempty"`
+            Overdue     bool       `json:"overdue"`
+            RecurrenceRule  string `json:"recurrence_rule,omitempty"`
+            SeriesID        int    `json:"series_id,omitempty"`
+            RecurrenceIndex int    `json:"recurrence_index,omitempty"`
+            Tags        []domain.Tag `json:"tags"`
+            Progress    domain.ChecklistProgress `json:"progress"`
+            AutoComplete bool    `json:"auto_complete"`
+            CompletedAt *time.Time `json:"completed_at,omitempty"`
+            Priority    string     `json:"priority"`
+            Position    string     `json:"position"`
+            ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+            Version     int        `json:"version"`
+            CreatedAt   time.Time  `json:"created_at"`
+            UpdatedAt   time.Time  `json:"updated_at"`
         }
 
         lightTodos := make([]LightTodo, len(todos))
@@ -57,10 +85,25 @@ func (h *TodoHandler) GetAllTodos(w http.ResponseWriter, r *http.Request) {
             lightTodos[i] = LightTodo{
                 ID:          todo.ID,
                 UserID:      todo.UserID,
+                ListID:      todo.ListID,
                 Title:       todo.Title,
                 Description: todo.Description,
                 Status:      todo.Status,
                 ImageID:     todo.ImageID,
+                StartAt:     todo.StartAt,
+                DueAt:       todo.DueAt,
+                Overdue:     todo.Overdue,
+                RecurrenceRule:  todo.RecurrenceRule,
+                SeriesID:        todo.SeriesID,
+                RecurrenceIndex: todo.RecurrenceIndex,
+                Tags:        todo.Tags,
+                Progress:    todo.Progress,
+                AutoComplete: todo.AutoComplete,
+                CompletedAt: todo.CompletedAt,
+                Priority:    todo.Priority,
+                Position:    todo.Position,
+                ArchivedAt:  todo.ArchivedAt,
+                Version:     todo.Version,
                 CreatedAt:   todo.CreatedAt,
                 UpdatedAt:   todo.UpdatedAt,
             }
@@ -80,6 +123,43 @@ func (h *TodoHandler) GetAllTodos(w http.ResponseWriter, r *http.Request) {
         http.Error(w, "Error encoding response", http.StatusInternalServerError)
     }
 }
+
+// SearchTodos runs a full-text search, best matches first.
+func (h *TodoHandler) SearchTodos(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	params := r.URL.Query()
+	query := domain.SearchQuery{Text: params.Get("q")}
+	var err error
+	if v := params.Get("list_id"); v != "" {
+		if query.ListID, err = strconv.Atoi(v); err != nil {
+			problem.Write(w, http.StatusBadRequest, "invalid_parameter", fmt.Sprintf("invalid list_id %q", v))
+			return
+		}
+	}
+	if v := params.Get("limit"); v != "" {
+		if query.Limit, err = strconv.Atoi(v); err != nil || query.Limit < 1 || query.Limit > domain.MaxSearchLimit {
+			problem.Write(w, http.StatusBadRequest, "invalid_parameter", fmt.Sprintf("invalid limit %q", v))
+			return
+		}
+	}
+	if strings.TrimSpace(query.Text) == "" {
+		problem.Write(w, http.StatusBadRequest, "invalid_parameter", "q is required")
+		return
+	}
+
+	results, err := h.todoService.SearchTodos(r.Context(), userID, query)
+	if err != nil {
+		fmt.Printf("Error searching todos: %v\n", err)
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
 func (h *TodoHandler) CreateTodo(w http.ResponseWriter, r *http.Request) {
     // Get user ID from context
     userID := middleware.GetUserIDFromContext(r.Context())
@@ -92,15 +172,38 @@ func (h *TodoHandler) CreateTodo(w http.ResponseWriter, r *http.Request) {
     if err != nil {
         // If not multipart form, try JSON
         if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-            http.Error(w, "Invalid request format", http.StatusBadRequest)
+            problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
             return
         }
     } else {
         // Get form values
         req = domain.CreateTodoRequest{
-            Title:       r.FormValue("title"),
-            Description: r.FormValue("description"),
-            Status:      r.FormValue("status"),
+            Title:          r.FormValue("title"),
+            Description:    r.FormValue("description"),
+            Status:         r.FormValue("status"),
+            RecurrenceRule: r.FormValue("recurrence_rule"),
+            Priority:       r.FormValue("priority"),
+            Tags:           r.MultipartForm.Value["tags"],
+        }
+        if v := r.FormValue("list_id"); v != "" {
+            if req.ListID, err = strconv.Atoi(v); err != nil {
+                problem.Write(w, http.StatusBadRequest, "invalid_request", "invalid list_id")
+                return
+            }
+        }
+        if v := r.FormValue("auto_complete"); v != "" {
+            if req.AutoComplete, err = strconv.ParseBool(v); err != nil {
+                problem.Write(w, http.StatusBadRequest, "invalid_request", "invalid auto_complete")
+                return
+            }
+        }
+        if req.StartAt, err = parseTimeParam(r.FormValue("start_at")); err != nil {
+            problem.Write(w, http.StatusBadRequest, "invalid_request", "invalid start_at, expected RFC 3339")
+            return
+        }
+        if req.DueAt, err = parseTimeParam(r.FormValue("due_at")); err != nil {
+            problem.Write(w, http.StatusBadRequest, "invalid_request", "invalid due_at, expected RFC 3339")
+            return
         }
         
         // Get image file if present
@@ -110,17 +213,11 @@ func (h *TodoHandler) CreateTodo(w http.ResponseWriter, r *http.Request) {
         }
     }
     
-    // Validate required fields
-    if req.Title == "" || req.Status == "" {
-        http.Error(w, "Title and status are required", http.StatusBadRequest)
-        return
-    }
-
     // Create todo with image if provided
     todo, err := h.todoService.CreateTodo(r.Context(), req, userID, imageFile)
     if err != nil {
         fmt.Printf("Error creating todo: %v\n", err)
-        http.Error(w, fmt.Sprintf("Failed to create todo: %v", err), http.StatusInternalServerError)
+        problem.WriteError(w, err)
         return
     }
 
@@ -133,7 +230,7 @@ func (h *TodoHandler) UpdateTodo(w http.ResponseWriter, r *http.Request) {
     // Get todo ID from URL
     todoID, err := strconv.Atoi(chi.URLParam(r, "id"))
     if err != nil {
-        http.Error(w, "Invalid todo ID", http.StatusBadRequest)
+        problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid todo ID")
         return
     }
 
@@ -148,7 +245,7 @@ func (h *TodoHandler) UpdateTodo(w http.ResponseWriter, r *http.Request) {
     if err != nil {
         // If not multipart form, try JSON
         if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-            http.Error(w, "Invalid request format", http.StatusBadRequest)
+            problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
             return
         }
     } else {
@@ -157,6 +254,41 @@ func (h *TodoHandler) UpdateTodo(w http.ResponseWriter, r *http.Request) {
             Title:       r.FormValue("title"),
             Description: r.FormValue("description"),
             Status:      r.FormValue("status"),
+            Scope:       r.FormValue("scope"),
+        }
+        // Only touch the rule and tags when the form carries the field
+        if values, ok := r.MultipartForm.Value["recurrence_rule"]; ok && len(values) > 0 {
+            req.RecurrenceRule = &values[0]
+        }
+        if values, ok := r.MultipartForm.Value["tags"]; ok {
+            req.Tags = values
+        }
+        if values, ok := r.MultipartForm.Value["priority"]; ok && len(values) > 0 {
+            req.Priority = &values[0]
+        }
+        if v := r.FormValue("list_id"); v != "" {
+            listID, err := strconv.Atoi(v)
+            if err != nil {
+                problem.Write(w, http.StatusBadRequest, "invalid_request", "invalid list_id")
+                return
+            }
+            req.ListID = &listID
+        }
+        if v := r.FormValue("auto_complete"); v != "" {
+            autoComplete, err := strconv.ParseBool(v)
+            if err != nil {
+                problem.Write(w, http.StatusBadRequest, "invalid_request", "invalid auto_complete")
+                return
+            }
+            req.AutoComplete = &autoComplete
+        }
+        if req.StartAt, err = parseTimeParam(r.FormValue("start_at")); err != nil {
+            problem.Write(w, http.StatusBadRequest, "invalid_request", "invalid start_at, expected RFC 3339")
+            return
+        }
+        if req.DueAt, err = parseTimeParam(r.FormValue("due_at")); err != nil {
+            problem.Write(w, http.StatusBadRequest, "invalid_request", "invalid due_at, expected RFC 3339")
+            return
         }
         
         // Get image file if present
@@ -166,49 +298,411 @@ func (h *TodoHandler) UpdateTodo(w http.ResponseWriter, r *http.Request) {
         }
     }
     
-    // Validate required fields
-    if req.Title == "" || req.Status == "" {
-        http.Error(w, "Title and status are required", http.StatusBadRequest)
+    // If-Match takes precedence over a version in the body
+    version, ok := h.ifMatchVersion(w, r, todoID)
+    if !ok {
         return
     }
+    if version != 0 {
+        req.Version = version
+    }
 
     // Update todo with image if provided
     todo, err := h.todoService.UpdateTodo(r.Context(), todoID, req, userID, imageFile)
+    if errors.Is(err, domain.ErrVersionConflict) {
+        h.writeVersionConflict(w, r, todoID)
+        return
+    }
     if err != nil {
         fmt.Printf("Error updating todo: %v\n", err)
-        http.Error(w, fmt.Sprintf("Failed to update todo: %v", err), http.StatusInternalServerError)
+        problem.WriteError(w, err)
         return
     }
 
     w.Header().Set("Content-Type", "application/json")
+    w.Header().Set("ETag", todoETag(todo.Version))
     json.NewEncoder(w).Encode(todo)
 }
+// BatchTodos applies several operations to many todos in one transaction
+// and answers with the result of every item.
+func (h *TodoHandler) BatchTodos(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req domain.BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	result, err := h.todoService.BatchTodos(r.Context(), req, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	// Failed items carry the problem details a single request would get
+	resp := batchResponse{Mode: result.Mode, Committed: result.Committed, Results: make([]batchItemResponse, len(result.Results))}
+	for i, item := range result.Results {
+		resp.Results[i].BatchItemResult = item
+		if item.Err != nil {
+			details := problem.Describe(item.Err)
+			resp.Results[i].Error = &details
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+type batchResponse struct {
+	Mode      string              `json:"mode"`
+	Committed bool                `json:"committed"`
+	Results   []batchItemResponse `json:"results"`
+}
+
+type batchItemResponse struct {
+	domain.BatchItemResult
+	Error *problem.Details `json:"error,omitempty"`
+}
+
+// maxPatchSize bounds the body of a PATCH request.
+const maxPatchSize = 1 << 20
+
+// PatchTodo changes only the fields named in the body, which is a JSON Merge
+// Patch (application/merge-patch+json or application/json) or a JSON Patch
+// (application/json-patch+json).
+func (h *TodoHandler) PatchTodo(w http.ResponseWriter, r *http.Request) {
+	// Get todo ID from URL
+	todoID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid todo ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req domain.PatchTodoRequest
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/merge-patch+json", "application/json":
+		req.Format = domain.PatchMerge
+	case "application/json-patch+json":
+		req.Format = domain.PatchJSON
+	default:
+		problem.Write(w, http.StatusUnsupportedMediaType, "unsupported_media_type", "unsupported patch format")
+		return
+	}
+
+	req.Patch, err = io.ReadAll(http.MaxBytesReader(w, r.Body, maxPatchSize))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+	var ok bool
+	if req.Version, ok = h.ifMatchVersion(w, r, todoID); !ok {
+		return
+	}
+
+	todo, err := h.todoService.PatchTodo(r.Context(), todoID, req, userID)
+	if errors.Is(err, domain.ErrVersionConflict) {
+		h.writeVersionConflict(w, r, todoID)
+		return
+	}
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", todoETag(todo.Version))
+	json.NewEncoder(w).Encode(todo)
+}
+
 func (h *TodoHandler) DeleteTodo(w http.ResponseWriter, r *http.Request) {
 	// Get todo ID from URL
 	todoID, err := strconv.Atoi(chi.URLParam(r, "id"))
 	if err != nil {
-		http.Error(w, "Invalid todo ID", http.StatusBadRequest)
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid todo ID")
 		return
 	}
 
 	// Get user ID from context
 	userID := middleware.GetUserIDFromContext(r.Context())
 
+	version, ok := h.ifMatchVersion(w, r, todoID)
+	if !ok {
+		return
+	}
+
 	// Delete todo
-	if err := h.todoService.DeleteTodo(r.Context(), todoID, userID); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	err = h.todoService.DeleteTodo(r.Context(), todoID, userID, version)
+	if errors.Is(err, domain.ErrVersionConflict) {
+		h.writeVersionConflict(w, r, todoID)
+		return
+	}
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetTrash lists the user's trashed todos and takes the same query
+// parameters as GetAllTodos.
+func (h *TodoHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	query, err := parseTodoQuery(r)
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+
+	page, err := h.todoService.GetTrash(r.Context(), userID, query)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	setPageHeaders(w, r, page)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page.Todos)
+}
+
+func (h *TodoHandler) RestoreTodo(w http.ResponseWriter, r *http.Request) {
+	// Get todo ID from URL
+	todoID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid todo ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	todo, err := h.todoService.RestoreTodo(r.Context(), todoID, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(todo)
+}
+
+// PurgeTodo permanently deletes a todo that is in the trash.
+func (h *TodoHandler) PurgeTodo(w http.ResponseWriter, r *http.Request) {
+	// Get todo ID from URL
+	todoID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid todo ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	if err := h.todoService.PurgeTodo(r.Context(), todoID, userID); err != nil {
+		problem.WriteError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *TodoHandler) EmptyTrash(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	purged, err := h.todoService.EmptyTrash(r.Context(), userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"purged": purged})
+}
+
+// MoveTodo places a todo between the neighbours given as after_id and
+// before_id.
+func (h *TodoHandler) MoveTodo(w http.ResponseWriter, r *http.Request) {
+	// Get todo ID from URL
+	todoID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid todo ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req domain.MoveTodoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	todo, err := h.todoService.MoveTodo(r.Context(), todoID, req, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(todo)
+}
+
+// GetArchivedTodos lists the user's archived todos and takes the same
+// query parameters as GetAllTodos.
+func (h *TodoHandler) GetArchivedTodos(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	query, err := parseTodoQuery(r)
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+
+	page, err := h.todoService.GetArchivedTodos(r.Context(), userID, query)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	setPageHeaders(w, r, page)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page.Todos)
+}
+
+func (h *TodoHandler) ArchiveTodo(w http.ResponseWriter, r *http.Request) {
+	// Get todo ID from URL
+	todoID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid todo ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	todo, err := h.todoService.ArchiveTodo(r.Context(), todoID, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(todo)
+}
+
+func (h *TodoHandler) UnarchiveTodo(w http.ResponseWriter, r *http.Request) {
+	// Get todo ID from URL
+	todoID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid todo ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	todo, err := h.todoService.UnarchiveTodo(r.Context(), todoID, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(todo)
+}
+
+// ArchiveCompleted archives all completed todos of the user, or only those
+// of the list given as the list_id query parameter.
+func (h *TodoHandler) ArchiveCompleted(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	listID := 0
+	if v := r.URL.Query().Get("list_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid list ID")
+			return
+		}
+		listID = id
+	}
+
+	archived, err := h.todoService.ArchiveCompleted(r.Context(), userID, listID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"archived": archived})
+}
+
+// GetTodoHistory lists the revisions of a todo, newest first.
+func (h *TodoHandler) GetTodoHistory(w http.ResponseWriter, r *http.Request) {
+	// Get todo ID from URL
+	todoID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid todo ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	revisions, err := h.todoService.GetTodoHistory(r.Context(), todoID, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revisions)
+}
+
+// RevertTodo restores a todo to the state it had right after a revision.
+func (h *TodoHandler) RevertTodo(w http.ResponseWriter, r *http.Request) {
+	// Get todo ID and revision from URL
+	todoID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid todo ID")
+		return
+	}
+	rev, err := strconv.Atoi(chi.URLParam(r, "rev"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_parameter", "invalid revision")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	todo, err := h.todoService.RevertTodo(r.Context(), todoID, rev, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(todo)
+}
+
 // internal/adapters/handlers/http/todo_handler.go
 func (h *TodoHandler) GetTodoByID(w http.ResponseWriter, r *http.Request) {
     // Get todo ID from URL
     todoID, err := strconv.Atoi(chi.URLParam(r, "id"))
     if err != nil {
-        http.Error(w, "Invalid todo ID", http.StatusBadRequest)
+        problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid todo ID")
         return
     }
 
@@ -218,10 +712,112 @@ func (h *TodoHandler) GetTodoByID(w http.ResponseWriter, r *http.Request) {
     // Get todo
     todo, err := h.todoService.GetTodoByID(r.Context(), todoID, userID)
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        problem.WriteError(w, err)
         return
     }
 
     w.Header().Set("Content-Type", "application/json")
+    w.Header().Set("ETag", todoETag(todo.Version))
     json.NewEncoder(w).Encode(todo)
-}
\ No newline at end of file
+}
+
+// parseTimeParam parses an optional RFC 3339 timestamp. An empty value
+// yields nil.
+func parseTimeParam(value string) (*time.Time, error) {
+    if value == "" {
+        return nil, nil
+    }
+    t, err := time.Parse(time.RFC3339, value)
+    if err != nil {
+        return nil, err
+    }
+    return &t, nil
+}
+
+// parseTodoQuery reads the todo list filters, sort order and page from the
+// query string.
+func parseTodoQuery(r *http.Request) (domain.TodoQuery, error) {
+    params := r.URL.Query()
+
+    var query domain.TodoQuery
+    var err error
+    if v := params.Get("list_id"); v != "" {
+        if query.ListID, err = strconv.Atoi(v); err != nil {
+            return query, fmt.Errorf("invalid list_id %q", v)
+        }
+    }
+    if v := params.Get("overdue"); v != "" {
+        if query.Overdue, err = strconv.ParseBool(v); err != nil {
+            return query, fmt.Errorf("invalid overdue value %q", v)
+        }
+    }
+    if v := params.Get("include_archived"); v != "" {
+        if query.IncludeArchived, err = strconv.ParseBool(v); err != nil {
+            return query, fmt.Errorf("invalid include_archived value %q", v)
+        }
+    }
+
+    // status may be repeated or comma separated
+    for _, v := range params["status"] {
+        for _, status := range strings.Split(v, ",") {
+            if status = strings.TrimSpace(status); status != "" {
+                query.Statuses = append(query.Statuses, status)
+            }
+        }
+    }
+    query.Text = strings.TrimSpace(params.Get("q"))
+
+    ranges := []struct {
+        name string
+        dest **time.Time
+    }{
+        {"created_after", &query.CreatedAfter},
+        {"created_before", &query.CreatedBefore},
+        {"updated_after", &query.UpdatedAfter},
+        {"updated_before", &query.UpdatedBefore},
+        {"due_after", &query.DueAfter},
+        {"due_before", &query.DueBefore},
+    }
+    for _, p := range ranges {
+        if *p.dest, err = parseTimeParam(params.Get(p.name)); err != nil {
+            return query, fmt.Errorf("invalid %s, expected RFC 3339", p.name)
+        }
+    }
+
+    query.Tags = params["tag"]
+    query.TagMatch = params.Get("tag_match")
+    query.Sort = params.Get("sort")
+    query.Order = strings.ToLower(params.Get("order"))
+    if v := params.Get("limit"); v != "" {
+        if query.Limit, err = strconv.Atoi(v); err != nil || query.Limit < 1 {
+            return query, fmt.Errorf("invalid limit %q", v)
+        }
+    }
+    query.Cursor = params.Get("cursor")
+
+    if err := query.Normalize(); err != nil {
+        return query, err
+    }
+    if query.Cursor != "" {
+        if _, err := domain.DecodeTodoCursor(query.Cursor, query); err != nil {
+            return query, err
+        }
+    }
+    return query, nil
+}
+
+// setPageHeaders points the client at the next page, if there is one. The
+// body stays a plain array so existing clients keep working.
+func setPageHeaders(w http.ResponseWriter, r *http.Request, page *domain.TodoPage) {
+    if page.NextCursor == "" {
+        return
+    }
+
+    next := *r.URL
+    params := next.Query()
+    params.Set("cursor", page.NextCursor)
+    next.RawQuery = params.Encode()
+
+    w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", next.RequestURI()))
+    w.Header().Set("X-Next-Cursor", page.NextCursor)
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/middleware"
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/problem"
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+	"github.com/go-chi/chi/v5"
+)
+
+type WorkflowHandler struct {
+	workflowService ports.WorkflowService
+}
+
+func NewWorkflowHandler(workflowService ports.WorkflowService) *WorkflowHandler {
+	return &WorkflowHandler{
+		workflowService: workflowService,
+	}
+}
+
+func (h *WorkflowHandler) GetAllWorkflows(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	workflows, err := h.workflowService.GetWorkflows(r.Context(), userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(workflows)
+}
+
+func (h *WorkflowHandler) GetWorkflowByID(w http.ResponseWriter, r *http.Request) {
+	// Get workflow ID from URL
+	workflowID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid workflow ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	workflow, err := h.workflowService.GetWorkflow(r.Context(), workflowID, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(workflow)
+}
+
+// GetListWorkflow returns the workflow a list's todos follow, which may be
+// inherited from the user's default.
+func (h *WorkflowHandler) GetListWorkflow(w http.ResponseWriter, r *http.Request) {
+	// Get list ID from URL
+	listID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid list ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	workflow, err := h.workflowService.GetListWorkflow(r.Context(), listID, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(workflow)
+}
+
+func (h *WorkflowHandler) CreateWorkflow(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req domain.WorkflowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	workflow, err := h.workflowService.CreateWorkflow(r.Context(), req, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(workflow)
+}
+
+func (h *WorkflowHandler) UpdateWorkflow(w http.ResponseWriter, r *http.Request) {
+	// Get workflow ID from URL
+	workflowID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid workflow ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	var req domain.WorkflowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_request", "request body is not valid JSON")
+		return
+	}
+
+	workflow, err := h.workflowService.UpdateWorkflow(r.Context(), workflowID, req, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(workflow)
+}
+
+func (h *WorkflowHandler) DeleteWorkflow(w http.ResponseWriter, r *http.Request) {
+	// Get workflow ID from URL
+	workflowID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "invalid_id", "invalid workflow ID")
+		return
+	}
+
+	// Get user ID from context
+	userID := middleware.GetUserIDFromContext(r.Context())
+
+	err = h.workflowService.DeleteWorkflow(r.Context(), workflowID, userID)
+	if err != nil {
+		problem.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -3,61 +3,74 @@ package middleware
 import (
 	"net/http"
 	"context"
-	"strconv"
+	"time"
 
-	"github.com/ChaiyawutTar/MyList/pkg/auth"
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/problem"
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
 )
 
 type contextKey string
 
-const userIDKey contextKey = "userID"
+const (
+	userIDKey    contextKey = "userID"
+	sessionIDKey contextKey = "sessionID"
+)
 
-func AuthMiddleware(jwtAuth *auth.JWTAuth) func(http.Handler) http.Handler {
+// AuthMiddleware admits requests with a valid access token that has not
+// been revoked, and records them as activity of the token's session.
+func AuthMiddleware(tokenService ports.TokenService, activity ports.SessionActivity) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get token from Authorization header
-			token := r.Header.Get("Authorization")
+			token := BearerToken(r)
 			if token == "" {
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				problem.WriteError(w, domain.ErrInvalidToken)
 				return
 			}
 
-			// Remove "Bearer " prefix if present
-			if len(token) > 7 && token[:7] == "Bearer " {
-				token = token[7:]
-			}
-
-			// Validate token
-			claims, err := jwtAuth.ValidateToken(token)
+			// Validate token and check the revocation list
+			claims, err := tokenService.Authenticate(r.Context(), token)
 			if err != nil {
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				problem.WriteError(w, err)
 				return
 			}
 
-			// Get user ID from claims
-			userIDStr, ok := claims["user_id"].(string)
-			if !ok {
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
-				return
-			}
+			activity.Touch(claims.SessionID, time.Now())
 
-			userID, err := strconv.Atoi(userIDStr)
-			if err != nil {
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
-				return
-			}
-
-			// Add user ID to context
-			ctx := context.WithValue(r.Context(), userIDKey, userID)
+			// Add user and session ID to context; changes made in this
+			// request are attributed to the user
+			ctx := context.WithValue(r.Context(), userIDKey, claims.UserID)
+			ctx = context.WithValue(ctx, sessionIDKey, claims.SessionID)
+			ctx = domain.WithActor(ctx, claims.UserID)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// BearerToken returns the token of the request's Authorization header, or
+// "" if it has none.
+func BearerToken(r *http.Request) string {
+	token := r.Header.Get("Authorization")
+
+	// Remove "Bearer " prefix if present
+	if len(token) > 7 && token[:7] == "Bearer " {
+		token = token[7:]
+	}
+	return token
+}
+
 func GetUserIDFromContext(ctx context.Context) int {
 	userID, ok := ctx.Value(userIDKey).(int)
 	if !ok {
 		return 0
 	}
 	return userID
+}
+
+// GetSessionIDFromContext returns the session of the request's access
+// token, or "" for tokens issued before sessions were tracked.
+func GetSessionIDFromContext(ctx context.Context) string {
+	sessionID, _ := ctx.Value(sessionIDKey).(string)
+	return sessionID
 }
\ No newline at end of file
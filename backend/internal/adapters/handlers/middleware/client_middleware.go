@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+// ClientMiddleware records the user agent and IP address of each request
+// in its context, for the sessions logins start. The IP is taken from
+// RemoteAddr; put chi's RealIP in front when running behind a proxy.
+func ClientMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent := r.UserAgent()
+		if len(userAgent) > domain.MaxUserAgentLength {
+			userAgent = strings.ToValidUTF8(userAgent[:domain.MaxUserAgentLength], "")
+		}
+
+		ip := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+
+		ctx := domain.WithClient(r.Context(), domain.Client{UserAgent: userAgent, IP: ip})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
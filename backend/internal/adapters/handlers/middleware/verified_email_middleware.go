@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/ChaiyawutTar/MyList/internal/adapters/handlers/problem"
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+)
+
+// VerifiedEmailMiddleware lets users who have not verified their email
+// read their data but not change it. It must run after AuthMiddleware.
+func VerifiedEmailMiddleware(userService ports.UserService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, err := userService.GetUserByID(r.Context(), GetUserIDFromContext(r.Context()))
+			if err != nil {
+				problem.WriteError(w, err)
+				return
+			}
+			if user.EmailVerifiedAt == nil {
+				problem.WriteError(w, domain.ErrEmailNotVerified)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Package problem renders errors as RFC 7807 application/problem+json
+// responses.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+// ContentType is the media type of a problem response.
+const ContentType = "application/problem+json"
+
+// Details is the body of a problem response. Code is a stable identifier
+// clients can switch on; Errors lists the invalid fields of a request that
+// failed validation.
+type Details struct {
+	Type   string                    `json:"type"`
+	Title  string                    `json:"title"`
+	Status int                       `json:"status"`
+	Detail string                    `json:"detail,omitempty"`
+	Code   string                    `json:"code"`
+	Errors []*domain.ValidationError `json:"errors,omitempty"`
+}
+
+// Write sends a problem response with the given status and code.
+func Write(w http.ResponseWriter, status int, code, detail string) {
+	write(w, Details{Status: status, Code: code, Detail: detail})
+}
+
+// WriteError sends the problem response matching the kind of err. Errors
+// that are not domain errors are logged and reported as a generic 500, so
+// internal details never reach the client.
+func WriteError(w http.ResponseWriter, err error) {
+	var throttled *domain.ThrottledError
+	if errors.As(err, &throttled) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	}
+	write(w, Describe(err))
+}
+
+// Describe returns the problem details WriteError would send for err, for
+// errors reported inside a response rather than as one. Like WriteError it
+// logs errors that are not domain errors and hides their text.
+func Describe(err error) Details {
+	if fields := validationErrors(err); fields != nil {
+		detail := fields[0].Error()
+		if len(fields) > 1 {
+			detail = fmt.Sprintf("%d fields are invalid", len(fields))
+		}
+		return details(Details{
+			Status: http.StatusUnprocessableEntity,
+			Code:   "validation_failed",
+			Detail: detail,
+			Errors: fields,
+		})
+	}
+
+	var throttled *domain.ThrottledError
+	if errors.As(err, &throttled) {
+		return details(Details{Status: http.StatusTooManyRequests, Code: throttled.Code, Detail: throttled.Message})
+	}
+
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		return details(Details{Status: status(domainErr.Kind), Code: domainErr.Code, Detail: domainErr.Message})
+	}
+
+	log.Printf("Internal error: %v", err)
+	return details(Details{Status: http.StatusInternalServerError, Code: "internal_error", Detail: "an unexpected error occurred"})
+}
+
+// validationErrors returns the field errors in err, or nil if it is not a
+// validation error. Every field error has a code.
+func validationErrors(err error) []*domain.ValidationError {
+	var fields domain.ValidationErrors
+	var field *domain.ValidationError
+	switch {
+	case errors.As(err, &fields):
+	case errors.As(err, &field):
+		fields = domain.ValidationErrors{field}
+	default:
+		return nil
+	}
+
+	result := make([]*domain.ValidationError, len(fields))
+	for i, f := range fields {
+		copied := *f
+		if copied.Code == "" {
+			copied.Code = domain.CodeInvalid
+		}
+		result[i] = &copied
+	}
+	return result
+}
+
+func status(kind error) int {
+	switch kind {
+	case domain.ErrNotFound:
+		return http.StatusNotFound
+	case domain.ErrForbidden:
+		return http.StatusForbidden
+	case domain.ErrValidation:
+		return http.StatusUnprocessableEntity
+	case domain.ErrConflict:
+		return http.StatusConflict
+	case domain.ErrUnauthenticated:
+		return http.StatusUnauthorized
+	case domain.ErrThrottled:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// details fills in the fields that follow from the status.
+func details(p Details) Details {
+	p.Type = "about:blank"
+	p.Title = http.StatusText(p.Status)
+	return p
+}
+
+func write(w http.ResponseWriter, p Details) {
+	p = details(p)
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
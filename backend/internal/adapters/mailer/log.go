@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+// logMailer writes every message to a writer instead of sending it, so
+// links in them can be followed without a mail server.
+type logMailer struct {
+	from string
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewLogMailer(w io.Writer, from string) *logMailer {
+	return &logMailer{w: w, from: from}
+}
+
+func (m *logMailer) Send(ctx context.Context, email domain.Email) error {
+	msg, err := message(m.from, email)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, err := io.WriteString(m.w, "----- mail -----\n"); err != nil {
+		return err
+	}
+	if _, err := m.w.Write(msg); err != nil {
+		return err
+	}
+	_, err = io.WriteString(m.w, "\n----- end of mail -----\n")
+	return err
+}
@@ -0,0 +1,229 @@
+// Package conformance holds the behaviour every set of repository adapters
+// must share, so that the services work the same on each of them. Run it
+// against a fresh set of adapters; *testing.T can serve as the Reporter.
+//
+// Checks only look at rows they create themselves, under users of their
+// own, so they can run against a database that holds other data. They do
+// leave those rows behind: point them at a throwaway database.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+)
+
+// Reporter receives the failures of a check.
+type Reporter interface {
+	Errorf(format string, args ...interface{})
+}
+
+// Repositories is the set of adapters under test.
+type Repositories struct {
+	Users         ports.UserRepository
+	RefreshTokens ports.RefreshTokenRepository
+	RevokedTokens ports.RevokedTokenRepository
+	Sessions      ports.SessionRepository
+	OneTimeTokens ports.OneTimeTokenRepository
+	MFA           ports.MFARepository
+	RecoveryCodes ports.RecoveryCodeRepository
+	Todos         ports.TodoRepository
+	Revisions     ports.TodoRevisionRepository
+	Searcher      ports.TodoSearcher
+	Lists         ports.ListRepository
+	Tags          ports.TagRepository
+	Checklist     ports.ChecklistRepository
+	Workflows     ports.WorkflowRepository
+	Images        ports.ImageRepository
+	Transactor    ports.Transactor
+}
+
+// Check is one behaviour of the adapters.
+type Check struct {
+	Name string
+	run  func(c *checker)
+}
+
+// Checks lists every check, in the order Run runs them.
+var Checks = []Check{
+	{"users", checkUsers},
+	{"refresh tokens", checkRefreshTokens},
+	{"revoked tokens", checkRevokedTokens},
+	{"sessions", checkSessions},
+	{"one-time tokens", checkOneTimeTokens},
+	{"mfa", checkMFA},
+	{"recovery codes", checkRecoveryCodes},
+	{"todos", checkTodos},
+	{"todo revisions", checkTodoRevisions},
+	{"todo queries", checkTodoQueries},
+	{"todo paging", checkTodoPaging},
+	{"archiving", checkArchiving},
+	{"trash", checkTrash},
+	{"series", checkSeries},
+	{"lists", checkLists},
+	{"tags", checkTags},
+	{"checklists", checkChecklists},
+	{"workflows", checkWorkflows},
+	{"images", checkImages},
+	{"transactions", checkTransactions},
+	{"search", checkSearch},
+	{"concurrency", checkConcurrency},
+}
+
+// Run runs every check against repos and reports whether all of them
+// passed.
+func Run(ctx context.Context, r Reporter, repos Repositories) bool {
+	passed := true
+	for _, check := range Checks {
+		if !check.Run(ctx, r, repos) {
+			passed = false
+		}
+	}
+	return passed
+}
+
+// Run runs the check against repos and reports whether it passed. A check
+// stops at the first failure that leaves it nothing to go on.
+func (check Check) Run(ctx context.Context, r Reporter, repos Repositories) (passed bool) {
+	c := &checker{ctx: ctx, name: check.Name, r: r, repos: repos}
+	defer func() {
+		if v := recover(); v != nil {
+			if _, ok := v.(abort); !ok {
+				panic(v)
+			}
+		}
+		passed = !c.failed
+	}()
+	check.run(c)
+	return
+}
+
+// abort unwinds a check that cannot go on.
+type abort struct{}
+
+// checker is what a check runs with.
+type checker struct {
+	ctx    context.Context
+	name   string
+	r      Reporter
+	repos  Repositories
+	failed bool
+}
+
+func (c *checker) errorf(format string, args ...interface{}) {
+	c.failed = true
+	c.r.Errorf("%s: %s", c.name, fmt.Sprintf(format, args...))
+}
+
+func (c *checker) fatalf(format string, args ...interface{}) {
+	c.errorf(format, args...)
+	panic(abort{})
+}
+
+// must stops the check if err is not nil.
+func (c *checker) must(err error, what string) {
+	if err != nil {
+		c.fatalf("%s: %v", what, err)
+	}
+}
+
+// expectErr checks that err is target, or wraps it.
+func (c *checker) expectErr(err, target error, what string) {
+	if !errors.Is(err, target) {
+		c.errorf("%s: got error %v, want %v", what, err, target)
+	}
+}
+
+func (c *checker) equal(got, want interface{}, what string) {
+	if !reflect.DeepEqual(got, want) {
+		c.errorf("%s: got %v, want %v", what, got, want)
+	}
+}
+
+// equalTime compares times at the microsecond precision databases keep.
+func (c *checker) equalTime(got, want *time.Time, what string) {
+	switch {
+	case got == nil && want == nil:
+	case got == nil || want == nil:
+		c.errorf("%s: got %v, want %v", what, got, want)
+	case !got.Truncate(time.Microsecond).Equal(want.Truncate(time.Microsecond)):
+		c.errorf("%s: got %v, want %v", what, *got, *want)
+	}
+}
+
+var sequence int64
+
+// unique returns a suffix no other call returns, in this run or another.
+func unique() string {
+	return fmt.Sprintf("%d_%d", time.Now().UnixNano(), atomic.AddInt64(&sequence, 1))
+}
+
+const userPassword = "conformance1"
+
+func (c *checker) newUser() *domain.User {
+	suffix := unique()
+	user := &domain.User{Username: "cf_" + suffix, Email: "cf_" + suffix + "@example.com"}
+	c.must(c.repos.Users.Create(c.ctx, user, userPassword), "creating a user")
+	return user
+}
+
+func (c *checker) newList(userID int, name string) *domain.List {
+	list := &domain.List{UserID: userID, Name: name}
+	c.must(c.repos.Lists.Create(c.ctx, list), "creating a list")
+	return list
+}
+
+// newTodo creates a pending todo; edit can set more fields before it is
+// created.
+func (c *checker) newTodo(userID, listID int, title string, edit func(todo *domain.Todo)) *domain.Todo {
+	todo := &domain.Todo{
+		UserID:   userID,
+		ListID:   listID,
+		Title:    title,
+		Status:   domain.StatusPending,
+		Priority: domain.PriorityNone,
+	}
+	if edit != nil {
+		edit(todo)
+	}
+	c.must(c.repos.Todos.Create(c.ctx, todo), "creating a todo")
+	return todo
+}
+
+// update saves todo after edit changes it.
+func (c *checker) update(todo *domain.Todo, edit func(todo *domain.Todo)) {
+	edit(todo)
+	c.must(c.repos.Todos.Update(c.ctx, todo), "updating a todo")
+}
+
+func (c *checker) newTag(userID int, name string) *domain.Tag {
+	tag := &domain.Tag{UserID: userID, Name: name}
+	c.must(c.repos.Tags.Create(c.ctx, tag), "creating a tag")
+	return tag
+}
+
+func todoIDs(todos []domain.Todo) []int {
+	ids := make([]int, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+	return ids
+}
+
+func tagNames(tags []domain.Tag) []string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,282 @@
+package conformance
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+func checkLists(c *checker) {
+	lists := c.repos.Lists
+	user := c.newUser()
+
+	_, err := lists.FindInbox(c.ctx, user.ID)
+	c.expectErr(err, domain.ErrListNotFound, "FindInbox before the inbox exists")
+
+	inbox := &domain.List{UserID: user.ID, Name: domain.InboxListName, IsInbox: true}
+	c.must(lists.Create(c.ctx, inbox), "creating the inbox")
+	second := &domain.List{UserID: user.ID, Name: "second inbox", IsInbox: true}
+	c.expectErr(lists.Create(c.ctx, second), domain.ErrConflict, "creating a second inbox")
+
+	work := c.newList(user.ID, "work")
+	chores := c.newList(user.ID, "chores")
+
+	found, err := lists.FindInbox(c.ctx, user.ID)
+	c.must(err, "FindInbox")
+	c.equal(found.ID, inbox.ID, "inbox ID")
+
+	all, err := lists.FindAll(c.ctx, user.ID)
+	c.must(err, "FindAll")
+	var ids []int
+	for _, list := range all {
+		ids = append(ids, list.ID)
+	}
+	c.equal(ids, []int{inbox.ID, chores.ID, work.ID}, "lists, inbox first")
+
+	work.Name = "office"
+	c.must(lists.Update(c.ctx, work), "Update")
+	found, err = lists.FindByID(c.ctx, work.ID)
+	c.must(err, "FindByID")
+	c.equal(found.Name, "office", "renamed list")
+
+	missing := &domain.List{ID: -1, UserID: user.ID, Name: "missing"}
+	c.expectErr(lists.Update(c.ctx, missing), domain.ErrListNotFound, "Update of an unknown list")
+	c.expectErr(lists.Delete(c.ctx, -1), domain.ErrListNotFound, "Delete of an unknown list")
+	_, err = lists.FindByID(c.ctx, -1)
+	c.expectErr(err, domain.ErrListNotFound, "FindByID of an unknown list")
+
+	// MoveTodos moves every todo of a list as one change per todo
+	todo := c.newTodo(user.ID, work.ID, "Report", nil)
+	c.must(lists.MoveTodos(c.ctx, work.ID, chores.ID), "MoveTodos")
+	moved, err := c.repos.Todos.FindByID(c.ctx, todo.ID)
+	c.must(err, "FindByID of a moved todo")
+	c.equal(moved.ListID, chores.ID, "list of a moved todo")
+	c.equal(moved.Version, todo.Version+1, "version of a moved todo")
+	revisions, err := c.repos.Revisions.FindByTodoID(c.ctx, todo.ID)
+	c.must(err, "FindByTodoID")
+	if last := revisions[len(revisions)-1]; last.Changes["list_id"].New == nil {
+		c.errorf("the move was not recorded in the todo's history")
+	}
+
+	// Deleting a list takes its todos and workflow with it
+	workflow := &domain.Workflow{UserID: user.ID, ListID: chores.ID, Name: "chores", Statuses: defaultStatuses()}
+	c.must(c.repos.Workflows.Create(c.ctx, workflow), "creating a list workflow")
+	c.must(lists.Delete(c.ctx, chores.ID), "Delete")
+	_, err = lists.FindByID(c.ctx, chores.ID)
+	c.expectErr(err, domain.ErrListNotFound, "FindByID of a deleted list")
+	_, err = c.repos.Todos.FindByID(c.ctx, todo.ID)
+	c.expectErr(err, domain.ErrTodoNotFound, "FindByID of a todo of a deleted list")
+	_, err = c.repos.Workflows.FindByID(c.ctx, workflow.ID)
+	c.expectErr(err, domain.ErrWorkflowNotFound, "FindByID of the workflow of a deleted list")
+}
+
+func checkTags(c *checker) {
+	tags := c.repos.Tags
+	user := c.newUser()
+	list := c.newList(user.ID, "work")
+
+	home := c.newTag(user.ID, "Home")
+	c.expectErr(tags.Create(c.ctx, &domain.Tag{UserID: user.ID, Name: "home"}), domain.ErrConflict,
+		"creating a tag whose name differs only in case")
+	work := c.newTag(user.ID, "Work")
+	errand := c.newTag(user.ID, "Errand")
+
+	// Other users may use the same names
+	other := c.newUser()
+	c.newTag(other.ID, "Home")
+
+	all, err := tags.FindAll(c.ctx, user.ID)
+	c.must(err, "FindAll")
+	c.equal(tagNames(all), []string{"Errand", "Home", "Work"}, "tags by name")
+
+	found, err := tags.FindByNames(c.ctx, user.ID, []string{"HOME", "missing"})
+	c.must(err, "FindByNames")
+	c.equal(tagNames(found), []string{"Home"}, "tags found by name")
+
+	work.Name = "HOME"
+	c.expectErr(tags.Update(c.ctx, work), domain.ErrConflict, "renaming a tag to a taken name")
+	work.Name = "Office"
+	work.Color = "#ff0000"
+	c.must(tags.Update(c.ctx, work), "Update")
+	tag, err := tags.FindByID(c.ctx, work.ID)
+	c.must(err, "FindByID")
+	c.equal(tag.Name, "Office", "renamed tag")
+	c.equal(tag.Color, "#ff0000", "recoloured tag")
+	c.expectErr(tags.Update(c.ctx, &domain.Tag{ID: -1, Name: "missing"}), domain.ErrTagNotFound, "Update of an unknown tag")
+
+	todo := c.newTodo(user.ID, list.ID, "Shopping", nil)
+	untagged := c.newTodo(user.ID, list.ID, "Nothing", nil)
+	c.must(tags.SetTodoTags(c.ctx, todo.ID, []int{work.ID, home.ID}), "SetTodoTags")
+	byTodo, err := tags.FindByTodoIDs(c.ctx, []int{todo.ID, untagged.ID})
+	c.must(err, "FindByTodoIDs")
+	c.equal(tagNames(byTodo[todo.ID]), []string{"Home", "Office"}, "tags of a todo")
+	c.equal(len(byTodo[untagged.ID]), 0, "tags of an untagged todo")
+
+	c.must(tags.SetTodoTags(c.ctx, todo.ID, []int{errand.ID}), "SetTodoTags replacing the tags")
+	byTodo, err = tags.FindByTodoIDs(c.ctx, []int{todo.ID})
+	c.must(err, "FindByTodoIDs")
+	c.equal(tagNames(byTodo[todo.ID]), []string{"Errand"}, "replaced tags of a todo")
+
+	c.must(tags.Merge(c.ctx, home.ID, []int{errand.ID}), "Merge")
+	_, err = tags.FindByID(c.ctx, errand.ID)
+	c.expectErr(err, domain.ErrTagNotFound, "FindByID of a merged tag")
+	byTodo, err = tags.FindByTodoIDs(c.ctx, []int{todo.ID})
+	c.must(err, "FindByTodoIDs")
+	c.equal(tagNames(byTodo[todo.ID]), []string{"Home"}, "tags of a todo after a merge")
+
+	c.must(tags.Delete(c.ctx, home.ID), "Delete")
+	byTodo, err = tags.FindByTodoIDs(c.ctx, []int{todo.ID})
+	c.must(err, "FindByTodoIDs")
+	c.equal(len(byTodo[todo.ID]), 0, "tags of a todo after its tag is deleted")
+	c.expectErr(tags.Delete(c.ctx, home.ID), domain.ErrTagNotFound, "Delete of a deleted tag")
+}
+
+func checkChecklists(c *checker) {
+	checklist := c.repos.Checklist
+	user := c.newUser()
+	list := c.newList(user.ID, "work")
+	todo := c.newTodo(user.ID, list.ID, "Pack", nil)
+	empty := c.newTodo(user.ID, list.ID, "No checklist", nil)
+
+	var items []*domain.ChecklistItem
+	for i, text := range []string{"socks", "shirts", "passport"} {
+		item := &domain.ChecklistItem{TodoID: todo.ID, Text: text}
+		c.must(checklist.Create(c.ctx, item), "Create")
+		c.equal(item.Position, i, "position of a new item")
+		items = append(items, item)
+	}
+
+	items[1].Done = true
+	items[1].Text = "shirts and ties"
+	c.must(checklist.Update(c.ctx, items[1]), "Update")
+	found, err := checklist.FindByID(c.ctx, items[1].ID)
+	c.must(err, "FindByID")
+	c.equal(found.Done, true, "item done")
+	c.equal(found.Text, "shirts and ties", "item text")
+
+	progress, err := checklist.Progress(c.ctx, []int{todo.ID, empty.ID})
+	c.must(err, "Progress")
+	c.equal(progress[todo.ID], domain.ChecklistProgress{Done: 1, Total: 3}, "progress")
+	if _, ok := progress[empty.ID]; ok {
+		c.errorf("Progress reports a todo without a checklist")
+	}
+
+	c.must(checklist.Reorder(c.ctx, todo.ID, []int{items[2].ID, items[0].ID, items[1].ID}), "Reorder")
+	ordered, err := checklist.FindByTodoID(c.ctx, todo.ID)
+	c.must(err, "FindByTodoID")
+	var texts []string
+	for _, item := range ordered {
+		texts = append(texts, item.Text)
+	}
+	c.equal(texts, []string{"passport", "socks", "shirts and ties"}, "reordered checklist")
+
+	c.must(checklist.Delete(c.ctx, items[0].ID), "Delete")
+	c.expectErr(checklist.Delete(c.ctx, items[0].ID), domain.ErrChecklistItemNotFound, "Delete of a deleted item")
+	_, err = checklist.FindByID(c.ctx, items[0].ID)
+	c.expectErr(err, domain.ErrChecklistItemNotFound, "FindByID of a deleted item")
+	c.expectErr(checklist.Update(c.ctx, &domain.ChecklistItem{ID: -1, Text: "missing"}), domain.ErrChecklistItemNotFound,
+		"Update of an unknown item")
+
+	// Items go with their todo
+	c.must(c.repos.Todos.Delete(c.ctx, todo.ID), "deleting the todo")
+	_, err = checklist.FindByID(c.ctx, items[1].ID)
+	c.expectErr(err, domain.ErrChecklistItemNotFound, "FindByID of an item of a deleted todo")
+}
+
+func defaultStatuses() []domain.WorkflowStatus {
+	return []domain.WorkflowStatus{
+		{Key: domain.StatusPending, Name: "Pending", Position: 0},
+		{Key: domain.StatusDone, Name: "Done", Position: 1, Complete: true},
+	}
+}
+
+func checkWorkflows(c *checker) {
+	workflows := c.repos.Workflows
+	user := c.newUser()
+	list := c.newList(user.ID, "work")
+	other := c.newList(user.ID, "home")
+
+	effective, err := workflows.FindEffective(c.ctx, user.ID, list.ID)
+	c.must(err, "FindEffective without workflows")
+	if effective != nil {
+		c.errorf("FindEffective found a workflow before any was stored")
+	}
+
+	byDefault := &domain.Workflow{UserID: user.ID, Name: domain.DefaultWorkflowName, Statuses: defaultStatuses()}
+	c.must(workflows.Create(c.ctx, byDefault), "creating the default workflow")
+	duplicate := &domain.Workflow{UserID: user.ID, Name: "another", Statuses: defaultStatuses()}
+	c.expectErr(workflows.Create(c.ctx, duplicate), domain.ErrConflict, "creating a second default workflow")
+
+	found, err := workflows.FindByID(c.ctx, byDefault.ID)
+	c.must(err, "FindByID")
+	c.equal(found.Statuses, defaultStatuses(), "statuses")
+	c.equal(len(found.Transitions), 0, "transitions")
+
+	forList := &domain.Workflow{
+		UserID:      user.ID,
+		ListID:      list.ID,
+		Name:        "Reviewed",
+		Statuses:    defaultStatuses(),
+		Transitions: []domain.WorkflowTransition{{From: domain.StatusPending, To: domain.StatusDone}},
+	}
+	c.must(workflows.Create(c.ctx, forList), "creating a list workflow")
+	duplicate = &domain.Workflow{UserID: user.ID, ListID: list.ID, Name: "another", Statuses: defaultStatuses()}
+	c.expectErr(workflows.Create(c.ctx, duplicate), domain.ErrConflict, "creating a second workflow for a list")
+
+	effective, err = workflows.FindEffective(c.ctx, user.ID, list.ID)
+	c.must(err, "FindEffective")
+	if effective == nil || effective.ID != forList.ID {
+		c.errorf("FindEffective of a list with a workflow: got %v, want workflow %d", effective, forList.ID)
+	}
+	effective, err = workflows.FindEffective(c.ctx, user.ID, other.ID)
+	c.must(err, "FindEffective")
+	if effective == nil || effective.ID != byDefault.ID {
+		c.errorf("FindEffective of a list without a workflow: got %v, want workflow %d", effective, byDefault.ID)
+	}
+
+	all, err := workflows.FindAll(c.ctx, user.ID)
+	c.must(err, "FindAll")
+	var ids []int
+	for _, workflow := range all {
+		ids = append(ids, workflow.ID)
+	}
+	c.equal(ids, []int{byDefault.ID, forList.ID}, "workflows, default first")
+
+	forList.Name = "Reviewed twice"
+	forList.Transitions = nil
+	c.must(workflows.Update(c.ctx, forList), "Update")
+	found, err = workflows.FindByID(c.ctx, forList.ID)
+	c.must(err, "FindByID")
+	c.equal(found.Name, "Reviewed twice", "renamed workflow")
+	c.equal(len(found.Transitions), 0, "cleared transitions")
+
+	missing := &domain.Workflow{ID: -1, Name: "missing", Statuses: defaultStatuses()}
+	c.expectErr(workflows.Update(c.ctx, missing), domain.ErrWorkflowNotFound, "Update of an unknown workflow")
+	c.must(workflows.Delete(c.ctx, forList.ID), "Delete")
+	c.expectErr(workflows.Delete(c.ctx, forList.ID), domain.ErrWorkflowNotFound, "Delete of a deleted workflow")
+}
+
+func checkConcurrency(c *checker) {
+	user := c.newUser()
+
+	const writers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- c.repos.Tags.Create(c.ctx, &domain.Tag{UserID: user.ID, Name: fmt.Sprintf("tag %02d", i)})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		c.must(err, "creating tags concurrently")
+	}
+
+	all, err := c.repos.Tags.FindAll(c.ctx, user.ID)
+	c.must(err, "FindAll")
+	c.equal(len(all), writers, "tags created concurrently")
+}
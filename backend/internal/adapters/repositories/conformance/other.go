@@ -0,0 +1,174 @@
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+// upload is an in-memory multipart.File.
+type upload struct {
+	*bytes.Reader
+}
+
+func (upload) Close() error { return nil }
+
+func checkImages(c *checker) {
+	images := c.repos.Images
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 100)...)
+
+	id, err := images.Save(c.ctx, upload{bytes.NewReader(png)}, "pixel.png")
+	c.must(err, "Save")
+	if id == "" {
+		c.fatalf("Save returned no image ID")
+	}
+
+	data, contentType, err := images.Get(c.ctx, id)
+	c.must(err, "Get")
+	if !bytes.Equal(data, png) {
+		c.errorf("Get returned %d bytes that differ from the %d saved", len(data), len(png))
+	}
+	c.equal(contentType, "image/png", "content type")
+
+	_, _, err = images.Get(c.ctx, "not-a-number")
+	if err == nil {
+		c.errorf("Get of a malformed ID succeeded")
+	}
+
+	c.must(images.Delete(c.ctx, id), "Delete")
+	_, _, err = images.Get(c.ctx, id)
+	c.expectErr(err, domain.ErrImageNotFound, "Get of a deleted image")
+	c.expectErr(images.Delete(c.ctx, id), domain.ErrImageNotFound, "Delete of a deleted image")
+}
+
+func checkTransactions(c *checker) {
+	tags := c.repos.Tags
+	user := c.newUser()
+	failure := errors.New("rolled back on purpose")
+
+	exists := func(name string) bool {
+		found, err := tags.FindByNames(c.ctx, user.ID, []string{name})
+		c.must(err, "FindByNames")
+		return len(found) > 0
+	}
+
+	err := c.repos.Transactor.WithinTx(c.ctx, func(ctx context.Context) error {
+		c.must(tags.Create(ctx, &domain.Tag{UserID: user.ID, Name: "discarded"}), "creating a tag in a transaction")
+		found, err := tags.FindByNames(ctx, user.ID, []string{"discarded"})
+		c.must(err, "FindByNames in a transaction")
+		if len(found) != 1 {
+			c.errorf("a transaction does not see its own writes")
+		}
+		return failure
+	})
+	c.expectErr(err, failure, "WithinTx")
+	if exists("discarded") {
+		c.errorf("a failed transaction was not rolled back")
+	}
+
+	// A failing nested transaction only undoes its own changes
+	err = c.repos.Transactor.WithinTx(c.ctx, func(ctx context.Context) error {
+		c.must(tags.Create(ctx, &domain.Tag{UserID: user.ID, Name: "outer"}), "creating a tag in a transaction")
+		inner := c.repos.Transactor.WithinTx(ctx, func(ctx context.Context) error {
+			c.must(tags.Create(ctx, &domain.Tag{UserID: user.ID, Name: "inner"}), "creating a tag in a nested transaction")
+			return failure
+		})
+		c.expectErr(inner, failure, "nested WithinTx")
+		return nil
+	})
+	c.must(err, "WithinTx")
+	if !exists("outer") {
+		c.errorf("a committed transaction lost its changes")
+	}
+	if exists("inner") {
+		c.errorf("a failed nested transaction was not rolled back")
+	}
+
+	// A failing statement in a nested transaction leaves the outer one
+	// usable
+	err = c.repos.Transactor.WithinTx(c.ctx, func(ctx context.Context) error {
+		inner := c.repos.Transactor.WithinTx(ctx, func(ctx context.Context) error {
+			return tags.Create(ctx, &domain.Tag{UserID: user.ID, Name: "OUTER"})
+		})
+		c.expectErr(inner, domain.ErrConflict, "nested WithinTx creating a duplicate tag")
+		return tags.Create(ctx, &domain.Tag{UserID: user.ID, Name: "after"})
+	})
+	c.must(err, "WithinTx after a failed statement")
+	if !exists("after") {
+		c.errorf("a transaction lost the changes made after a failed nested one")
+	}
+}
+
+func checkSearch(c *checker) {
+	user := c.newUser()
+	work := c.newList(user.ID, "work")
+	home := c.newList(user.ID, "home")
+
+	groceries := c.newTodo(user.ID, home.ID, "Buy groceries", nil)
+	list := c.newTodo(user.ID, work.ID, "Groceries budget", nil)
+	plumber := c.newTodo(user.ID, home.ID, "Call the plumber", func(t *domain.Todo) {
+		t.Description = "The kitchen sink is leaking"
+	})
+	trashed := c.newTodo(user.ID, home.ID, "Buy groceries again", nil)
+	c.update(trashed, func(t *domain.Todo) { t.DeletedAt = timePtr(trashed.CreatedAt) })
+	archived := c.newTodo(user.ID, home.ID, "Groceries last year", nil)
+	c.update(archived, func(t *domain.Todo) { t.ArchivedAt = timePtr(archived.CreatedAt) })
+
+	search := func(text string, listID int) []domain.SearchResult {
+		results, err := c.repos.Searcher.Search(c.ctx, domain.SearchQuery{
+			UserID: user.ID, Text: text, ListID: listID, Limit: domain.DefaultSearchLimit,
+		})
+		c.must(err, "Search")
+		return results
+	}
+	ids := func(results []domain.SearchResult) []int {
+		var found []int
+		for _, result := range results {
+			found = append(found, result.Todo.ID)
+		}
+		return found
+	}
+	contains := func(results []domain.SearchResult, todo *domain.Todo) bool {
+		for _, result := range results {
+			if result.Todo.ID == todo.ID {
+				return true
+			}
+		}
+		return false
+	}
+
+	results := search("groc", 0)
+	if !contains(results, groceries) || !contains(results, list) {
+		c.errorf("searching a word prefix found %v, want todos %d and %d", ids(results), groceries.ID, list.ID)
+	}
+	if contains(results, trashed) || contains(results, archived) {
+		c.errorf("search found trashed or archived todos: %v", ids(results))
+	}
+	for _, result := range results {
+		if !strings.Contains(result.Highlights.Title, "<mark>") {
+			c.errorf("title %q is not highlighted", result.Highlights.Title)
+		}
+	}
+
+	results = search("groc", work.ID)
+	c.equal(ids(results), []int{list.ID}, "search within a list")
+
+	if results := search("leak", 0); !contains(results, plumber) {
+		c.errorf("searching the description found %v, want todo %d", ids(results), plumber.ID)
+	}
+	if results := search("plumbr", 0); !contains(results, plumber) {
+		c.errorf("searching a misspelt word found %v, want todo %d", ids(results), plumber.ID)
+	}
+	if results := search("zebra", 0); len(results) != 0 {
+		c.errorf("searching an unknown word found %v", ids(results))
+	}
+
+	results, err := c.repos.Searcher.Search(c.ctx, domain.SearchQuery{UserID: user.ID, Text: "groceries", Limit: 1})
+	c.must(err, "Search with a limit")
+	if len(results) != 1 {
+		c.errorf("search with limit 1 found %d todos", len(results))
+	}
+}
@@ -0,0 +1,377 @@
+package conformance
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+func checkTodos(c *checker) {
+	todos := c.repos.Todos
+	user := c.newUser()
+	list := c.newList(user.ID, "work")
+
+	due := time.Date(2030, 1, 2, 15, 4, 5, 123456000, time.UTC)
+	todo := c.newTodo(user.ID, list.ID, "Write report", func(t *domain.Todo) {
+		t.Description = "Quarterly numbers"
+		t.Priority = domain.PriorityHigh
+		t.Position = "a0"
+		t.DueAt = &due
+		t.RecurrenceRule = "FREQ=WEEKLY"
+		t.AutoComplete = true
+	})
+	if todo.ID == 0 {
+		c.fatalf("Create did not set the todo ID")
+	}
+	c.equal(todo.Version, 1, "version of a new todo")
+
+	found, err := todos.FindByID(c.ctx, todo.ID)
+	c.must(err, "FindByID")
+	c.equal(found.UserID, user.ID, "user ID")
+	c.equal(found.ListID, list.ID, "list ID")
+	c.equal(found.Title, "Write report", "title")
+	c.equal(found.Description, "Quarterly numbers", "description")
+	c.equal(found.Status, domain.StatusPending, "status")
+	c.equal(found.Priority, domain.PriorityHigh, "priority")
+	c.equal(found.Position, "a0", "position")
+	c.equal(found.RecurrenceRule, "FREQ=WEEKLY", "recurrence rule")
+	c.equal(found.AutoComplete, true, "auto complete")
+	c.equal(found.Version, 1, "version")
+	c.equalTime(found.DueAt, &due, "due date")
+	c.equalTime(found.StartAt, nil, "start date")
+
+	// A todo without a priority has none
+	plain := c.newTodo(user.ID, list.ID, "Plain", func(t *domain.Todo) { t.Priority = "" })
+	found, err = todos.FindByID(c.ctx, plain.ID)
+	c.must(err, "FindByID of a todo without priority")
+	c.equal(found.Priority, domain.PriorityNone, "default priority")
+
+	found, err = todos.FindByID(c.ctx, todo.ID)
+	c.must(err, "FindByID")
+	stale := *found
+	found.Title = "Write the report"
+	found.DueAt = nil
+	c.must(todos.Update(c.ctx, found), "Update")
+	c.equal(found.Version, 2, "version after Update")
+
+	updated, err := todos.FindByID(c.ctx, todo.ID)
+	c.must(err, "FindByID after Update")
+	c.equal(updated.Title, "Write the report", "updated title")
+	c.equal(updated.Version, 2, "stored version after Update")
+	c.equalTime(updated.DueAt, nil, "cleared due date")
+
+	stale.Title = "Lost update"
+	c.expectErr(todos.Update(c.ctx, &stale), domain.ErrVersionConflict, "Update of a stale version")
+	missing := *updated
+	missing.ID = -1
+	c.expectErr(todos.Update(c.ctx, &missing), domain.ErrTodoNotFound, "Update of an unknown todo")
+
+	c.must(todos.Delete(c.ctx, todo.ID), "Delete")
+	_, err = todos.FindByID(c.ctx, todo.ID)
+	c.expectErr(err, domain.ErrTodoNotFound, "FindByID after Delete")
+	c.expectErr(todos.Delete(c.ctx, todo.ID), domain.ErrTodoNotFound, "Delete of a deleted todo")
+}
+
+func checkTodoRevisions(c *checker) {
+	user := c.newUser()
+	list := c.newList(user.ID, "work")
+	ctx := domain.WithActor(c.ctx, user.ID)
+
+	todo := &domain.Todo{UserID: user.ID, ListID: list.ID, Title: "Draft", Status: domain.StatusPending, Priority: domain.PriorityNone}
+	c.must(c.repos.Todos.Create(ctx, todo), "Create")
+	todo.Title = "Final"
+	c.must(c.repos.Todos.Update(ctx, todo), "Update")
+	// Saving without changes records nothing
+	c.must(c.repos.Todos.Update(ctx, todo), "Update without changes")
+	c.must(c.repos.Todos.Delete(ctx, todo.ID), "Delete")
+
+	revisions, err := c.repos.Revisions.FindByTodoID(c.ctx, todo.ID)
+	c.must(err, "FindByTodoID")
+	if len(revisions) != 3 {
+		c.fatalf("got %d revisions, want 3", len(revisions))
+	}
+	for i, action := range []string{domain.RevisionCreate, domain.RevisionUpdate, domain.RevisionDelete} {
+		revision := revisions[i]
+		c.equal(revision.Revision, i+1, "revision number")
+		c.equal(revision.Action, action, "revision action")
+		c.equal(revision.TodoID, todo.ID, "revision todo ID")
+		c.equal(revision.UserID, user.ID, "revision user ID")
+		c.equal(revision.ActorID, user.ID, "revision actor ID")
+	}
+	if change, ok := revisions[1].Changes["title"]; !ok {
+		c.errorf("the update revision does not record the title")
+	} else {
+		c.equal(string(change.Old), `"Draft"`, "old title")
+		c.equal(string(change.New), `"Final"`, "new title")
+	}
+	if len(revisions[1].Changes) != 1 {
+		c.errorf("the update revision records %d fields, want only the title", len(revisions[1].Changes))
+	}
+}
+
+// queryFixture is a user with todos covering every filter.
+type queryFixture struct {
+	user                    *domain.User
+	work, home              *domain.List
+	milk, call, rent, today *domain.Todo
+	trashed, archived       *domain.Todo
+	now                     time.Time
+}
+
+func newQueryFixture(c *checker) *queryFixture {
+	f := &queryFixture{now: time.Date(2030, 6, 15, 12, 0, 0, 0, time.UTC)}
+	f.user = c.newUser()
+	f.work = c.newList(f.user.ID, "work")
+	f.home = c.newList(f.user.ID, "home")
+	uid := f.user.ID
+
+	f.milk = c.newTodo(uid, f.work.ID, "Buy milk", func(t *domain.Todo) {
+		t.Priority = domain.PriorityLow
+		t.Position = "a1"
+		t.DueAt = timePtr(time.Date(2030, 7, 1, 9, 0, 0, 0, time.UTC))
+	})
+	f.call = c.newTodo(uid, f.work.ID, "Call mom", func(t *domain.Todo) {
+		t.Status = domain.StatusDone
+		t.CompletedAt = timePtr(f.now.Add(-time.Hour))
+		t.Priority = domain.PriorityHigh
+		t.Position = "a2"
+	})
+	f.rent = c.newTodo(uid, f.home.ID, "Pay rent", func(t *domain.Todo) {
+		t.Description = "Milk money too"
+		t.Priority = domain.PriorityUrgent
+		t.Position = "a0"
+		t.DueAt = timePtr(time.Date(2030, 6, 14, 9, 0, 0, 0, time.UTC))
+	})
+	// Due at midnight, so due all day and not overdue at noon
+	f.today = c.newTodo(uid, f.home.ID, "Water plants", func(t *domain.Todo) {
+		t.Position = "a3"
+		t.DueAt = timePtr(time.Date(2030, 6, 15, 0, 0, 0, 0, time.UTC))
+	})
+	f.trashed = c.newTodo(uid, f.work.ID, "Old milk", nil)
+	c.update(f.trashed, func(t *domain.Todo) { t.DeletedAt = timePtr(f.now) })
+	f.archived = c.newTodo(uid, f.work.ID, "Archived", nil)
+	c.update(f.archived, func(t *domain.Todo) { t.ArchivedAt = timePtr(f.now) })
+
+	errand := c.newTag(uid, "Errand")
+	family := c.newTag(uid, "Family")
+	c.must(c.repos.Tags.SetTodoTags(c.ctx, f.milk.ID, []int{errand.ID}), "tagging a todo")
+	c.must(c.repos.Tags.SetTodoTags(c.ctx, f.call.ID, []int{errand.ID, family.ID}), "tagging a todo")
+	return f
+}
+
+// find runs q for the fixture's user and returns the IDs it finds, in
+// order.
+func (f *queryFixture) find(c *checker, q domain.TodoQuery) []int {
+	q.UserID = f.user.ID
+	q.Now = f.now
+	q.Location = time.UTC
+	c.must(q.Normalize(), "normalizing a query")
+	page, err := c.repos.Todos.FindAll(c.ctx, q)
+	c.must(err, "FindAll")
+	return todoIDs(page.Todos)
+}
+
+// findSet is find for queries whose order does not matter.
+func (f *queryFixture) findSet(c *checker, q domain.TodoQuery) []int {
+	ids := f.find(c, q)
+	sort.Ints(ids)
+	return ids
+}
+
+func idSet(todos ...*domain.Todo) []int {
+	ids := idList(todos...)
+	sort.Ints(ids)
+	return ids
+}
+
+func idList(todos ...*domain.Todo) []int {
+	ids := make([]int, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+	return ids
+}
+
+func checkTodoQueries(c *checker) {
+	f := newQueryFixture(c)
+	live := idSet(f.milk, f.call, f.rent, f.today)
+
+	c.equal(f.findSet(c, domain.TodoQuery{}), live, "live todos")
+	c.equal(f.findSet(c, domain.TodoQuery{ListID: f.work.ID}), idSet(f.milk, f.call), "todos of a list")
+	c.equal(f.findSet(c, domain.TodoQuery{Statuses: []string{domain.StatusDone}}), idSet(f.call), "todos by status")
+	c.equal(f.findSet(c, domain.TodoQuery{Text: "MILK"}), idSet(f.milk, f.rent), "text search on title and description")
+	c.equal(f.findSet(c, domain.TodoQuery{Text: "50%"}), []int{}, "text search escapes wildcards")
+	c.equal(f.findSet(c, domain.TodoQuery{Trashed: true}), idSet(f.trashed), "trashed todos")
+	c.equal(f.findSet(c, domain.TodoQuery{Archived: true}), idSet(f.archived), "archived todos")
+	c.equal(f.findSet(c, domain.TodoQuery{IncludeArchived: true}), idSet(f.milk, f.call, f.rent, f.today, f.archived), "todos including archived")
+	c.equal(f.findSet(c, domain.TodoQuery{Tags: []string{"ERRAND"}}), idSet(f.milk, f.call), "todos with any tag")
+	c.equal(f.findSet(c, domain.TodoQuery{Tags: []string{"errand", "family"}, TagMatch: domain.TagMatchAll}), idSet(f.call), "todos with all tags")
+	c.equal(f.findSet(c, domain.TodoQuery{Tags: []string{"missing"}}), []int{}, "todos with an unknown tag")
+	c.equal(f.findSet(c, domain.TodoQuery{Overdue: true}), idSet(f.rent), "overdue todos")
+	c.equal(f.findSet(c, domain.TodoQuery{DueBefore: f.milk.DueAt}), idSet(f.rent, f.today), "todos due before")
+	c.equal(f.findSet(c, domain.TodoQuery{DueAfter: f.rent.DueAt}), idSet(f.milk, f.today), "todos due after")
+
+	c.equal(f.find(c, domain.TodoQuery{Sort: domain.SortDueAt, Order: domain.SortAsc}),
+		idList(f.rent, f.today, f.milk, f.call), "todos by due date, undated last")
+	c.equal(f.find(c, domain.TodoQuery{Sort: domain.SortPriority}),
+		idList(f.rent, f.call, f.milk, f.today), "todos by priority")
+	c.equal(f.find(c, domain.TodoQuery{Sort: domain.SortPosition}),
+		idList(f.rent, f.milk, f.call, f.today), "todos by position")
+	c.equal(f.find(c, domain.TodoQuery{Sort: domain.SortTitle, Order: domain.SortAsc}),
+		idList(f.milk, f.call, f.rent, f.today), "todos by title")
+}
+
+func checkTodoPaging(c *checker) {
+	f := newQueryFixture(c)
+
+	for _, q := range []domain.TodoQuery{
+		{Sort: domain.SortDueAt, Order: domain.SortAsc},
+		{Sort: domain.SortDueAt, Order: domain.SortDesc},
+		{Sort: domain.SortPriority},
+		{Sort: domain.SortPosition},
+		{Sort: domain.SortCreatedAt},
+		{Sort: domain.SortArchived, IncludeArchived: true},
+	} {
+		all := f.find(c, q)
+
+		var paged []int
+		query := q
+		query.Limit = 1
+		for pages := 0; ; pages++ {
+			if pages > len(all) {
+				c.fatalf("paging by %s %s does not end", q.Sort, q.Order)
+			}
+			query.UserID = f.user.ID
+			query.Now = f.now
+			c.must(query.Normalize(), "normalizing a query")
+			page, err := c.repos.Todos.FindAll(c.ctx, query)
+			c.must(err, "FindAll with a cursor")
+			paged = append(paged, todoIDs(page.Todos)...)
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+		c.equal(paged, all, "todos paged by "+q.Sort+" "+q.Order)
+	}
+
+	// A cursor is only good for the order it was issued for
+	q := domain.TodoQuery{UserID: f.user.ID, Sort: domain.SortTitle, Limit: 1}
+	c.must(q.Normalize(), "normalizing a query")
+	page, err := c.repos.Todos.FindAll(c.ctx, q)
+	c.must(err, "FindAll")
+	q = domain.TodoQuery{UserID: f.user.ID, Sort: domain.SortDueAt, Cursor: page.NextCursor}
+	c.must(q.Normalize(), "normalizing a query")
+	_, err = c.repos.Todos.FindAll(c.ctx, q)
+	c.expectErr(err, domain.ErrValidation, "FindAll with a cursor of another sort")
+}
+
+func checkArchiving(c *checker) {
+	user := c.newUser()
+	list := c.newList(user.ID, "work")
+	now := time.Now()
+	longAgo := now.Add(-10 * 24 * time.Hour)
+
+	old := c.newTodo(user.ID, list.ID, "Done long ago", func(t *domain.Todo) {
+		t.Status = domain.StatusDone
+		t.CompletedAt = &longAgo
+	})
+	recent := c.newTodo(user.ID, list.ID, "Done just now", func(t *domain.Todo) {
+		t.Status = domain.StatusDone
+		t.CompletedAt = &now
+	})
+	open := c.newTodo(user.ID, list.ID, "Not done", nil)
+	trashed := c.newTodo(user.ID, list.ID, "Done and trashed", func(t *domain.Todo) {
+		t.Status = domain.StatusDone
+		t.CompletedAt = &longAgo
+	})
+	c.update(trashed, func(t *domain.Todo) { t.DeletedAt = &now })
+
+	archived, err := c.repos.Todos.ArchiveCompleted(c.ctx, user.ID, 0, now.Add(-24*time.Hour))
+	c.must(err, "ArchiveCompleted")
+	c.equal(archived, 1, "archived todos")
+
+	found, err := c.repos.Todos.FindByID(c.ctx, old.ID)
+	c.must(err, "FindByID")
+	if found.ArchivedAt == nil {
+		c.errorf("the old completed todo was not archived")
+	}
+	c.equal(found.Version, old.Version+1, "version of an archived todo")
+	for _, todo := range []*domain.Todo{recent, open, trashed} {
+		found, err := c.repos.Todos.FindByID(c.ctx, todo.ID)
+		c.must(err, "FindByID")
+		if found.ArchivedAt != nil {
+			c.errorf("todo %q was archived", todo.Title)
+		}
+	}
+
+	revisions, err := c.repos.Revisions.FindByTodoID(c.ctx, old.ID)
+	c.must(err, "FindByTodoID")
+	if last := revisions[len(revisions)-1]; last.Changes["archived_at"].New == nil {
+		c.errorf("archiving was not recorded in the todo's history")
+	}
+
+	archived, err = c.repos.Todos.ArchiveCompleted(c.ctx, user.ID, 0, now.Add(-24*time.Hour))
+	c.must(err, "ArchiveCompleted again")
+	c.equal(archived, 0, "todos archived twice")
+}
+
+func checkTrash(c *checker) {
+	user := c.newUser()
+	list := c.newList(user.ID, "work")
+	now := time.Now()
+
+	var trashed []*domain.Todo
+	for _, age := range []time.Duration{48 * time.Hour, 72 * time.Hour, time.Hour} {
+		deletedAt := now.Add(-age)
+		todo := c.newTodo(user.ID, list.ID, "Trashed", nil)
+		c.update(todo, func(t *domain.Todo) { t.DeletedAt = &deletedAt })
+		trashed = append(trashed, todo)
+	}
+
+	// The trash is shared by every user; only look at this user's todos
+	found, err := c.repos.Todos.FindTrashedBefore(c.ctx, now.Add(-24*time.Hour), 1000)
+	c.must(err, "FindTrashedBefore")
+	var mine []int
+	for _, todo := range found {
+		if todo.UserID == user.ID {
+			mine = append(mine, todo.ID)
+		}
+	}
+	c.equal(mine, idList(trashed[1], trashed[0]), "todos trashed before, oldest first")
+
+	found, err = c.repos.Todos.FindTrashedBefore(c.ctx, now.Add(-24*time.Hour), 1)
+	c.must(err, "FindTrashedBefore with a limit")
+	if len(found) != 1 {
+		c.errorf("FindTrashedBefore with limit 1 returned %d todos", len(found))
+	}
+}
+
+func checkSeries(c *checker) {
+	user := c.newUser()
+	list := c.newList(user.ID, "work")
+
+	first := c.newTodo(user.ID, list.ID, "Standup", func(t *domain.Todo) {
+		t.RecurrenceRule = "FREQ=DAILY"
+		t.RecurrenceIndex = 1
+	})
+	third := c.newTodo(user.ID, list.ID, "Standup", func(t *domain.Todo) {
+		t.SeriesID = first.ID
+		t.RecurrenceIndex = 3
+	})
+	second := c.newTodo(user.ID, list.ID, "Standup", func(t *domain.Todo) {
+		t.SeriesID = first.ID
+		t.RecurrenceIndex = 2
+	})
+
+	series, err := c.repos.Todos.FindSeries(c.ctx, first.ID)
+	c.must(err, "FindSeries")
+	c.equal(todoIDs(series), idList(first, second, third), "series in order")
+
+	// Deleting the first todo detaches the rest
+	c.must(c.repos.Todos.Delete(c.ctx, first.ID), "Delete")
+	found, err := c.repos.Todos.FindByID(c.ctx, second.ID)
+	c.must(err, "FindByID")
+	c.equal(found.SeriesID, 0, "series ID after the first todo is deleted")
+}
@@ -0,0 +1,413 @@
+package conformance
+
+import (
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+func (c *checker) newRefreshToken(userID int, familyID string, expiresAt time.Time) *domain.RefreshToken {
+	token := &domain.RefreshToken{
+		UserID:          userID,
+		FamilyID:        familyID,
+		TokenHash:       "hash_" + unique(),
+		AccessTokenID:   "jti_" + unique(),
+		AccessExpiresAt: time.Now().Add(15 * time.Minute),
+		ExpiresAt:       expiresAt,
+	}
+	c.must(c.repos.RefreshTokens.Create(c.ctx, token), "creating a refresh token")
+	return token
+}
+
+func checkRefreshTokens(c *checker) {
+	tokens := c.repos.RefreshTokens
+	user := c.newUser()
+	family := "family_" + unique()
+	expiresAt := time.Now().Add(time.Hour)
+
+	first := c.newRefreshToken(user.ID, family, expiresAt)
+	if first.ID == 0 {
+		c.fatalf("Create did not set the token ID")
+	}
+
+	found, err := tokens.FindByHash(c.ctx, first.TokenHash)
+	c.must(err, "FindByHash")
+	c.equal(found.ID, first.ID, "FindByHash ID")
+	c.equal(found.UserID, user.ID, "user ID")
+	c.equal(found.FamilyID, family, "family ID")
+	c.equal(found.AccessTokenID, first.AccessTokenID, "access token ID")
+	c.equalTime(&found.AccessExpiresAt, &first.AccessExpiresAt, "access token expiry")
+	c.equalTime(&found.ExpiresAt, &expiresAt, "expiry")
+	c.equalTime(found.UsedAt, nil, "used at of a new token")
+	c.equalTime(found.RevokedAt, nil, "revoked at of a new token")
+	_, err = tokens.FindByHash(c.ctx, "missing_"+unique())
+	c.expectErr(err, domain.ErrInvalidRefreshToken, "FindByHash of an unknown hash")
+
+	// A token is used up once
+	usedAt := time.Now()
+	marked, err := tokens.MarkUsed(c.ctx, first.ID, usedAt)
+	c.must(err, "MarkUsed")
+	c.equal(marked, true, "MarkUsed of a live token")
+	marked, err = tokens.MarkUsed(c.ctx, first.ID, time.Now())
+	c.must(err, "MarkUsed again")
+	c.equal(marked, false, "MarkUsed of a used token")
+	found, err = tokens.FindByHash(c.ctx, first.TokenHash)
+	c.must(err, "FindByHash after MarkUsed")
+	c.equalTime(found.UsedAt, &usedAt, "used at")
+
+	second := c.newRefreshToken(user.ID, family, expiresAt)
+	other := c.newRefreshToken(user.ID, "family_"+unique(), expiresAt)
+	inFamily, err := tokens.FindByFamily(c.ctx, family)
+	c.must(err, "FindByFamily")
+	ids := make([]int, len(inFamily))
+	for i, token := range inFamily {
+		ids[i] = token.ID
+	}
+	c.equal(ids, []int{first.ID, second.ID}, "FindByFamily IDs")
+
+	revokedAt := time.Now()
+	c.must(tokens.RevokeFamily(c.ctx, family, revokedAt), "RevokeFamily")
+	for _, token := range []*domain.RefreshToken{first, second} {
+		found, err = tokens.FindByHash(c.ctx, token.TokenHash)
+		c.must(err, "FindByHash after RevokeFamily")
+		c.equalTime(found.RevokedAt, &revokedAt, "revoked at")
+	}
+	found, err = tokens.FindByHash(c.ctx, other.TokenHash)
+	c.must(err, "FindByHash of another family")
+	c.equalTime(found.RevokedAt, nil, "revoked at of another family")
+	marked, err = tokens.MarkUsed(c.ctx, second.ID, time.Now())
+	c.must(err, "MarkUsed of a revoked token")
+	c.equal(marked, false, "MarkUsed of a revoked token")
+
+	expired := c.newRefreshToken(user.ID, "family_"+unique(), time.Now().Add(-time.Minute))
+	deleted, err := tokens.DeleteExpired(c.ctx, time.Now())
+	c.must(err, "DeleteExpired")
+	if deleted < 1 {
+		c.errorf("DeleteExpired deleted %d tokens, want at least 1", deleted)
+	}
+	_, err = tokens.FindByHash(c.ctx, expired.TokenHash)
+	c.expectErr(err, domain.ErrInvalidRefreshToken, "FindByHash of an expired token")
+	_, err = tokens.FindByHash(c.ctx, other.TokenHash)
+	c.must(err, "FindByHash of a token that has not expired")
+}
+
+func checkRevokedTokens(c *checker) {
+	revoked := c.repos.RevokedTokens
+	tokenID := "jti_" + unique()
+
+	isRevoked, err := revoked.IsRevoked(c.ctx, tokenID)
+	c.must(err, "IsRevoked")
+	c.equal(isRevoked, false, "IsRevoked of a token never revoked")
+
+	c.must(revoked.Revoke(c.ctx, tokenID, time.Now().Add(time.Hour)), "Revoke")
+	c.must(revoked.Revoke(c.ctx, tokenID, time.Now().Add(time.Hour)), "Revoke again")
+	isRevoked, err = revoked.IsRevoked(c.ctx, tokenID)
+	c.must(err, "IsRevoked after Revoke")
+	c.equal(isRevoked, true, "IsRevoked of a revoked token")
+
+	expiredID := "jti_" + unique()
+	c.must(revoked.Revoke(c.ctx, expiredID, time.Now().Add(-time.Minute)), "Revoke an expired token")
+	deleted, err := revoked.DeleteExpired(c.ctx, time.Now())
+	c.must(err, "DeleteExpired")
+	if deleted < 1 {
+		c.errorf("DeleteExpired deleted %d entries, want at least 1", deleted)
+	}
+	isRevoked, err = revoked.IsRevoked(c.ctx, expiredID)
+	c.must(err, "IsRevoked after DeleteExpired")
+	c.equal(isRevoked, false, "IsRevoked of an expired entry")
+	isRevoked, err = revoked.IsRevoked(c.ctx, tokenID)
+	c.must(err, "IsRevoked of an entry that has not expired")
+	c.equal(isRevoked, true, "IsRevoked of an entry that has not expired")
+}
+
+func (c *checker) newSession(userID int, seenAt, expiresAt time.Time) *domain.Session {
+	session := &domain.Session{
+		ID:         "session_" + unique(),
+		UserID:     userID,
+		UserAgent:  "conformance",
+		IP:         "192.0.2.1",
+		CreatedAt:  seenAt,
+		LastSeenAt: seenAt,
+		ExpiresAt:  expiresAt,
+	}
+	c.must(c.repos.Sessions.Create(c.ctx, session), "creating a session")
+	return session
+}
+
+func sessionIDs(sessions []domain.Session) []string {
+	ids := make([]string, len(sessions))
+	for i, session := range sessions {
+		ids[i] = session.ID
+	}
+	return ids
+}
+
+func checkSessions(c *checker) {
+	sessions := c.repos.Sessions
+	user := c.newUser()
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+
+	older := c.newSession(user.ID, now.Add(-time.Hour), expiresAt)
+	newer := c.newSession(user.ID, now.Add(-time.Minute), expiresAt)
+	expired := c.newSession(user.ID, now.Add(-2*time.Hour), now.Add(-time.Minute))
+
+	found, err := sessions.FindByID(c.ctx, older.ID)
+	c.must(err, "FindByID")
+	c.equal(found.UserID, user.ID, "user ID")
+	c.equal(found.UserAgent, "conformance", "user agent")
+	c.equal(found.IP, "192.0.2.1", "IP")
+	c.equalTime(&found.LastSeenAt, &older.LastSeenAt, "last seen at")
+	c.equalTime(&found.ExpiresAt, &expiresAt, "expires at")
+	c.equalTime(found.RevokedAt, nil, "revoked at of a new session")
+	_, err = sessions.FindByID(c.ctx, "missing_"+unique())
+	c.expectErr(err, domain.ErrSessionNotFound, "FindByID of an unknown session")
+
+	active, err := sessions.FindActive(c.ctx, user.ID, now)
+	c.must(err, "FindActive")
+	c.equal(sessionIDs(active), []string{newer.ID, older.ID}, "FindActive, most recently seen first")
+
+	// Touch moves last-seen times forwards only, and skips unknown sessions
+	c.must(sessions.Touch(c.ctx, map[string]time.Time{
+		older.ID:              now,
+		newer.ID:              now.Add(-2 * time.Minute),
+		"missing_" + unique(): now,
+	}), "Touch")
+	active, err = sessions.FindActive(c.ctx, user.ID, now)
+	c.must(err, "FindActive after Touch")
+	c.equal(sessionIDs(active), []string{older.ID, newer.ID}, "FindActive after Touch")
+	found, err = sessions.FindByID(c.ctx, newer.ID)
+	c.must(err, "FindByID after Touch")
+	c.equalTime(&found.LastSeenAt, &newer.LastSeenAt, "last seen at touched with an older time")
+
+	extended := now.Add(2 * time.Hour)
+	c.must(sessions.Extend(c.ctx, expired.ID, now, extended), "Extend")
+	found, err = sessions.FindByID(c.ctx, expired.ID)
+	c.must(err, "FindByID after Extend")
+	c.equalTime(&found.ExpiresAt, &extended, "expires at after Extend")
+	c.equalTime(&found.LastSeenAt, &now, "last seen at after Extend")
+	c.expectErr(sessions.Extend(c.ctx, "missing_"+unique(), now, extended), domain.ErrSessionNotFound, "Extend of an unknown session")
+
+	revokedAt := time.Now()
+	c.must(sessions.Revoke(c.ctx, newer.ID, revokedAt), "Revoke")
+	c.must(sessions.Revoke(c.ctx, newer.ID, time.Now()), "Revoke again")
+	c.must(sessions.Revoke(c.ctx, "missing_"+unique(), time.Now()), "Revoke of an unknown session")
+	found, err = sessions.FindByID(c.ctx, newer.ID)
+	c.must(err, "FindByID of a revoked session")
+	c.equalTime(found.RevokedAt, &revokedAt, "revoked at")
+	active, err = sessions.FindActive(c.ctx, user.ID, now)
+	c.must(err, "FindActive after Revoke")
+	c.equal(sessionIDs(active), []string{older.ID, expired.ID}, "FindActive after Revoke")
+
+	gone := c.newSession(user.ID, now.Add(-2*time.Hour), now.Add(-time.Minute))
+	deleted, err := sessions.DeleteExpired(c.ctx, now)
+	c.must(err, "DeleteExpired")
+	if deleted < 1 {
+		c.errorf("DeleteExpired deleted %d sessions, want at least 1", deleted)
+	}
+	_, err = sessions.FindByID(c.ctx, gone.ID)
+	c.expectErr(err, domain.ErrSessionNotFound, "FindByID of an expired session")
+	_, err = sessions.FindByID(c.ctx, older.ID)
+	c.must(err, "FindByID of a session that has not expired")
+}
+
+func (c *checker) newOneTimeToken(userID int, purpose string, expiresAt time.Time) *domain.OneTimeToken {
+	token := &domain.OneTimeToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: "hash_" + unique(),
+		ExpiresAt: expiresAt,
+	}
+	c.must(c.repos.OneTimeTokens.Create(c.ctx, token), "creating a one-time token")
+	return token
+}
+
+func checkOneTimeTokens(c *checker) {
+	tokens := c.repos.OneTimeTokens
+	user := c.newUser()
+	other := c.newUser()
+	purpose := domain.TokenPurposePasswordReset
+	expiresAt := time.Now().Add(time.Hour)
+
+	first := c.newOneTimeToken(user.ID, purpose, expiresAt)
+	if first.ID == 0 {
+		c.fatalf("Create did not set the token ID")
+	}
+
+	found, err := tokens.FindByHash(c.ctx, purpose, first.TokenHash)
+	c.must(err, "FindByHash")
+	c.equal(found.ID, first.ID, "FindByHash ID")
+	c.equal(found.UserID, user.ID, "user ID")
+	c.equal(found.Purpose, purpose, "purpose")
+	c.equalTime(&found.ExpiresAt, &expiresAt, "expiry")
+	c.equalTime(found.UsedAt, nil, "used at of a new token")
+	_, err = tokens.FindByHash(c.ctx, purpose, "missing_"+unique())
+	c.expectErr(err, domain.ErrOneTimeTokenNotFound, "FindByHash of an unknown hash")
+	_, err = tokens.FindByHash(c.ctx, "other_purpose", first.TokenHash)
+	c.expectErr(err, domain.ErrOneTimeTokenNotFound, "FindByHash for another purpose")
+
+	// A token is used up once
+	usedAt := time.Now()
+	consumed, err := tokens.Consume(c.ctx, first.ID, usedAt)
+	c.must(err, "Consume")
+	c.equal(consumed, true, "Consume of an unused token")
+	consumed, err = tokens.Consume(c.ctx, first.ID, time.Now())
+	c.must(err, "Consume again")
+	c.equal(consumed, false, "Consume of a used token")
+	found, err = tokens.FindByHash(c.ctx, purpose, first.TokenHash)
+	c.must(err, "FindByHash after Consume")
+	c.equalTime(found.UsedAt, &usedAt, "used at")
+
+	// ConsumeAll only touches unused tokens of the user for the purpose
+	second := c.newOneTimeToken(user.ID, purpose, expiresAt)
+	otherPurpose := c.newOneTimeToken(user.ID, "other_purpose", expiresAt)
+	otherUser := c.newOneTimeToken(other.ID, purpose, expiresAt)
+	consumedAt := time.Now()
+	c.must(tokens.ConsumeAll(c.ctx, user.ID, purpose, consumedAt), "ConsumeAll")
+	found, err = tokens.FindByHash(c.ctx, purpose, second.TokenHash)
+	c.must(err, "FindByHash after ConsumeAll")
+	c.equalTime(found.UsedAt, &consumedAt, "used at after ConsumeAll")
+	found, err = tokens.FindByHash(c.ctx, purpose, first.TokenHash)
+	c.must(err, "FindByHash of a token used before ConsumeAll")
+	c.equalTime(found.UsedAt, &usedAt, "used at of a token used before ConsumeAll")
+	found, err = tokens.FindByHash(c.ctx, "other_purpose", otherPurpose.TokenHash)
+	c.must(err, "FindByHash of a token for another purpose")
+	c.equalTime(found.UsedAt, nil, "used at of a token for another purpose")
+	found, err = tokens.FindByHash(c.ctx, purpose, otherUser.TokenHash)
+	c.must(err, "FindByHash of a token of another user")
+	c.equalTime(found.UsedAt, nil, "used at of a token of another user")
+
+	latest, err := tokens.FindLatest(c.ctx, user.ID, purpose)
+	c.must(err, "FindLatest")
+	c.equal(latest.ID, second.ID, "FindLatest ID")
+	_, err = tokens.FindLatest(c.ctx, c.newUser().ID, purpose)
+	c.expectErr(err, domain.ErrOneTimeTokenNotFound, "FindLatest of a user without tokens")
+
+	expired := c.newOneTimeToken(user.ID, purpose, time.Now().Add(-time.Minute))
+	deleted, err := tokens.DeleteExpired(c.ctx, time.Now())
+	c.must(err, "DeleteExpired")
+	if deleted < 1 {
+		c.errorf("DeleteExpired deleted %d tokens, want at least 1", deleted)
+	}
+	_, err = tokens.FindByHash(c.ctx, purpose, expired.TokenHash)
+	c.expectErr(err, domain.ErrOneTimeTokenNotFound, "FindByHash of an expired token")
+	_, err = tokens.FindByHash(c.ctx, purpose, second.TokenHash)
+	c.must(err, "FindByHash of a token that has not expired")
+}
+
+func checkMFA(c *checker) {
+	mfaRepo := c.repos.MFA
+	user := c.newUser()
+	missing := c.newUser()
+
+	c.must(mfaRepo.Save(c.ctx, &domain.MFA{UserID: user.ID, Secret: "first"}), "Save")
+	c.must(mfaRepo.Save(c.ctx, &domain.MFA{UserID: user.ID, Secret: "second"}), "Save over earlier settings")
+	found, err := mfaRepo.FindByUserID(c.ctx, user.ID)
+	c.must(err, "FindByUserID")
+	c.equal(found.Secret, "second", "secret after Save over earlier settings")
+	c.equalTime(found.EnabledAt, nil, "enabled at of new settings")
+	c.equal(found.LastUsedStep, int64(0), "last used step of new settings")
+	_, err = mfaRepo.FindByUserID(c.ctx, missing.ID)
+	c.expectErr(err, domain.ErrMFANotFound, "FindByUserID of a user without settings")
+
+	enabledAt := time.Now()
+	c.must(mfaRepo.Enable(c.ctx, user.ID, enabledAt), "Enable")
+	found, err = mfaRepo.FindByUserID(c.ctx, user.ID)
+	c.must(err, "FindByUserID after Enable")
+	c.equalTime(found.EnabledAt, &enabledAt, "enabled at")
+	c.expectErr(mfaRepo.Enable(c.ctx, missing.ID, enabledAt), domain.ErrMFANotFound, "Enable of a user without settings")
+
+	// Every step is used once, and never before a later one
+	used, err := mfaRepo.UseStep(c.ctx, user.ID, 100)
+	c.must(err, "UseStep")
+	c.equal(used, true, "UseStep of a new step")
+	used, err = mfaRepo.UseStep(c.ctx, user.ID, 100)
+	c.must(err, "UseStep again")
+	c.equal(used, false, "UseStep of a used step")
+	used, err = mfaRepo.UseStep(c.ctx, user.ID, 99)
+	c.must(err, "UseStep of an earlier step")
+	c.equal(used, false, "UseStep of an earlier step")
+	used, err = mfaRepo.UseStep(c.ctx, missing.ID, 100)
+	c.must(err, "UseStep of a user without settings")
+	c.equal(used, false, "UseStep of a user without settings")
+	found, err = mfaRepo.FindByUserID(c.ctx, user.ID)
+	c.must(err, "FindByUserID after UseStep")
+	c.equal(found.LastUsedStep, int64(100), "last used step")
+
+	failures, err := mfaRepo.AddFailure(c.ctx, user.ID)
+	c.must(err, "AddFailure")
+	c.equal(failures, 1, "failures after AddFailure")
+	failures, err = mfaRepo.AddFailure(c.ctx, user.ID)
+	c.must(err, "AddFailure again")
+	c.equal(failures, 2, "failures after AddFailure again")
+	_, err = mfaRepo.AddFailure(c.ctx, missing.ID)
+	c.expectErr(err, domain.ErrMFANotFound, "AddFailure of a user without settings")
+
+	lockedUntil := time.Now().Add(time.Hour)
+	c.must(mfaRepo.Lock(c.ctx, user.ID, lockedUntil), "Lock")
+	found, err = mfaRepo.FindByUserID(c.ctx, user.ID)
+	c.must(err, "FindByUserID after Lock")
+	c.equalTime(found.LockedUntil, &lockedUntil, "locked until")
+	c.equal(found.FailedAttempts, 0, "failures after Lock")
+
+	_, err = mfaRepo.AddFailure(c.ctx, user.ID)
+	c.must(err, "AddFailure after Lock")
+	c.must(mfaRepo.ClearFailures(c.ctx, user.ID), "ClearFailures")
+	found, err = mfaRepo.FindByUserID(c.ctx, user.ID)
+	c.must(err, "FindByUserID after ClearFailures")
+	c.equal(found.FailedAttempts, 0, "failures after ClearFailures")
+	c.expectErr(mfaRepo.ClearFailures(c.ctx, missing.ID), domain.ErrMFANotFound, "ClearFailures of a user without settings")
+
+	c.must(mfaRepo.Delete(c.ctx, user.ID), "Delete")
+	_, err = mfaRepo.FindByUserID(c.ctx, user.ID)
+	c.expectErr(err, domain.ErrMFANotFound, "FindByUserID after Delete")
+	c.must(mfaRepo.Delete(c.ctx, user.ID), "Delete of a user without settings")
+}
+
+func checkRecoveryCodes(c *checker) {
+	codes := c.repos.RecoveryCodes
+	user := c.newUser()
+	other := c.newUser()
+
+	c.must(codes.Replace(c.ctx, user.ID, []string{"hash_a", "hash_b", "hash_c"}), "Replace")
+	c.must(codes.Replace(c.ctx, other.ID, []string{"hash_a"}), "Replace for another user")
+	count, err := codes.CountUnused(c.ctx, user.ID)
+	c.must(err, "CountUnused")
+	c.equal(count, 3, "CountUnused")
+
+	// A code is used up once, and only by its own user
+	used, err := codes.Use(c.ctx, user.ID, "hash_b", time.Now())
+	c.must(err, "Use")
+	c.equal(used, true, "Use of an unused code")
+	used, err = codes.Use(c.ctx, user.ID, "hash_b", time.Now())
+	c.must(err, "Use again")
+	c.equal(used, false, "Use of a used code")
+	used, err = codes.Use(c.ctx, user.ID, "hash_missing", time.Now())
+	c.must(err, "Use of an unknown code")
+	c.equal(used, false, "Use of an unknown code")
+	used, err = codes.Use(c.ctx, other.ID, "hash_b", time.Now())
+	c.must(err, "Use of another user's code")
+	c.equal(used, false, "Use of another user's code")
+	count, err = codes.CountUnused(c.ctx, user.ID)
+	c.must(err, "CountUnused after Use")
+	c.equal(count, 2, "CountUnused after Use")
+
+	c.must(codes.Replace(c.ctx, user.ID, []string{"hash_d", "hash_b"}), "Replace again")
+	count, err = codes.CountUnused(c.ctx, user.ID)
+	c.must(err, "CountUnused after Replace again")
+	c.equal(count, 2, "CountUnused after Replace again")
+	used, err = codes.Use(c.ctx, user.ID, "hash_a", time.Now())
+	c.must(err, "Use of a replaced code")
+	c.equal(used, false, "Use of a replaced code")
+	used, err = codes.Use(c.ctx, user.ID, "hash_b", time.Now())
+	c.must(err, "Use of a code issued again")
+	c.equal(used, true, "Use of a code issued again")
+
+	c.must(codes.DeleteAll(c.ctx, user.ID), "DeleteAll")
+	count, err = codes.CountUnused(c.ctx, user.ID)
+	c.must(err, "CountUnused after DeleteAll")
+	c.equal(count, 0, "CountUnused after DeleteAll")
+	count, err = codes.CountUnused(c.ctx, other.ID)
+	c.must(err, "CountUnused of another user after DeleteAll")
+	c.equal(count, 1, "CountUnused of another user after DeleteAll")
+}
@@ -0,0 +1,87 @@
+package conformance
+
+import (
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func checkUsers(c *checker) {
+	users := c.repos.Users
+	user := c.newUser()
+	if user.ID == 0 {
+		c.fatalf("Create did not set the user ID")
+	}
+
+	found, err := users.FindByEmail(c.ctx, user.Email)
+	c.must(err, "FindByEmail")
+	c.equal(found.ID, user.ID, "FindByEmail ID")
+	if bcrypt.CompareHashAndPassword([]byte(found.PasswordHash), []byte(userPassword)) != nil {
+		c.errorf("the stored password hash does not match the password")
+	}
+
+	found, err = users.FindByID(c.ctx, user.ID)
+	c.must(err, "FindByID")
+	c.equal(found.Username, user.Username, "FindByID username")
+	c.equal(found.Email, user.Email, "FindByID email")
+
+	_, err = users.FindByEmail(c.ctx, "missing_"+unique()+"@example.com")
+	c.expectErr(err, domain.ErrUserNotFound, "FindByEmail of an unknown email")
+	_, err = users.FindByID(c.ctx, -1)
+	c.expectErr(err, domain.ErrUserNotFound, "FindByID of an unknown ID")
+
+	sameEmail := &domain.User{Username: "cf_" + unique(), Email: user.Email}
+	c.expectErr(users.Create(c.ctx, sameEmail, userPassword), domain.ErrConflict, "Create with a taken email")
+	sameName := &domain.User{Username: user.Username, Email: "cf_" + unique() + "@example.com"}
+	c.expectErr(users.Create(c.ctx, sameName, userPassword), domain.ErrConflict, "Create with a taken username")
+
+	c.must(users.UpdateTimezone(c.ctx, user.ID, "Asia/Bangkok"), "UpdateTimezone")
+	found, err = users.FindByID(c.ctx, user.ID)
+	c.must(err, "FindByID after UpdateTimezone")
+	c.equal(found.Timezone, "Asia/Bangkok", "timezone")
+	c.expectErr(users.UpdateTimezone(c.ctx, -1, "UTC"), domain.ErrUserNotFound, "UpdateTimezone of an unknown user")
+
+	c.must(users.UpdatePassword(c.ctx, user.ID, "n3w password"), "UpdatePassword")
+	found, err = users.FindByID(c.ctx, user.ID)
+	c.must(err, "FindByID after UpdatePassword")
+	if bcrypt.CompareHashAndPassword([]byte(found.PasswordHash), []byte("n3w password")) != nil {
+		c.errorf("the stored password hash does not match the new password")
+	}
+	c.expectErr(users.UpdatePassword(c.ctx, -1, "n3w password"), domain.ErrUserNotFound, "UpdatePassword of an unknown user")
+
+	// Email verification is recorded once
+	c.equalTime(found.EmailVerifiedAt, nil, "email verified at of a new user")
+	verifiedAt := time.Now()
+	c.must(users.MarkEmailVerified(c.ctx, user.ID, verifiedAt), "MarkEmailVerified")
+	c.must(users.MarkEmailVerified(c.ctx, user.ID, verifiedAt.Add(time.Minute)), "MarkEmailVerified again")
+	found, err = users.FindByEmail(c.ctx, user.Email)
+	c.must(err, "FindByEmail after MarkEmailVerified")
+	c.equalTime(found.EmailVerifiedAt, &verifiedAt, "email verified at")
+	c.expectErr(users.MarkEmailVerified(c.ctx, -1, verifiedAt), domain.ErrUserNotFound, "MarkEmailVerified of an unknown user")
+
+	// OAuth users have no password
+	suffix := unique()
+	oauthUser := &domain.User{
+		Username:        "cf_" + suffix,
+		Email:           "cf_" + suffix + "@example.com",
+		OAuthProvider:   "google",
+		OAuthProviderID: "g_" + suffix,
+		EmailVerifiedAt: &verifiedAt,
+	}
+	c.must(users.Create(c.ctx, oauthUser, ""), "Create an OAuth user")
+	found, err = users.FindByOAuthID(c.ctx, "google", "g_"+suffix)
+	c.must(err, "FindByOAuthID")
+	c.equalTime(found.EmailVerifiedAt, &verifiedAt, "email verified at set on Create")
+	c.equal(found.ID, oauthUser.ID, "FindByOAuthID ID")
+	c.equal(found.PasswordHash, "", "password hash of an OAuth user")
+	_, err = users.FindByOAuthID(c.ctx, "google", "missing_"+suffix)
+	c.expectErr(err, domain.ErrUserNotFound, "FindByOAuthID of an unknown ID")
+
+	user.OAuthProvider = "github"
+	user.OAuthProviderID = "gh_" + suffix
+	c.must(users.UpdateOAuthInfo(c.ctx, user), "UpdateOAuthInfo")
+	found, err = users.FindByOAuthID(c.ctx, "github", "gh_"+suffix)
+	c.must(err, "FindByOAuthID after UpdateOAuthInfo")
+	c.equal(found.ID, user.ID, "linked user ID")
+}
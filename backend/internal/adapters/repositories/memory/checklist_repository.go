@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+type checklistRepository struct {
+	store *Store
+}
+
+func NewChecklistRepository(store *Store) *checklistRepository {
+	return &checklistRepository{store: store}
+}
+
+func (r *checklistRepository) FindByTodoID(ctx context.Context, todoID int) ([]domain.ChecklistItem, error) {
+	items := make([]domain.ChecklistItem, 0)
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, item := range t.checklist {
+			if item.TodoID == todoID {
+				items = append(items, item)
+			}
+		}
+		return nil
+	})
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Position != items[j].Position {
+			return items[i].Position < items[j].Position
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items, err
+}
+
+func (r *checklistRepository) FindByID(ctx context.Context, id int) (*domain.ChecklistItem, error) {
+	var item domain.ChecklistItem
+	err := r.store.read(ctx, func(t *tables) error {
+		var ok bool
+		if item, ok = t.checklist[id]; !ok {
+			return domain.ErrChecklistItemNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *checklistRepository) Progress(ctx context.Context, todoIDs []int) (map[int]domain.ChecklistProgress, error) {
+	progress := make(map[int]domain.ChecklistProgress, len(todoIDs))
+	wanted := make(map[int]bool, len(todoIDs))
+	for _, id := range todoIDs {
+		wanted[id] = true
+	}
+
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, item := range t.checklist {
+			if !wanted[item.TodoID] {
+				continue
+			}
+			p := progress[item.TodoID]
+			p.Total++
+			if item.Done {
+				p.Done++
+			}
+			progress[item.TodoID] = p
+		}
+		return nil
+	})
+	return progress, err
+}
+
+func (r *checklistRepository) Create(ctx context.Context, item *domain.ChecklistItem) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.todos[item.TodoID]; !ok {
+			return fmt.Errorf("failed to add checklist item: todo %d does not exist", item.TodoID)
+		}
+
+		item.Position = 0
+		for _, other := range t.checklist {
+			if other.TodoID == item.TodoID && other.Position >= item.Position {
+				item.Position = other.Position + 1
+			}
+		}
+
+		now := time.Now()
+		item.ID = t.nextID()
+		item.CreatedAt = now
+		item.UpdatedAt = now
+		t.checklist[item.ID] = *item
+		return nil
+	})
+}
+
+func (r *checklistRepository) Update(ctx context.Context, item *domain.ChecklistItem) error {
+	return r.store.write(ctx, func(t *tables) error {
+		stored, ok := t.checklist[item.ID]
+		if !ok {
+			return domain.ErrChecklistItemNotFound
+		}
+		item.UpdatedAt = time.Now()
+		stored.Text = item.Text
+		stored.Done = item.Done
+		stored.UpdatedAt = item.UpdatedAt
+		t.checklist[item.ID] = stored
+		return nil
+	})
+}
+
+func (r *checklistRepository) Delete(ctx context.Context, id int) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.checklist[id]; !ok {
+			return domain.ErrChecklistItemNotFound
+		}
+		delete(t.checklist, id)
+		return nil
+	})
+}
+
+// Reorder skips IDs that are not items of the todo, like the SQL adapters.
+func (r *checklistRepository) Reorder(ctx context.Context, todoID int, itemIDs []int) error {
+	return r.store.write(ctx, func(t *tables) error {
+		now := time.Now()
+		for position, id := range itemIDs {
+			item, ok := t.checklist[id]
+			if !ok || item.TodoID != todoID {
+				continue
+			}
+			item.Position = position
+			item.UpdatedAt = now
+			t.checklist[id] = item
+		}
+		return nil
+	})
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+)
+
+type imageRepository struct {
+	store *Store
+}
+
+func NewImageRepository(store *Store) ports.ImageRepository {
+	return &imageRepository{store: store}
+}
+
+func (r *imageRepository) Save(ctx context.Context, file multipart.File, filename string) (string, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var id int
+	err = r.store.write(ctx, func(t *tables) error {
+		id = t.nextID()
+		t.images[id] = image{
+			filename:    filename,
+			data:        data,
+			contentType: http.DetectContentType(data),
+			createdAt:   time.Now(),
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(id), nil
+}
+
+func (r *imageRepository) Delete(ctx context.Context, imageID string) error {
+	id, err := strconv.Atoi(imageID)
+	if err != nil {
+		return domain.ErrImageNotFound
+	}
+
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.images[id]; !ok {
+			return domain.ErrImageNotFound
+		}
+		delete(t.images, id)
+		return nil
+	})
+}
+
+func (r *imageRepository) Get(ctx context.Context, imageID string) ([]byte, string, error) {
+	id, err := strconv.Atoi(imageID)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid image ID: %w", err)
+	}
+
+	var img image
+	err = r.store.read(ctx, func(t *tables) error {
+		var ok bool
+		if img, ok = t.images[id]; !ok {
+			return domain.ErrImageNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	// Stored bytes are never changed, but the caller may change its copy
+	return append([]byte(nil), img.data...), img.contentType, nil
+}
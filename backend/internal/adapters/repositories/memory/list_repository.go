@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+type listRepository struct {
+	store *Store
+}
+
+func NewListRepository(store *Store) *listRepository {
+	return &listRepository{store: store}
+}
+
+func (r *listRepository) FindAll(ctx context.Context, userID int) ([]domain.List, error) {
+	lists := make([]domain.List, 0)
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, list := range t.lists {
+			if list.UserID == userID {
+				lists = append(lists, list)
+			}
+		}
+		return nil
+	})
+	// The inbox comes first, then the rest by name
+	sort.Slice(lists, func(i, j int) bool {
+		if lists[i].IsInbox != lists[j].IsInbox {
+			return lists[i].IsInbox
+		}
+		return lists[i].Name < lists[j].Name
+	})
+	return lists, err
+}
+
+func (r *listRepository) FindByID(ctx context.Context, id int) (*domain.List, error) {
+	return r.findOne(ctx, func(l *domain.List) bool { return l.ID == id })
+}
+
+func (r *listRepository) FindInbox(ctx context.Context, userID int) (*domain.List, error) {
+	return r.findOne(ctx, func(l *domain.List) bool { return l.UserID == userID && l.IsInbox })
+}
+
+func (r *listRepository) findOne(ctx context.Context, match func(l *domain.List) bool) (*domain.List, error) {
+	var found *domain.List
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, list := range t.lists {
+			if match(&list) {
+				found = &list
+				return nil
+			}
+		}
+		return domain.ErrListNotFound
+	})
+	return found, err
+}
+
+func (r *listRepository) Create(ctx context.Context, list *domain.List) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if list.IsInbox {
+			for _, existing := range t.lists {
+				if existing.UserID == list.UserID && existing.IsInbox {
+					return domain.Conflict("inbox_exists", "inbox already exists")
+				}
+			}
+		}
+
+		now := time.Now()
+		list.ID = t.nextID()
+		list.CreatedAt = now
+		list.UpdatedAt = now
+		t.lists[list.ID] = *list
+		return nil
+	})
+}
+
+func (r *listRepository) Update(ctx context.Context, list *domain.List) error {
+	return r.store.write(ctx, func(t *tables) error {
+		stored, ok := t.lists[list.ID]
+		if !ok {
+			return domain.ErrListNotFound
+		}
+		list.UpdatedAt = time.Now()
+		stored.Name = list.Name
+		stored.UpdatedAt = list.UpdatedAt
+		t.lists[list.ID] = stored
+		return nil
+	})
+}
+
+// Delete removes the list together with its todos and workflow.
+func (r *listRepository) Delete(ctx context.Context, id int) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.lists[id]; !ok {
+			return domain.ErrListNotFound
+		}
+		delete(t.lists, id)
+		for todoID, todo := range t.todos {
+			if todo.ListID == id {
+				t.deleteTodo(todoID)
+			}
+		}
+		for workflowID, workflow := range t.workflows {
+			if workflow.ListID == id {
+				delete(t.workflows, workflowID)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *listRepository) MoveTodos(ctx context.Context, fromListID, toListID int) error {
+	return r.store.write(ctx, func(t *tables) error {
+		changes, err := domain.DiffTodos(&domain.Todo{ListID: fromListID}, &domain.Todo{ListID: toListID})
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for id, todo := range t.todos {
+			if todo.ListID != fromListID {
+				continue
+			}
+			todo.ListID = toListID
+			todo.UpdatedAt = now
+			todo.Version++
+			t.todos[id] = todo
+			t.addRevision(ctx, domain.RevisionUpdate, &todo, changes)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,168 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+type mfaRepository struct {
+	store *Store
+}
+
+func NewMFARepository(store *Store) *mfaRepository {
+	return &mfaRepository{store: store}
+}
+
+func (r *mfaRepository) Save(ctx context.Context, mfa *domain.MFA) error {
+	return r.store.write(ctx, func(t *tables) error {
+		mfa.CreatedAt = time.Now()
+		t.mfa[mfa.UserID] = *mfa
+		return nil
+	})
+}
+
+func (r *mfaRepository) FindByUserID(ctx context.Context, userID int) (*domain.MFA, error) {
+	var found domain.MFA
+	err := r.store.read(ctx, func(t *tables) error {
+		mfa, ok := t.mfa[userID]
+		if !ok {
+			return domain.ErrMFANotFound
+		}
+		found = mfa
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &found, nil
+}
+
+func (r *mfaRepository) Enable(ctx context.Context, userID int, at time.Time) error {
+	return r.update(ctx, userID, func(mfa *domain.MFA) {
+		mfa.EnabledAt = &at
+	})
+}
+
+func (r *mfaRepository) UseStep(ctx context.Context, userID int, step int64) (bool, error) {
+	used := false
+	err := r.store.write(ctx, func(t *tables) error {
+		mfa, ok := t.mfa[userID]
+		if !ok || mfa.LastUsedStep >= step {
+			return nil
+		}
+		mfa.LastUsedStep = step
+		t.mfa[userID] = mfa
+		used = true
+		return nil
+	})
+	return used, err
+}
+
+func (r *mfaRepository) AddFailure(ctx context.Context, userID int) (int, error) {
+	failures := 0
+	err := r.update(ctx, userID, func(mfa *domain.MFA) {
+		mfa.FailedAttempts++
+		failures = mfa.FailedAttempts
+	})
+	return failures, err
+}
+
+func (r *mfaRepository) Lock(ctx context.Context, userID int, until time.Time) error {
+	return r.update(ctx, userID, func(mfa *domain.MFA) {
+		mfa.LockedUntil = &until
+		mfa.FailedAttempts = 0
+	})
+}
+
+func (r *mfaRepository) ClearFailures(ctx context.Context, userID int) error {
+	return r.update(ctx, userID, func(mfa *domain.MFA) {
+		mfa.FailedAttempts = 0
+	})
+}
+
+func (r *mfaRepository) Delete(ctx context.Context, userID int) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.mfa, userID)
+		return nil
+	})
+}
+
+// update changes the user's settings with change, or returns
+// domain.ErrMFANotFound if there are none.
+func (r *mfaRepository) update(ctx context.Context, userID int, change func(mfa *domain.MFA)) error {
+	return r.store.write(ctx, func(t *tables) error {
+		mfa, ok := t.mfa[userID]
+		if !ok {
+			return domain.ErrMFANotFound
+		}
+		change(&mfa)
+		t.mfa[userID] = mfa
+		return nil
+	})
+}
+
+type recoveryCodeRepository struct {
+	store *Store
+}
+
+func NewRecoveryCodeRepository(store *Store) *recoveryCodeRepository {
+	return &recoveryCodeRepository{store: store}
+}
+
+func (r *recoveryCodeRepository) Replace(ctx context.Context, userID int, hashes []string) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for id, code := range t.recoveryCodes {
+			if code.UserID == userID {
+				delete(t.recoveryCodes, id)
+			}
+		}
+		now := time.Now()
+		for _, hash := range hashes {
+			id := t.nextID()
+			t.recoveryCodes[id] = domain.RecoveryCode{ID: id, UserID: userID, CodeHash: hash, CreatedAt: now}
+		}
+		return nil
+	})
+}
+
+func (r *recoveryCodeRepository) Use(ctx context.Context, userID int, hash string, at time.Time) (bool, error) {
+	used := false
+	err := r.store.write(ctx, func(t *tables) error {
+		for id, code := range t.recoveryCodes {
+			if code.UserID == userID && code.CodeHash == hash && code.UsedAt == nil {
+				code.UsedAt = &at
+				t.recoveryCodes[id] = code
+				used = true
+				return nil
+			}
+		}
+		return nil
+	})
+	return used, err
+}
+
+func (r *recoveryCodeRepository) CountUnused(ctx context.Context, userID int) (int, error) {
+	count := 0
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, code := range t.recoveryCodes {
+			if code.UserID == userID && code.UsedAt == nil {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+func (r *recoveryCodeRepository) DeleteAll(ctx context.Context, userID int) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for id, code := range t.recoveryCodes {
+			if code.UserID == userID {
+				delete(t.recoveryCodes, id)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+type oneTimeTokenRepository struct {
+	store *Store
+}
+
+func NewOneTimeTokenRepository(store *Store) *oneTimeTokenRepository {
+	return &oneTimeTokenRepository{store: store}
+}
+
+func (r *oneTimeTokenRepository) Create(ctx context.Context, token *domain.OneTimeToken) error {
+	return r.store.write(ctx, func(t *tables) error {
+		token.ID = t.nextID()
+		token.CreatedAt = time.Now()
+		t.oneTimeTokens[token.ID] = *token
+		return nil
+	})
+}
+
+func (r *oneTimeTokenRepository) FindByHash(ctx context.Context, purpose, hash string) (*domain.OneTimeToken, error) {
+	var found *domain.OneTimeToken
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, token := range t.oneTimeTokens {
+			if token.TokenHash == hash && token.Purpose == purpose {
+				found = &token
+				return nil
+			}
+		}
+		return domain.ErrOneTimeTokenNotFound
+	})
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+func (r *oneTimeTokenRepository) FindLatest(ctx context.Context, userID int, purpose string) (*domain.OneTimeToken, error) {
+	var found *domain.OneTimeToken
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, token := range t.oneTimeTokens {
+			if token.UserID != userID || token.Purpose != purpose {
+				continue
+			}
+			if found == nil || token.CreatedAt.After(found.CreatedAt) ||
+				(token.CreatedAt.Equal(found.CreatedAt) && token.ID > found.ID) {
+				latest := token
+				found = &latest
+			}
+		}
+		if found == nil {
+			return domain.ErrOneTimeTokenNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+func (r *oneTimeTokenRepository) Consume(ctx context.Context, id int, at time.Time) (bool, error) {
+	consumed := false
+	err := r.store.write(ctx, func(t *tables) error {
+		token, ok := t.oneTimeTokens[id]
+		if !ok || token.UsedAt != nil {
+			return nil
+		}
+		token.UsedAt = &at
+		t.oneTimeTokens[id] = token
+		consumed = true
+		return nil
+	})
+	return consumed, err
+}
+
+func (r *oneTimeTokenRepository) ConsumeAll(ctx context.Context, userID int, purpose string, at time.Time) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for id, token := range t.oneTimeTokens {
+			if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+				token.UsedAt = &at
+				t.oneTimeTokens[id] = token
+			}
+		}
+		return nil
+	})
+}
+
+func (r *oneTimeTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	deleted := 0
+	err := r.store.write(ctx, func(t *tables) error {
+		for id, token := range t.oneTimeTokens {
+			if token.ExpiresAt.Before(before) {
+				delete(t.oneTimeTokens, id)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+type sessionRepository struct {
+	store *Store
+}
+
+func NewSessionRepository(store *Store) *sessionRepository {
+	return &sessionRepository{store: store}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	return r.store.write(ctx, func(t *tables) error {
+		t.sessions[session.ID] = *session
+		return nil
+	})
+}
+
+func (r *sessionRepository) FindByID(ctx context.Context, id string) (*domain.Session, error) {
+	var session domain.Session
+	err := r.store.read(ctx, func(t *tables) error {
+		var ok bool
+		if session, ok = t.sessions[id]; !ok {
+			return domain.ErrSessionNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) FindActive(ctx context.Context, userID int, now time.Time) ([]domain.Session, error) {
+	sessions := make([]domain.Session, 0)
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, session := range t.sessions {
+			if session.UserID == userID && session.RevokedAt == nil && session.ExpiresAt.After(now) {
+				sessions = append(sessions, session)
+			}
+		}
+		return nil
+	})
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].LastSeenAt.Equal(sessions[j].LastSeenAt) {
+			return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+	return sessions, err
+}
+
+func (r *sessionRepository) Extend(ctx context.Context, id string, seenAt, expiresAt time.Time) error {
+	return r.store.write(ctx, func(t *tables) error {
+		session, ok := t.sessions[id]
+		if !ok {
+			return domain.ErrSessionNotFound
+		}
+		if seenAt.After(session.LastSeenAt) {
+			session.LastSeenAt = seenAt
+		}
+		session.ExpiresAt = expiresAt
+		t.sessions[id] = session
+		return nil
+	})
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if session, ok := t.sessions[id]; ok && session.RevokedAt == nil {
+			session.RevokedAt = &at
+			t.sessions[id] = session
+		}
+		return nil
+	})
+}
+
+func (r *sessionRepository) Touch(ctx context.Context, lastSeen map[string]time.Time) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for id, at := range lastSeen {
+			if session, ok := t.sessions[id]; ok && at.After(session.LastSeenAt) {
+				session.LastSeenAt = at
+				t.sessions[id] = session
+			}
+		}
+		return nil
+	})
+}
+
+func (r *sessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	deleted := 0
+	err := r.store.write(ctx, func(t *tables) error {
+		for id, session := range t.sessions {
+			if session.ExpiresAt.Before(before) {
+				delete(t.sessions, id)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
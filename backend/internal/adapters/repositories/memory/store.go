@@ -0,0 +1,237 @@
+// Package memory implements the repository ports on plain Go maps. It keeps
+// nothing across restarts and is meant for tests and demos; the conformance
+// suite holds it to the same behaviour as the SQL adapters.
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+// Store holds the tables every repository of one memory database shares.
+// A single lock serializes all access: each repository call, or each
+// outermost WithinTx, holds it from start to end, so transactions are fully
+// isolated from each other.
+type Store struct {
+	mu   sync.Mutex
+	data *tables
+}
+
+// tables is the content of a Store. Rows are stored by value and copied in
+// and out, so callers never share memory with the store.
+type tables struct {
+	lastID int
+
+	users     map[int]domain.User
+	todos     map[int]domain.Todo
+	revisions []domain.TodoRevision
+	lists     map[int]domain.List
+	tags      map[int]domain.Tag
+	todoTags  map[int]map[int]bool // todo ID to tag IDs
+	checklist map[int]domain.ChecklistItem
+	workflows map[int]domain.Workflow
+	images    map[int]image
+
+	refreshTokens map[int]domain.RefreshToken
+	revokedTokens map[string]time.Time // token ID to expiry
+	sessions      map[string]domain.Session
+	oneTimeTokens map[int]domain.OneTimeToken
+	mfa           map[int]domain.MFA // user ID to settings
+	recoveryCodes map[int]domain.RecoveryCode
+}
+
+type image struct {
+	filename    string
+	data        []byte
+	contentType string
+	createdAt   time.Time
+}
+
+func NewStore() *Store {
+	return &Store{data: &tables{
+		users:     make(map[int]domain.User),
+		todos:     make(map[int]domain.Todo),
+		lists:     make(map[int]domain.List),
+		tags:      make(map[int]domain.Tag),
+		todoTags:  make(map[int]map[int]bool),
+		checklist: make(map[int]domain.ChecklistItem),
+		workflows: make(map[int]domain.Workflow),
+		images:    make(map[int]image),
+
+		refreshTokens: make(map[int]domain.RefreshToken),
+		revokedTokens: make(map[string]time.Time),
+		sessions:      make(map[string]domain.Session),
+		oneTimeTokens: make(map[int]domain.OneTimeToken),
+		mfa:           make(map[int]domain.MFA),
+		recoveryCodes: make(map[int]domain.RecoveryCode),
+	}}
+}
+
+// nextID hands out IDs from one sequence shared by every table.
+func (t *tables) nextID() int {
+	t.lastID++
+	return t.lastID
+}
+
+// clone returns a copy of t that can be changed without affecting t.
+// Stored rows are never modified in place, only replaced, so copying the
+// maps is enough.
+func (t *tables) clone() *tables {
+	c := &tables{
+		lastID:    t.lastID,
+		users:     make(map[int]domain.User, len(t.users)),
+		todos:     make(map[int]domain.Todo, len(t.todos)),
+		revisions: append([]domain.TodoRevision(nil), t.revisions...),
+		lists:     make(map[int]domain.List, len(t.lists)),
+		tags:      make(map[int]domain.Tag, len(t.tags)),
+		todoTags:  make(map[int]map[int]bool, len(t.todoTags)),
+		checklist: make(map[int]domain.ChecklistItem, len(t.checklist)),
+		workflows: make(map[int]domain.Workflow, len(t.workflows)),
+		images:    make(map[int]image, len(t.images)),
+
+		refreshTokens: make(map[int]domain.RefreshToken, len(t.refreshTokens)),
+		revokedTokens: make(map[string]time.Time, len(t.revokedTokens)),
+		sessions:      make(map[string]domain.Session, len(t.sessions)),
+		oneTimeTokens: make(map[int]domain.OneTimeToken, len(t.oneTimeTokens)),
+		mfa:           make(map[int]domain.MFA, len(t.mfa)),
+		recoveryCodes: make(map[int]domain.RecoveryCode, len(t.recoveryCodes)),
+	}
+	for id, row := range t.users {
+		c.users[id] = row
+	}
+	for id, row := range t.todos {
+		c.todos[id] = row
+	}
+	for id, row := range t.lists {
+		c.lists[id] = row
+	}
+	for id, row := range t.tags {
+		c.tags[id] = row
+	}
+	for id, tagIDs := range t.todoTags {
+		copied := make(map[int]bool, len(tagIDs))
+		for tagID := range tagIDs {
+			copied[tagID] = true
+		}
+		c.todoTags[id] = copied
+	}
+	for id, row := range t.checklist {
+		c.checklist[id] = row
+	}
+	for id, row := range t.workflows {
+		c.workflows[id] = row
+	}
+	for id, row := range t.images {
+		c.images[id] = row
+	}
+	for id, row := range t.refreshTokens {
+		c.refreshTokens[id] = row
+	}
+	for id, expiresAt := range t.revokedTokens {
+		c.revokedTokens[id] = expiresAt
+	}
+	for id, row := range t.sessions {
+		c.sessions[id] = row
+	}
+	for id, row := range t.oneTimeTokens {
+		c.oneTimeTokens[id] = row
+	}
+	for userID, row := range t.mfa {
+		c.mfa[userID] = row
+	}
+	for id, row := range t.recoveryCodes {
+		c.recoveryCodes[id] = row
+	}
+	return c
+}
+
+// txKey marks the transaction repositories should run in.
+type txKey struct{}
+
+type txState struct {
+	store *Store
+}
+
+// inTx reports whether ctx carries a transaction of this store, whose
+// owner already holds the lock.
+func (s *Store) inTx(ctx context.Context) bool {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	return ok && state.store == s
+}
+
+// read runs fn with the store's tables for a repository call that only
+// reads.
+func (s *Store) read(ctx context.Context, fn func(t *tables) error) error {
+	if !s.inTx(ctx) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	return fn(s.data)
+}
+
+// write runs fn with the store's tables for a repository call that
+// changes them. fn changes the tables in place, so it must do every check
+// that can fail before it changes anything; only WithinTx pays for a copy
+// it can throw away.
+func (s *Store) write(ctx context.Context, fn func(t *tables) error) error {
+	if !s.inTx(ctx) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	return fn(s.data)
+}
+
+// atomically runs fn on a copy of the tables and keeps the copy only if fn
+// succeeds. The caller holds the lock.
+func (s *Store) atomically(fn func(t *tables) error) error {
+	working := s.data.clone()
+	if err := fn(working); err != nil {
+		return err
+	}
+	s.data = working
+	return nil
+}
+
+// transactor runs work against a Store as one transaction.
+type transactor struct {
+	store *Store
+}
+
+func NewTransactor(store *Store) *transactor {
+	return &transactor{store: store}
+}
+
+// WithinTx runs fn with the store locked and keeps its changes only if fn
+// returns nil. A nested WithinTx keeps or undoes its own changes the same
+// way, like a savepoint.
+func (t *transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	s := t.store
+	if s.inTx(ctx) {
+		return s.atomically(func(working *tables) error {
+			return s.swap(working, func() error { return fn(ctx) })
+		})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	txCtx := context.WithValue(ctx, txKey{}, &txState{store: s})
+	return s.atomically(func(working *tables) error {
+		return s.swap(working, func() error { return fn(txCtx) })
+	})
+}
+
+// swap makes working the tables repository calls see while fn runs, and
+// hands back whatever they left in it. The store's own tables are put back
+// even if fn panics.
+func (s *Store) swap(working *tables, fn func() error) error {
+	saved := s.data
+	s.data = working
+	defer func() {
+		*working = *s.data
+		s.data = saved
+	}()
+	return fn()
+}
@@ -0,0 +1,175 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+type tagRepository struct {
+	store *Store
+}
+
+func NewTagRepository(store *Store) *tagRepository {
+	return &tagRepository{store: store}
+}
+
+func sortTags(tags []domain.Tag) {
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+}
+
+func (r *tagRepository) FindAll(ctx context.Context, userID int) ([]domain.Tag, error) {
+	return r.findTags(ctx, func(tag *domain.Tag) bool { return tag.UserID == userID })
+}
+
+func (r *tagRepository) FindByID(ctx context.Context, id int) (*domain.Tag, error) {
+	var tag domain.Tag
+	err := r.store.read(ctx, func(t *tables) error {
+		var ok bool
+		if tag, ok = t.tags[id]; !ok {
+			return domain.ErrTagNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) FindByNames(ctx context.Context, userID int, names []string) ([]domain.Tag, error) {
+	lowered := make(map[string]bool, len(names))
+	for _, name := range names {
+		lowered[strings.ToLower(name)] = true
+	}
+	return r.findTags(ctx, func(tag *domain.Tag) bool {
+		return tag.UserID == userID && lowered[strings.ToLower(tag.Name)]
+	})
+}
+
+func (r *tagRepository) findTags(ctx context.Context, match func(tag *domain.Tag) bool) ([]domain.Tag, error) {
+	tags := make([]domain.Tag, 0)
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, tag := range t.tags {
+			if match(&tag) {
+				tags = append(tags, tag)
+			}
+		}
+		return nil
+	})
+	sortTags(tags)
+	return tags, err
+}
+
+func (r *tagRepository) FindByTodoIDs(ctx context.Context, todoIDs []int) (map[int][]domain.Tag, error) {
+	result := make(map[int][]domain.Tag, len(todoIDs))
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, todoID := range todoIDs {
+			for tagID := range t.todoTags[todoID] {
+				result[todoID] = append(result[todoID], t.tags[tagID])
+			}
+		}
+		return nil
+	})
+	for _, tags := range result {
+		sortTags(tags)
+	}
+	return result, err
+}
+
+// nameTaken reports whether the user has a tag other than exceptID with
+// the name, ignoring case.
+func (t *tables) nameTaken(userID, exceptID int, name string) bool {
+	for _, tag := range t.tags {
+		if tag.UserID == userID && tag.ID != exceptID && strings.EqualFold(tag.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *tagRepository) Create(ctx context.Context, tag *domain.Tag) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if t.nameTaken(tag.UserID, 0, tag.Name) {
+			return domain.Conflict("tag_exists", fmt.Sprintf("tag %q already exists", tag.Name))
+		}
+		tag.ID = t.nextID()
+		tag.CreatedAt = time.Now()
+		t.tags[tag.ID] = *tag
+		return nil
+	})
+}
+
+// Update renames or recolours a tag. Todos reference tags by ID, so every
+// todo carrying the tag picks up the new name.
+func (r *tagRepository) Update(ctx context.Context, tag *domain.Tag) error {
+	return r.store.write(ctx, func(t *tables) error {
+		stored, ok := t.tags[tag.ID]
+		if !ok {
+			return domain.ErrTagNotFound
+		}
+		if t.nameTaken(stored.UserID, stored.ID, tag.Name) {
+			return domain.Conflict("tag_exists", fmt.Sprintf("tag %q already exists", tag.Name))
+		}
+		stored.Name = tag.Name
+		stored.Color = tag.Color
+		t.tags[tag.ID] = stored
+		return nil
+	})
+}
+
+func (r *tagRepository) Delete(ctx context.Context, id int) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.tags[id]; !ok {
+			return domain.ErrTagNotFound
+		}
+		t.deleteTag(id)
+		return nil
+	})
+}
+
+// deleteTag removes a tag and takes it off every todo.
+func (t *tables) deleteTag(id int) {
+	delete(t.tags, id)
+	for _, tagIDs := range t.todoTags {
+		delete(tagIDs, id)
+	}
+}
+
+func (r *tagRepository) Merge(ctx context.Context, targetID int, sourceIDs []int) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for _, tagIDs := range t.todoTags {
+			for _, sourceID := range sourceIDs {
+				if tagIDs[sourceID] {
+					tagIDs[targetID] = true
+				}
+			}
+		}
+		for _, sourceID := range sourceIDs {
+			t.deleteTag(sourceID)
+		}
+		return nil
+	})
+}
+
+func (r *tagRepository) SetTodoTags(ctx context.Context, todoID int, tagIDs []int) error {
+	return r.store.write(ctx, func(t *tables) error {
+		// Enforce what the foreign keys of todo_tags do
+		if _, ok := t.todos[todoID]; !ok && len(tagIDs) > 0 {
+			return fmt.Errorf("failed to tag todo: todo %d does not exist", todoID)
+		}
+		set := make(map[int]bool, len(tagIDs))
+		for _, tagID := range tagIDs {
+			if _, ok := t.tags[tagID]; !ok {
+				return fmt.Errorf("failed to tag todo: tag %d does not exist", tagID)
+			}
+			set[tagID] = true
+		}
+		t.todoTags[todoID] = set
+		return nil
+	})
+}
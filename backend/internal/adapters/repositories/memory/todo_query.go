@@ -0,0 +1,263 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+// todoSort describes how to order and page by one sort field. A cursor
+// value is parsed back into a todo that compare can weigh against others.
+type todoSort struct {
+	compare func(a, b *domain.Todo) int
+	value   func(todo *domain.Todo) string
+	parse   func(value string) (*domain.Todo, error)
+}
+
+// timeSort sorts by a time field. Todos without one sort as if it were
+// nilValue: after every time when "infinity", before when "-infinity".
+func timeSort(field func(t *domain.Todo) **time.Time, nilValue string) todoSort {
+	nilFirst := nilValue == "-infinity"
+	return todoSort{
+		compare: func(a, b *domain.Todo) int {
+			ta, tb := *field(a), *field(b)
+			switch {
+			case ta == nil && tb == nil:
+				return 0
+			case ta == nil:
+				return boolCompare(!nilFirst)
+			case tb == nil:
+				return -boolCompare(!nilFirst)
+			}
+			return timeCompare(*ta, *tb)
+		},
+		value: func(t *domain.Todo) string {
+			if p := *field(t); p != nil {
+				return p.UTC().Format(time.RFC3339Nano)
+			}
+			return nilValue
+		},
+		parse: func(value string) (*domain.Todo, error) {
+			var todo domain.Todo
+			if value == nilValue {
+				return &todo, nil
+			}
+			parsed, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return nil, err
+			}
+			*field(&todo) = &parsed
+			return &todo, nil
+		},
+	}
+}
+
+// requiredTimeSort sorts by a time field every todo has.
+func requiredTimeSort(field func(t *domain.Todo) *time.Time) todoSort {
+	s := timeSort(func(t *domain.Todo) **time.Time {
+		p := field(t)
+		return &p
+	}, "")
+	s.parse = func(value string) (*domain.Todo, error) {
+		parsed, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, err
+		}
+		var todo domain.Todo
+		*field(&todo) = parsed
+		return &todo, nil
+	}
+	return s
+}
+
+var todoSorts = map[string]todoSort{
+	domain.SortCreatedAt: requiredTimeSort(func(t *domain.Todo) *time.Time { return &t.CreatedAt }),
+	domain.SortUpdatedAt: requiredTimeSort(func(t *domain.Todo) *time.Time { return &t.UpdatedAt }),
+	domain.SortDueAt:     timeSort(func(t *domain.Todo) **time.Time { return &t.DueAt }, "infinity"),
+	domain.SortArchived:  timeSort(func(t *domain.Todo) **time.Time { return &t.ArchivedAt }, "-infinity"),
+	domain.SortTitle: {
+		// Close to the database's default collation, which mostly
+		// ignores case
+		compare: func(a, b *domain.Todo) int {
+			if c := strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title)); c != 0 {
+				return c
+			}
+			return strings.Compare(a.Title, b.Title)
+		},
+		value: func(t *domain.Todo) string { return t.Title },
+		parse: func(value string) (*domain.Todo, error) { return &domain.Todo{Title: value}, nil },
+	},
+	domain.SortPosition: {
+		// Ranks compare byte by byte, as position's COLLATE "C" does
+		compare: func(a, b *domain.Todo) int { return strings.Compare(a.Position, b.Position) },
+		value:   func(t *domain.Todo) string { return t.Position },
+		parse:   func(value string) (*domain.Todo, error) { return &domain.Todo{Position: value}, nil },
+	},
+	domain.SortPriority: {
+		compare: func(a, b *domain.Todo) int { return priorityLevel(a.Priority) - priorityLevel(b.Priority) },
+		value:   func(t *domain.Todo) string { return strconv.Itoa(priorityLevel(t.Priority)) },
+		parse: func(value string) (*domain.Todo, error) {
+			level, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, err
+			}
+			return &domain.Todo{Priority: domain.PriorityName(level)}, nil
+		},
+	},
+}
+
+func timeCompare(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+func boolCompare(greater bool) int {
+	if greater {
+		return 1
+	}
+	return -1
+}
+
+// findTodos answers a normalized TodoQuery the way the SQL adapters do:
+// filter, order by the sort field and then ID in the same direction, and
+// continue strictly after the cursor.
+func findTodos(t *tables, q domain.TodoQuery) (*domain.TodoPage, error) {
+	sorter, ok := todoSorts[q.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort %q", q.Sort)
+	}
+
+	// direction is 1 when ascending and -1 when descending, so that
+	// direction*compare > 0 means "comes after"
+	direction := -1
+	if q.Order == domain.SortAsc {
+		direction = 1
+	}
+	compare := func(a, b *domain.Todo) int {
+		if c := sorter.compare(a, b); c != 0 {
+			return direction * c
+		}
+		return direction * (a.ID - b.ID)
+	}
+
+	var after *domain.Todo
+	if q.Cursor != "" {
+		cursor, err := domain.DecodeTodoCursor(q.Cursor, q)
+		if err != nil {
+			return nil, err
+		}
+		if after, err = sorter.parse(cursor.Value); err != nil {
+			return nil, domain.ErrInvalidCursor
+		}
+		after.ID = cursor.ID
+	}
+
+	match := todoMatcher(t, q)
+	todos := make([]domain.Todo, 0)
+	for _, todo := range t.todos {
+		if match(&todo) && (after == nil || compare(&todo, after) > 0) {
+			todos = append(todos, copyTimes(todo))
+		}
+	}
+	sort.Slice(todos, func(i, j int) bool { return compare(&todos[i], &todos[j]) < 0 })
+
+	page := &domain.TodoPage{Todos: todos}
+	if q.Limit > 0 && len(todos) > q.Limit {
+		page.Todos = todos[:q.Limit]
+		last := &page.Todos[q.Limit-1]
+		page.NextCursor = domain.TodoCursor{
+			Sort:  q.Sort,
+			Order: q.Order,
+			Value: sorter.value(last),
+			ID:    last.ID,
+		}.Encode()
+	}
+	return page, nil
+}
+
+// todoMatcher returns the filter of q, everything but the cursor.
+func todoMatcher(t *tables, q domain.TodoQuery) func(todo *domain.Todo) bool {
+	text := strings.ToLower(q.Text)
+
+	statuses := make(map[string]bool, len(q.Statuses))
+	for _, status := range q.Statuses {
+		statuses[status] = true
+	}
+
+	// Tag filters name tags case-insensitively; resolve them to IDs of the
+	// user's tags once
+	tagNames := make(map[string]bool)
+	for _, name := range q.Tags {
+		tagNames[strings.ToLower(name)] = true
+	}
+	tagIDs := make(map[int]bool)
+	for _, tag := range t.tags {
+		if tag.UserID == q.UserID && tagNames[strings.ToLower(tag.Name)] {
+			tagIDs[tag.ID] = true
+		}
+	}
+
+	loc := q.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return func(todo *domain.Todo) bool {
+		switch {
+		case todo.UserID != q.UserID,
+			q.Trashed != (todo.DeletedAt != nil),
+			q.Archived && todo.ArchivedAt == nil,
+			!q.Archived && !q.IncludeArchived && todo.ArchivedAt != nil,
+			q.ListID != 0 && todo.ListID != q.ListID,
+			len(statuses) > 0 && !statuses[todo.Status],
+			text != "" && !strings.Contains(strings.ToLower(todo.Title), text) &&
+				!strings.Contains(strings.ToLower(todo.Description), text),
+			q.CreatedAfter != nil && !todo.CreatedAt.After(*q.CreatedAfter),
+			q.CreatedBefore != nil && !todo.CreatedAt.Before(*q.CreatedBefore),
+			q.UpdatedAfter != nil && !todo.UpdatedAt.After(*q.UpdatedAfter),
+			q.UpdatedBefore != nil && !todo.UpdatedAt.Before(*q.UpdatedBefore),
+			q.DueAfter != nil && (todo.DueAt == nil || !todo.DueAt.After(*q.DueAfter)),
+			q.DueBefore != nil && (todo.DueAt == nil || !todo.DueAt.Before(*q.DueBefore)),
+			q.Overdue && !isOverdue(todo, q.Now, loc):
+			return false
+		}
+
+		if len(tagNames) > 0 {
+			carried := 0
+			for tagID := range t.todoTags[todo.ID] {
+				if tagIDs[tagID] {
+					carried++
+				}
+			}
+			// Tag names are unique per user, so carrying every named tag
+			// means carrying as many tags as there are names
+			if carried == 0 || (q.TagMatch == domain.TagMatchAll && carried < len(tagNames)) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// isOverdue mirrors todoService.isOverdue: a due date at midnight in the
+// user's zone covers the whole day.
+func isOverdue(todo *domain.Todo, now time.Time, loc *time.Location) bool {
+	if todo.DueAt == nil || todo.CompletedAt != nil {
+		return false
+	}
+
+	deadline := todo.DueAt.In(loc)
+	if deadline.Equal(time.Date(deadline.Year(), deadline.Month(), deadline.Day(), 0, 0, 0, 0, loc)) {
+		deadline = deadline.AddDate(0, 0, 1)
+	}
+	return !now.Before(deadline)
+}
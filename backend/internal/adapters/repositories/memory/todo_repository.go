@@ -0,0 +1,220 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+type todoRepository struct {
+	store *Store
+}
+
+func NewTodoRepository(store *Store) *todoRepository {
+	return &todoRepository{store: store}
+}
+
+// storedTodo is the row kept for todo: only what the todos table holds,
+// with the priority normalized the way the SQL adapters store it.
+func storedTodo(todo *domain.Todo) domain.Todo {
+	row := *todo
+	row.Priority = domain.PriorityName(priorityLevel(todo.Priority))
+	row.Overdue = false
+	row.Tags = nil
+	row.Checklist = nil
+	row.Progress = domain.ChecklistProgress{}
+	return copyTimes(row)
+}
+
+// copyTimes gives todo its own copies of its optional times, which would
+// otherwise be shared between the store and its callers.
+func copyTimes(todo domain.Todo) domain.Todo {
+	for _, p := range []**time.Time{&todo.StartAt, &todo.DueAt, &todo.CompletedAt, &todo.ArchivedAt, &todo.DeletedAt} {
+		if *p != nil {
+			copied := **p
+			*p = &copied
+		}
+	}
+	return todo
+}
+
+func priorityLevel(priority string) int {
+	level, _ := domain.PriorityLevel(priority)
+	return level
+}
+
+func (r *todoRepository) FindAll(ctx context.Context, q domain.TodoQuery) (*domain.TodoPage, error) {
+	var page *domain.TodoPage
+	err := r.store.read(ctx, func(t *tables) error {
+		var err error
+		page, err = findTodos(t, q)
+		return err
+	})
+	return page, err
+}
+
+func (r *todoRepository) FindByID(ctx context.Context, id int) (*domain.Todo, error) {
+	var todo domain.Todo
+	err := r.store.read(ctx, func(t *tables) error {
+		var ok bool
+		if todo, ok = t.todos[id]; !ok {
+			return domain.ErrTodoNotFound
+		}
+		todo = copyTimes(todo)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &todo, nil
+}
+
+func (r *todoRepository) FindSeries(ctx context.Context, seriesID int) ([]domain.Todo, error) {
+	todos := make([]domain.Todo, 0)
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, todo := range t.todos {
+			if todo.ID == seriesID || todo.SeriesID == seriesID {
+				todos = append(todos, copyTimes(todo))
+			}
+		}
+		return nil
+	})
+	sort.Slice(todos, func(i, j int) bool {
+		if todos[i].RecurrenceIndex != todos[j].RecurrenceIndex {
+			return todos[i].RecurrenceIndex < todos[j].RecurrenceIndex
+		}
+		return todos[i].ID < todos[j].ID
+	})
+	return todos, err
+}
+
+func (r *todoRepository) FindTrashedBefore(ctx context.Context, before time.Time, limit int) ([]domain.Todo, error) {
+	todos := make([]domain.Todo, 0)
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, todo := range t.todos {
+			if todo.DeletedAt != nil && todo.DeletedAt.Before(before) {
+				todos = append(todos, copyTimes(todo))
+			}
+		}
+		return nil
+	})
+	sort.Slice(todos, func(i, j int) bool {
+		if !todos[i].DeletedAt.Equal(*todos[j].DeletedAt) {
+			return todos[i].DeletedAt.Before(*todos[j].DeletedAt)
+		}
+		return todos[i].ID < todos[j].ID
+	})
+	if len(todos) > limit {
+		todos = todos[:limit]
+	}
+	return todos, err
+}
+
+func (r *todoRepository) ArchiveCompleted(ctx context.Context, userID, listID int, completedBefore time.Time) (int, error) {
+	now := time.Now()
+	archived := 0
+	err := r.store.write(ctx, func(t *tables) error {
+		// Every archived todo gets the same revision
+		changes, err := domain.DiffTodos(&domain.Todo{}, &domain.Todo{ArchivedAt: &now})
+		if err != nil {
+			return err
+		}
+
+		for id, todo := range t.todos {
+			if todo.CompletedAt == nil || !todo.CompletedAt.Before(completedBefore) ||
+				todo.ArchivedAt != nil || todo.DeletedAt != nil ||
+				(userID != 0 && todo.UserID != userID) || (listID != 0 && todo.ListID != listID) {
+				continue
+			}
+			todo.ArchivedAt = &now
+			todo.Version++
+			t.todos[id] = todo
+			t.addRevision(ctx, domain.RevisionUpdate, &todo, changes)
+			archived++
+		}
+		return nil
+	})
+	return archived, err
+}
+
+func (r *todoRepository) Create(ctx context.Context, todo *domain.Todo) error {
+	return r.store.write(ctx, func(t *tables) error {
+		changes, err := domain.DiffTodos(nil, todo)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		todo.ID = t.nextID()
+		todo.Version = 1
+		todo.CreatedAt = now
+		todo.UpdatedAt = now
+		t.todos[todo.ID] = storedTodo(todo)
+		t.recordRevision(ctx, domain.RevisionCreate, todo, changes)
+		return nil
+	})
+}
+
+func (r *todoRepository) Update(ctx context.Context, todo *domain.Todo) error {
+	return r.store.write(ctx, func(t *tables) error {
+		old, ok := t.todos[todo.ID]
+		if !ok {
+			return domain.ErrTodoNotFound
+		}
+		if old.Version != todo.Version {
+			return domain.ErrVersionConflict
+		}
+		changes, err := domain.DiffTodos(&old, todo)
+		if err != nil {
+			return err
+		}
+
+		todo.UpdatedAt = time.Now()
+		todo.Version++
+
+		// The owner and creation time never change
+		row := storedTodo(todo)
+		row.UserID = old.UserID
+		row.CreatedAt = old.CreatedAt
+		t.todos[todo.ID] = row
+		t.recordRevision(ctx, domain.RevisionUpdate, todo, changes)
+		return nil
+	})
+}
+
+func (r *todoRepository) Delete(ctx context.Context, id int) error {
+	return r.store.write(ctx, func(t *tables) error {
+		old, ok := t.todos[id]
+		if !ok {
+			return domain.ErrTodoNotFound
+		}
+		changes, err := domain.DiffTodos(&old, nil)
+		if err != nil {
+			return err
+		}
+
+		t.deleteTodo(id)
+		t.recordRevision(ctx, domain.RevisionDelete, &old, changes)
+		return nil
+	})
+}
+
+// deleteTodo removes a todo with everything that references it, as the
+// foreign keys of the SQL schema do.
+func (t *tables) deleteTodo(id int) {
+	delete(t.todos, id)
+	delete(t.todoTags, id)
+	for itemID, item := range t.checklist {
+		if item.TodoID == id {
+			delete(t.checklist, itemID)
+		}
+	}
+	for otherID, other := range t.todos {
+		if other.SeriesID == id {
+			other.SeriesID = 0
+			t.todos[otherID] = other
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+// todoRevisionRepository reads the history of todos. Revisions are written
+// by todoRepository and listRepository along with the change they record.
+type todoRevisionRepository struct {
+	store *Store
+}
+
+func NewTodoRevisionRepository(store *Store) *todoRevisionRepository {
+	return &todoRevisionRepository{store: store}
+}
+
+// FindByTodoID returns every revision of the todo, oldest first.
+func (r *todoRevisionRepository) FindByTodoID(ctx context.Context, todoID int) ([]domain.TodoRevision, error) {
+	revisions := make([]domain.TodoRevision, 0)
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, revision := range t.revisions {
+			if revision.TodoID == todoID {
+				changes := make(map[string]domain.FieldChange, len(revision.Changes))
+				for name, change := range revision.Changes {
+					changes[name] = change
+				}
+				revision.Changes = changes
+				revisions = append(revisions, revision)
+			}
+		}
+		return nil
+	})
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision < revisions[j].Revision })
+	return revisions, err
+}
+
+// recordRevision records a change to a todo, unless it changed no tracked
+// field. The changes are diffed before the todo is written, so that a write
+// which cannot be recorded fails before it changes anything.
+func (t *tables) recordRevision(ctx context.Context, action string, todo *domain.Todo, changes map[string]domain.FieldChange) {
+	if len(changes) > 0 {
+		t.addRevision(ctx, action, todo, changes)
+	}
+}
+
+// addRevision appends the next revision of todo. Revisions are never
+// changed once added, so todos may share one changes map.
+func (t *tables) addRevision(ctx context.Context, action string, todo *domain.Todo, changes map[string]domain.FieldChange) {
+	number := 1
+	for _, revision := range t.revisions {
+		if revision.TodoID == todo.ID && revision.Revision >= number {
+			number = revision.Revision + 1
+		}
+	}
+
+	t.revisions = append(t.revisions, domain.TodoRevision{
+		ID:        t.nextID(),
+		TodoID:    todo.ID,
+		Revision:  number,
+		UserID:    todo.UserID,
+		ActorID:   domain.ActorFromContext(ctx),
+		Action:    action,
+		Changes:   changes,
+		CreatedAt: time.Now(),
+	})
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/pkg/textmatch"
+)
+
+// todoSearcher ranks every todo of the user with textmatch, which
+// approximates the Postgres full-text search without stemming.
+type todoSearcher struct {
+	store *Store
+}
+
+func NewTodoSearcher(store *Store) *todoSearcher {
+	return &todoSearcher{store: store}
+}
+
+func (s *todoSearcher) Search(ctx context.Context, q domain.SearchQuery) ([]domain.SearchResult, error) {
+	results := make([]domain.SearchResult, 0)
+	err := s.store.read(ctx, func(t *tables) error {
+		for _, todo := range t.todos {
+			if todo.UserID != q.UserID || todo.DeletedAt != nil || todo.ArchivedAt != nil ||
+				(q.ListID != 0 && todo.ListID != q.ListID) {
+				continue
+			}
+
+			rank, ok := textmatch.Rank(q.Text, todo.Title, todo.Description)
+			if !ok {
+				continue
+			}
+
+			results = append(results, domain.SearchResult{
+				Todo: copyTimes(todo),
+				Rank: rank,
+				Highlights: domain.SearchHighlight{
+					Title:       textmatch.Highlight(todo.Title, q.Text),
+					Description: textmatch.Highlight(todo.Description, q.Text),
+				},
+			})
+		}
+		return nil
+	})
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Todo.ID > results[j].Todo.ID
+	})
+	if len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results, err
+}
@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+type refreshTokenRepository struct {
+	store *Store
+}
+
+func NewRefreshTokenRepository(store *Store) *refreshTokenRepository {
+	return &refreshTokenRepository{store: store}
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	return r.store.write(ctx, func(t *tables) error {
+		token.ID = t.nextID()
+		token.CreatedAt = time.Now()
+		t.refreshTokens[token.ID] = *token
+		return nil
+	})
+}
+
+func (r *refreshTokenRepository) FindByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	var found *domain.RefreshToken
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, token := range t.refreshTokens {
+			if token.TokenHash == hash {
+				found = &token
+				return nil
+			}
+		}
+		return domain.ErrInvalidRefreshToken
+	})
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+func (r *refreshTokenRepository) FindByFamily(ctx context.Context, familyID string) ([]domain.RefreshToken, error) {
+	tokens := make([]domain.RefreshToken, 0)
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, token := range t.refreshTokens {
+			if token.FamilyID == familyID {
+				tokens = append(tokens, token)
+			}
+		}
+		return nil
+	})
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].ID < tokens[j].ID })
+	return tokens, err
+}
+
+func (r *refreshTokenRepository) MarkUsed(ctx context.Context, id int, at time.Time) (bool, error) {
+	marked := false
+	err := r.store.write(ctx, func(t *tables) error {
+		token, ok := t.refreshTokens[id]
+		if !ok || token.UsedAt != nil || token.RevokedAt != nil {
+			return nil
+		}
+		token.UsedAt = &at
+		t.refreshTokens[id] = token
+		marked = true
+		return nil
+	})
+	return marked, err
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for id, token := range t.refreshTokens {
+			if token.FamilyID == familyID && token.RevokedAt == nil {
+				token.RevokedAt = &at
+				t.refreshTokens[id] = token
+			}
+		}
+		return nil
+	})
+}
+
+func (r *refreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	deleted := 0
+	err := r.store.write(ctx, func(t *tables) error {
+		for id, token := range t.refreshTokens {
+			if token.ExpiresAt.Before(before) {
+				delete(t.refreshTokens, id)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+type revokedTokenRepository struct {
+	store *Store
+}
+
+func NewRevokedTokenRepository(store *Store) *revokedTokenRepository {
+	return &revokedTokenRepository{store: store}
+}
+
+func (r *revokedTokenRepository) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.revokedTokens[tokenID]; !ok {
+			t.revokedTokens[tokenID] = expiresAt
+		}
+		return nil
+	})
+}
+
+func (r *revokedTokenRepository) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	revoked := false
+	err := r.store.read(ctx, func(t *tables) error {
+		_, revoked = t.revokedTokens[tokenID]
+		return nil
+	})
+	return revoked, err
+}
+
+func (r *revokedTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	deleted := 0
+	err := r.store.write(ctx, func(t *tables) error {
+		for id, expiresAt := range t.revokedTokens {
+			if expiresAt.Before(before) {
+				delete(t.revokedTokens, id)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type userRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) *userRepository {
+	return &userRepository{store: store}
+}
+
+func (r *userRepository) Create(ctx context.Context, user *domain.User, password string) error {
+	var hashedPassword string
+	if password != "" {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		hashedPassword = string(hashed)
+	}
+
+	return r.store.write(ctx, func(t *tables) error {
+		for _, existing := range t.users {
+			if existing.Email == user.Email || existing.Username == user.Username {
+				return domain.Conflict("user_exists", "a user with this email or username already exists")
+			}
+		}
+
+		user.ID = t.nextID()
+		user.PasswordHash = hashedPassword
+		user.CreatedAt = time.Now()
+		t.users[user.ID] = *user
+		return nil
+	})
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.findOne(ctx, func(u *domain.User) bool { return u.Email == email })
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id int) (*domain.User, error) {
+	return r.findOne(ctx, func(u *domain.User) bool { return u.ID == id })
+}
+
+func (r *userRepository) FindByOAuthID(ctx context.Context, provider, providerID string) (*domain.User, error) {
+	return r.findOne(ctx, func(u *domain.User) bool {
+		return u.OAuthProvider == provider && u.OAuthProviderID == providerID
+	})
+}
+
+func (r *userRepository) findOne(ctx context.Context, match func(u *domain.User) bool) (*domain.User, error) {
+	var found *domain.User
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, user := range t.users {
+			if match(&user) {
+				found = &user
+				return nil
+			}
+		}
+		return domain.ErrUserNotFound
+	})
+	return found, err
+}
+
+func (r *userRepository) UpdateOAuthInfo(ctx context.Context, user *domain.User) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if stored, ok := t.users[user.ID]; ok {
+			stored.OAuthProvider = user.OAuthProvider
+			stored.OAuthProviderID = user.OAuthProviderID
+			t.users[user.ID] = stored
+		}
+		return nil
+	})
+}
+
+func (r *userRepository) UpdateTimezone(ctx context.Context, userID int, timezone string) error {
+	return r.store.write(ctx, func(t *tables) error {
+		stored, ok := t.users[userID]
+		if !ok {
+			return domain.ErrUserNotFound
+		}
+		stored.Timezone = timezone
+		t.users[userID] = stored
+		return nil
+	})
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, userID int, password string) error {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return r.store.write(ctx, func(t *tables) error {
+		stored, ok := t.users[userID]
+		if !ok {
+			return domain.ErrUserNotFound
+		}
+		stored.PasswordHash = string(hashed)
+		t.users[userID] = stored
+		return nil
+	})
+}
+
+func (r *userRepository) MarkEmailVerified(ctx context.Context, userID int, at time.Time) error {
+	return r.store.write(ctx, func(t *tables) error {
+		stored, ok := t.users[userID]
+		if !ok {
+			return domain.ErrUserNotFound
+		}
+		if stored.EmailVerifiedAt == nil {
+			stored.EmailVerifiedAt = &at
+			t.users[userID] = stored
+		}
+		return nil
+	})
+}
@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+type workflowRepository struct {
+	store *Store
+}
+
+func NewWorkflowRepository(store *Store) *workflowRepository {
+	return &workflowRepository{store: store}
+}
+
+// storedWorkflow copies the slices of workflow, so that neither the caller
+// nor the store sees the other's changes. Transitions are never nil, as
+// when read back from a JSON column.
+func storedWorkflow(workflow domain.Workflow) domain.Workflow {
+	workflow.Statuses = append([]domain.WorkflowStatus{}, workflow.Statuses...)
+	workflow.Transitions = append([]domain.WorkflowTransition{}, workflow.Transitions...)
+	return workflow
+}
+
+func (r *workflowRepository) FindAll(ctx context.Context, userID int) ([]domain.Workflow, error) {
+	workflows := make([]domain.Workflow, 0)
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, workflow := range t.workflows {
+			if workflow.UserID == userID {
+				workflows = append(workflows, storedWorkflow(workflow))
+			}
+		}
+		return nil
+	})
+	// The default workflow comes first
+	sort.Slice(workflows, func(i, j int) bool {
+		if workflows[i].ListID != workflows[j].ListID {
+			return workflows[i].ListID < workflows[j].ListID
+		}
+		return workflows[i].Name < workflows[j].Name
+	})
+	return workflows, err
+}
+
+func (r *workflowRepository) FindByID(ctx context.Context, id int) (*domain.Workflow, error) {
+	var workflow domain.Workflow
+	err := r.store.read(ctx, func(t *tables) error {
+		stored, ok := t.workflows[id]
+		if !ok {
+			return domain.ErrWorkflowNotFound
+		}
+		workflow = storedWorkflow(stored)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &workflow, nil
+}
+
+func (r *workflowRepository) FindEffective(ctx context.Context, userID, listID int) (*domain.Workflow, error) {
+	var effective *domain.Workflow
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, workflow := range t.workflows {
+			if workflow.UserID != userID {
+				continue
+			}
+			// A list's own workflow wins over the user's default
+			if (listID != 0 && workflow.ListID == listID) || (workflow.ListID == 0 && effective == nil) {
+				copied := storedWorkflow(workflow)
+				effective = &copied
+			}
+		}
+		return nil
+	})
+	return effective, err
+}
+
+func (r *workflowRepository) Create(ctx context.Context, workflow *domain.Workflow) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for _, existing := range t.workflows {
+			if existing.UserID != workflow.UserID || existing.ListID != workflow.ListID {
+				continue
+			}
+			if workflow.ListID == 0 {
+				return domain.Conflict("workflow_exists", "a default workflow already exists")
+			}
+			return domain.Conflict("workflow_exists", "this list already has a workflow")
+		}
+
+		now := time.Now()
+		workflow.ID = t.nextID()
+		workflow.CreatedAt = now
+		workflow.UpdatedAt = now
+		t.workflows[workflow.ID] = storedWorkflow(*workflow)
+		return nil
+	})
+}
+
+func (r *workflowRepository) Update(ctx context.Context, workflow *domain.Workflow) error {
+	return r.store.write(ctx, func(t *tables) error {
+		stored, ok := t.workflows[workflow.ID]
+		if !ok {
+			return domain.ErrWorkflowNotFound
+		}
+		workflow.UpdatedAt = time.Now()
+		stored.Name = workflow.Name
+		stored.Statuses = workflow.Statuses
+		stored.Transitions = workflow.Transitions
+		stored.UpdatedAt = workflow.UpdatedAt
+		t.workflows[workflow.ID] = storedWorkflow(stored)
+		return nil
+	})
+}
+
+func (r *workflowRepository) Delete(ctx context.Context, id int) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.workflows[id]; !ok {
+			return domain.ErrWorkflowNotFound
+		}
+		delete(t.workflows, id)
+		return nil
+	})
+}
@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/lib/pq"
+)
+
+type checklistRepository struct {
+	db *sql.DB
+}
+
+func NewChecklistRepository(db *sql.DB) *checklistRepository {
+	return &checklistRepository{db: db}
+}
+
+const checklistColumns = `id, todo_id, text, done, position, created_at, updated_at`
+
+func scanChecklistItem(row interface{ Scan(dest ...interface{}) error }) (*domain.ChecklistItem, error) {
+	var item domain.ChecklistItem
+	err := row.Scan(&item.ID, &item.TodoID, &item.Text, &item.Done, &item.Position, &item.CreatedAt, &item.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *checklistRepository) FindByTodoID(ctx context.Context, todoID int) ([]domain.ChecklistItem, error) {
+	query := `SELECT ` + checklistColumns + `
+              FROM checklist_items
+              WHERE todo_id = $1
+              ORDER BY position, id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying checklist: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]domain.ChecklistItem, 0)
+	for rows.Next() {
+		item, err := scanChecklistItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning checklist item: %w", err)
+		}
+		items = append(items, *item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *checklistRepository) FindByID(ctx context.Context, id int) (*domain.ChecklistItem, error) {
+	query := `SELECT ` + checklistColumns + ` FROM checklist_items WHERE id = $1`
+
+	item, err := scanChecklistItem(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrChecklistItemNotFound
+		}
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (r *checklistRepository) Progress(ctx context.Context, todoIDs []int) (map[int]domain.ChecklistProgress, error) {
+	progress := make(map[int]domain.ChecklistProgress, len(todoIDs))
+	if len(todoIDs) == 0 {
+		return progress, nil
+	}
+
+	query := `SELECT todo_id, COUNT(*) FILTER (WHERE done), COUNT(*)
+              FROM checklist_items
+              WHERE todo_id = ANY($1)
+              GROUP BY todo_id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, pq.Array(todoIDs))
+	if err != nil {
+		return nil, fmt.Errorf("error counting checklist items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var todoID int
+		var p domain.ChecklistProgress
+		if err := rows.Scan(&todoID, &p.Done, &p.Total); err != nil {
+			return nil, fmt.Errorf("error scanning checklist progress: %w", err)
+		}
+		progress[todoID] = p
+	}
+
+	return progress, rows.Err()
+}
+
+func (r *checklistRepository) Create(ctx context.Context, item *domain.ChecklistItem) error {
+	query := `INSERT INTO checklist_items (todo_id, text, done, position, created_at, updated_at)
+              VALUES ($1, $2, $3,
+                  (SELECT COALESCE(MAX(position) + 1, 0) FROM checklist_items WHERE todo_id = $1),
+                  $4, $5)
+              RETURNING id, position`
+
+	now := time.Now()
+	item.CreatedAt = now
+	item.UpdatedAt = now
+
+	return conn(ctx, r.db).QueryRowContext(ctx, query, item.TodoID, item.Text, item.Done, item.CreatedAt, item.UpdatedAt).
+		Scan(&item.ID, &item.Position)
+}
+
+func (r *checklistRepository) Update(ctx context.Context, item *domain.ChecklistItem) error {
+	query := `UPDATE checklist_items SET text = $1, done = $2, updated_at = $3 WHERE id = $4`
+
+	item.UpdatedAt = time.Now()
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, item.Text, item.Done, item.UpdatedAt, item.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrChecklistItemNotFound
+	}
+
+	return nil
+}
+
+func (r *checklistRepository) Delete(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM checklist_items WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrChecklistItemNotFound
+	}
+
+	return nil
+}
+
+func (r *checklistRepository) Reorder(ctx context.Context, todoID int, itemIDs []int) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for position, id := range itemIDs {
+		_, err := tx.ExecContext(ctx,
+			`UPDATE checklist_items SET position = $1, updated_at = $2 WHERE id = $3 AND todo_id = $4`,
+			position, now, id, todoID)
+		if err != nil {
+			return fmt.Errorf("failed to reorder checklist: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
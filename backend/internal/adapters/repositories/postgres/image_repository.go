@@ -11,6 +11,7 @@ import (
     "time"
     "bytes"
 	"strconv"
+    "github.com/ChaiyawutTar/MyList/internal/core/domain"
     "github.com/ChaiyawutTar/MyList/internal/core/ports"
 )
 
@@ -19,20 +20,6 @@ type imageRepository struct {
 }
 
 func NewImageRepository(db *sql.DB) ports.ImageRepository {
-    // Create the images table if it doesn't exist
-    _, err := db.Exec(`
-        CREATE TABLE IF NOT EXISTS images (
-            id SERIAL PRIMARY KEY,
-            filename TEXT NOT NULL,
-            data BYTEA NOT NULL,
-            content_type TEXT,
-            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-        )
-    `)
-    if err != nil {
-        panic(err)
-    }
-
     return &imageRepository{
         db: db,
     }
@@ -65,7 +52,7 @@ func (r *imageRepository) Save(ctx context.Context, file multipart.File, filenam
     fileBytes := fileBuffer.Bytes()
     
     // Begin a transaction
-    tx, err := r.db.BeginTx(ctx, nil)
+    tx, err := beginTx(ctx, r.db)
     if err != nil {
         return "", fmt.Errorf("failed to begin transaction: %w", err)
     }
@@ -103,7 +90,7 @@ func (r *imageRepository) Save(ctx context.Context, file multipart.File, filenam
 func (r *imageRepository) Delete(ctx context.Context, imageID string) error {
     fmt.Printf("Deleting image with ID: %s\n", imageID)
     
-    result, err := r.db.ExecContext(ctx, "DELETE FROM images WHERE id = $1", imageID)
+    result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM images WHERE id = $1", imageID)
     if err != nil {
         return fmt.Errorf("failed to delete image: %w", err)
     }
@@ -114,7 +101,7 @@ func (r *imageRepository) Delete(ctx context.Context, imageID string) error {
     }
     
     if rowsAffected == 0 {
-        return fmt.Errorf("image with ID %s not found", imageID)
+        return domain.ErrImageNotFound
     }
     
     fmt.Printf("Successfully deleted image with ID: %s\n", imageID)
@@ -134,10 +121,10 @@ func (r *imageRepository) Get(ctx context.Context, imageID string) ([]byte, stri
     var filename string
     
     query := "SELECT data, content_type, filename FROM images WHERE id = $1"
-    err = r.db.QueryRowContext(ctx, query, id).Scan(&data, &contentType, &filename)
+    err = conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(&data, &contentType, &filename)
     if err != nil {
         if err == sql.ErrNoRows {
-            return nil, "", fmt.Errorf("image not found: %s", imageID)
+            return nil, "", domain.ErrImageNotFound
         }
         return nil, "", fmt.Errorf("error querying image: %w", err)
     }
@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+type listRepository struct {
+	db *sql.DB
+}
+
+func NewListRepository(db *sql.DB) *listRepository {
+	return &listRepository{db: db}
+}
+
+const listColumns = `id, user_id, name, is_inbox, created_at, updated_at`
+
+func scanList(row interface{ Scan(dest ...interface{}) error }) (*domain.List, error) {
+	var list domain.List
+	err := row.Scan(&list.ID, &list.UserID, &list.Name, &list.IsInbox, &list.CreatedAt, &list.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (r *listRepository) FindAll(ctx context.Context, userID int) ([]domain.List, error) {
+	query := `SELECT ` + listColumns + `
+              FROM lists
+              WHERE user_id = $1
+              ORDER BY is_inbox DESC, name`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying lists: %w", err)
+	}
+	defer rows.Close()
+
+	lists := make([]domain.List, 0)
+	for rows.Next() {
+		list, err := scanList(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning list: %w", err)
+		}
+		lists = append(lists, *list)
+	}
+
+	return lists, rows.Err()
+}
+
+func (r *listRepository) FindByID(ctx context.Context, id int) (*domain.List, error) {
+	query := `SELECT ` + listColumns + ` FROM lists WHERE id = $1`
+	return r.findOne(ctx, query, id)
+}
+
+func (r *listRepository) FindInbox(ctx context.Context, userID int) (*domain.List, error) {
+	query := `SELECT ` + listColumns + ` FROM lists WHERE user_id = $1 AND is_inbox`
+	return r.findOne(ctx, query, userID)
+}
+
+func (r *listRepository) findOne(ctx context.Context, query string, arg interface{}) (*domain.List, error) {
+	list, err := scanList(conn(ctx, r.db).QueryRowContext(ctx, query, arg))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrListNotFound
+		}
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *listRepository) Create(ctx context.Context, list *domain.List) error {
+	query := `INSERT INTO lists (user_id, name, is_inbox, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5)
+              RETURNING id`
+
+	now := time.Now()
+	list.CreatedAt = now
+	list.UpdatedAt = now
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, list.UserID, list.Name, list.IsInbox, list.CreatedAt, list.UpdatedAt).Scan(&list.ID)
+	if list.IsInbox && isUniqueViolation(err) {
+		return domain.Conflict("inbox_exists", "inbox already exists")
+	}
+	return err
+}
+
+func (r *listRepository) Update(ctx context.Context, list *domain.List) error {
+	query := `UPDATE lists SET name = $1, updated_at = $2 WHERE id = $3`
+
+	list.UpdatedAt = time.Now()
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, list.Name, list.UpdatedAt, list.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrListNotFound
+	}
+
+	return nil
+}
+
+func (r *listRepository) Delete(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM lists WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrListNotFound
+	}
+
+	return nil
+}
+
+func (r *listRepository) MoveTodos(ctx context.Context, fromListID, toListID int) error {
+	query := `UPDATE todos SET list_id = $1, updated_at = $2, version = version + 1 WHERE list_id = $3 RETURNING id, user_id`
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	changes, err := domain.DiffTodos(&domain.Todo{ListID: fromListID}, &domain.Todo{ListID: toListID})
+	if err != nil {
+		return err
+	}
+	if _, err := insertBulkRevisions(ctx, tx, query, []interface{}{toListID, time.Now(), fromListID}, changes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/lib/pq"
+)
+
+type mfaRepository struct {
+	db *sql.DB
+}
+
+func NewMFARepository(db *sql.DB) *mfaRepository {
+	return &mfaRepository{db: db}
+}
+
+func (r *mfaRepository) Save(ctx context.Context, mfa *domain.MFA) error {
+	query := `INSERT INTO user_mfa (user_id, totp_secret, enabled_at, last_used_step, failed_attempts, locked_until, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7)
+              ON CONFLICT (user_id) DO UPDATE SET
+                  totp_secret = EXCLUDED.totp_secret,
+                  enabled_at = EXCLUDED.enabled_at,
+                  last_used_step = EXCLUDED.last_used_step,
+                  failed_attempts = EXCLUDED.failed_attempts,
+                  locked_until = EXCLUDED.locked_until,
+                  created_at = EXCLUDED.created_at`
+
+	mfa.CreatedAt = time.Now()
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, mfa.UserID, mfa.Secret, mfa.EnabledAt,
+		mfa.LastUsedStep, mfa.FailedAttempts, mfa.LockedUntil, mfa.CreatedAt)
+	return err
+}
+
+func (r *mfaRepository) FindByUserID(ctx context.Context, userID int) (*domain.MFA, error) {
+	query := `SELECT user_id, totp_secret, enabled_at, last_used_step, failed_attempts, locked_until, created_at
+              FROM user_mfa WHERE user_id = $1`
+
+	var mfa domain.MFA
+	var enabledAt, lockedUntil sql.NullTime
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, userID).Scan(&mfa.UserID, &mfa.Secret, &enabledAt,
+		&mfa.LastUsedStep, &mfa.FailedAttempts, &lockedUntil, &mfa.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrMFANotFound
+		}
+		return nil, err
+	}
+	mfa.EnabledAt = nullTimePtr(enabledAt)
+	mfa.LockedUntil = nullTimePtr(lockedUntil)
+	return &mfa, nil
+}
+
+func (r *mfaRepository) Enable(ctx context.Context, userID int, at time.Time) error {
+	return r.update(ctx, `UPDATE user_mfa SET enabled_at = $2 WHERE user_id = $1`, userID, at)
+}
+
+// UseStep only moves last_used_step forward; of two concurrent uses of
+// one step, the second waits for the row lock and then matches no row.
+func (r *mfaRepository) UseStep(ctx context.Context, userID int, step int64) (bool, error) {
+	query := `UPDATE user_mfa SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected == 1, nil
+}
+
+func (r *mfaRepository) AddFailure(ctx context.Context, userID int) (int, error) {
+	query := `UPDATE user_mfa SET failed_attempts = failed_attempts + 1 WHERE user_id = $1 RETURNING failed_attempts`
+
+	var failures int
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, userID).Scan(&failures)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, domain.ErrMFANotFound
+	}
+	return failures, err
+}
+
+func (r *mfaRepository) Lock(ctx context.Context, userID int, until time.Time) error {
+	return r.update(ctx, `UPDATE user_mfa SET locked_until = $2, failed_attempts = 0 WHERE user_id = $1`, userID, until)
+}
+
+func (r *mfaRepository) ClearFailures(ctx context.Context, userID int) error {
+	return r.update(ctx, `UPDATE user_mfa SET failed_attempts = 0 WHERE user_id = $1`, userID)
+}
+
+func (r *mfaRepository) Delete(ctx context.Context, userID int) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID)
+	return err
+}
+
+// update runs an UPDATE of one user's row, with the user ID as $1.
+func (r *mfaRepository) update(ctx context.Context, query string, userID int, args ...interface{}) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, append([]interface{}{userID}, args...)...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrMFANotFound
+	}
+	return nil
+}
+
+type recoveryCodeRepository struct {
+	db *sql.DB
+}
+
+func NewRecoveryCodeRepository(db *sql.DB) *recoveryCodeRepository {
+	return &recoveryCodeRepository{db: db}
+}
+
+func (r *recoveryCodeRepository) Replace(ctx context.Context, userID int, hashes []string) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to clear recovery codes: %w", err)
+	}
+
+	if len(hashes) > 0 {
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO mfa_recovery_codes (user_id, code_hash, created_at)
+            SELECT $1::integer, UNNEST($2::text[]), $3::timestamptz`, userID, pq.Array(hashes), time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to store recovery codes: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Use only updates an unused code; a concurrent Use of the same code
+// waits for the row lock and then finds it used.
+func (r *recoveryCodeRepository) Use(ctx context.Context, userID int, hash string, at time.Time) (bool, error) {
+	query := `UPDATE mfa_recovery_codes SET used_at = $1
+              WHERE id = (SELECT id FROM mfa_recovery_codes
+                          WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL
+                          LIMIT 1)
+              AND used_at IS NULL`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, at, userID, hash)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected == 1, nil
+}
+
+func (r *recoveryCodeRepository) CountUnused(ctx context.Context, userID int) (int, error) {
+	query := `SELECT COUNT(*) FROM mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+
+	var count int
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+func (r *recoveryCodeRepository) DeleteAll(ctx context.Context, userID int) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID)
+	return err
+}
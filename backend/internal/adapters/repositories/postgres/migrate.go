@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+
+	"github.com/ChaiyawutTar/MyList/pkg/migrate"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockKey identifies the advisory lock migrators take, so only one
+// instance migrates at a time.
+const migrationLockKey = 4829017734561
+
+// NewMigrator returns a migrator for the Postgres schema.
+func NewMigrator(db *sql.DB) (*migrate.Migrator, error) {
+	files, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(db, files, advisoryLock)
+}
+
+func advisoryLock(ctx context.Context, conn *sql.Conn) (func() error, error) {
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return nil, err
+	}
+	return func() error {
+		// The lock outlives a cancelled ctx, so release it regardless
+		_, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+		return err
+	}, nil
+}
@@ -0,0 +1,32 @@
+DROP TABLE IF EXISTS todo_revisions;
+DROP TABLE IF EXISTS checklist_items;
+DROP TABLE IF EXISTS todo_tags;
+DROP TABLE IF EXISTS tags;
+
+DROP INDEX IF EXISTS todos_completed_at_idx;
+DROP INDEX IF EXISTS todos_archived_idx;
+DROP INDEX IF EXISTS todos_deleted_at_idx;
+DROP INDEX IF EXISTS todos_list_position_idx;
+DROP INDEX IF EXISTS todos_title_trgm_idx;
+DROP INDEX IF EXISTS todos_search_idx;
+
+ALTER TABLE todos
+    DROP COLUMN IF EXISTS search_vector,
+    DROP COLUMN IF EXISTS version,
+    DROP COLUMN IF EXISTS deleted_at,
+    DROP COLUMN IF EXISTS archived_at,
+    DROP COLUMN IF EXISTS position,
+    DROP COLUMN IF EXISTS priority,
+    DROP COLUMN IF EXISTS completed_at,
+    DROP COLUMN IF EXISTS auto_complete,
+    DROP COLUMN IF EXISTS recurrence_index,
+    DROP COLUMN IF EXISTS series_id,
+    DROP COLUMN IF EXISTS recurrence_rule,
+    DROP COLUMN IF EXISTS due_at,
+    DROP COLUMN IF EXISTS start_at,
+    DROP COLUMN IF EXISTS list_id;
+
+DROP TABLE IF EXISTS workflows;
+DROP TABLE IF EXISTS lists;
+
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,111 @@
+-- Everything added to the schema before migrations existed: time zones,
+-- dates and recurrence, lists, workflows, tags, checklists, ordering,
+-- trash, archive, search and revision history. Like 0001 it also adopts
+-- databases that already have some of it.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64);
+
+CREATE TABLE IF NOT EXISTS lists (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    is_inbox BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS lists_one_inbox_idx ON lists (user_id) WHERE is_inbox;
+
+CREATE TABLE IF NOT EXISTS workflows (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    list_id INTEGER REFERENCES lists(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    statuses JSONB NOT NULL,
+    transitions JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One default workflow per user (list_id NULL) and at most one per list
+CREATE UNIQUE INDEX IF NOT EXISTS workflows_user_list_idx ON workflows (user_id, COALESCE(list_id, 0));
+
+ALTER TABLE todos
+    ADD COLUMN IF NOT EXISTS list_id INTEGER REFERENCES lists(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS start_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS due_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS recurrence_rule TEXT,
+    ADD COLUMN IF NOT EXISTS series_id INTEGER REFERENCES todos(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS recurrence_index INTEGER,
+    ADD COLUMN IF NOT EXISTS auto_complete BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS position TEXT COLLATE "C" NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(description, '')), 'B')
+    ) STORED;
+
+-- Full-text search, with trigram matching for typos
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS todos_search_idx ON todos USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS todos_title_trgm_idx ON todos USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS todos_list_position_idx ON todos (list_id, position);
+CREATE INDEX IF NOT EXISTS todos_deleted_at_idx ON todos (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS todos_archived_idx ON todos (user_id, archived_at) WHERE archived_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS todos_completed_at_idx ON todos (completed_at) WHERE archived_at IS NULL AND deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(20),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS tags_user_name_idx ON tags (user_id, LOWER(name));
+
+CREATE TABLE IF NOT EXISTS todo_tags (
+    todo_id INTEGER REFERENCES todos(id) ON DELETE CASCADE,
+    tag_id INTEGER REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (todo_id, tag_id)
+);
+
+CREATE TABLE IF NOT EXISTS checklist_items (
+    id SERIAL PRIMARY KEY,
+    todo_id INTEGER REFERENCES todos(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    done BOOLEAN NOT NULL DEFAULT FALSE,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One immutable row per change to a todo; kept after the todo is purged
+CREATE TABLE IF NOT EXISTS todo_revisions (
+    id SERIAL PRIMARY KEY,
+    todo_id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    action TEXT NOT NULL,
+    changes JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (todo_id, revision)
+);
+
+-- Move todos created before lists existed into an inbox
+INSERT INTO lists (user_id, name, is_inbox) SELECT id, 'Inbox', TRUE FROM users ON CONFLICT DO NOTHING;
+UPDATE todos t SET list_id = l.id FROM lists l WHERE l.user_id = t.user_id AND l.is_inbox AND t.list_id IS NULL;
+
+-- Move existing todos onto the default workflow
+INSERT INTO workflows (user_id, name, statuses) SELECT id, 'Default', '[{"key": "pending", "name": "Pending", "position": 0, "complete": false}, {"key": "in_progress", "name": "In Progress", "position": 1, "complete": false}, {"key": "done", "name": "Done", "position": 2, "complete": true}]' FROM users ON CONFLICT DO NOTHING;
+UPDATE todos SET status = 'pending' WHERE status IS NULL OR status NOT IN (SELECT s->>'key' FROM workflows w, jsonb_array_elements(w.statuses) s WHERE w.user_id = todos.user_id);
+UPDATE todos SET completed_at = updated_at WHERE status = 'done' AND completed_at IS NULL;
+
+-- Give existing todos a manual order, oldest first in each list
+UPDATE todos t SET position = LPAD(TO_HEX(r.n), 8, '0') FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY list_id ORDER BY created_at, id) AS n FROM todos) r WHERE r.id = t.id AND t.position = '';
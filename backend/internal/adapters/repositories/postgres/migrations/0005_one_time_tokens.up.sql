@@ -0,0 +1,15 @@
+-- Single-use tokens mailed to users, such as password reset links, stored
+-- by hash.
+
+CREATE TABLE one_time_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(32) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX one_time_tokens_user_idx ON one_time_tokens (user_id, purpose);
+CREATE INDEX one_time_tokens_expires_at_idx ON one_time_tokens (expires_at);
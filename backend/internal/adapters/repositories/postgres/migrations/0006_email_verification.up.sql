@@ -0,0 +1,6 @@
+-- When users verified their email address. Users who logged in with Google
+-- proved they own the address already.
+
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+
+UPDATE users SET email_verified_at = created_at WHERE oauth_provider = 'google';
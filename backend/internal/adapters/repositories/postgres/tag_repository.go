@@ -0,0 +1,220 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/lib/pq"
+)
+
+type tagRepository struct {
+	db *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) *tagRepository {
+	return &tagRepository{db: db}
+}
+
+const tagColumns = `id, user_id, name, COALESCE(color, ''), created_at`
+
+func scanTag(row interface{ Scan(dest ...interface{}) error }) (*domain.Tag, error) {
+	var tag domain.Tag
+	err := row.Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.Color, &tag.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) queryTags(ctx context.Context, query string, args ...interface{}) ([]domain.Tag, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make([]domain.Tag, 0)
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning tag: %w", err)
+		}
+		tags = append(tags, *tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func (r *tagRepository) FindAll(ctx context.Context, userID int) ([]domain.Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags WHERE user_id = $1 ORDER BY name`
+	return r.queryTags(ctx, query, userID)
+}
+
+func (r *tagRepository) FindByID(ctx context.Context, id int) (*domain.Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags WHERE id = $1`
+
+	tag, err := scanTag(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTagNotFound
+		}
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+// FindByNames matches names case-insensitively.
+func (r *tagRepository) FindByNames(ctx context.Context, userID int, names []string) ([]domain.Tag, error) {
+	lowered := make([]string, len(names))
+	for i, name := range names {
+		lowered[i] = strings.ToLower(name)
+	}
+
+	query := `SELECT ` + tagColumns + ` FROM tags WHERE user_id = $1 AND LOWER(name) = ANY($2) ORDER BY name`
+	return r.queryTags(ctx, query, userID, pq.Array(lowered))
+}
+
+func (r *tagRepository) FindByTodoIDs(ctx context.Context, todoIDs []int) (map[int][]domain.Tag, error) {
+	result := make(map[int][]domain.Tag, len(todoIDs))
+	if len(todoIDs) == 0 {
+		return result, nil
+	}
+
+	query := `SELECT tt.todo_id, t.id, t.user_id, t.name, COALESCE(t.color, ''), t.created_at
+              FROM todo_tags tt
+              JOIN tags t ON t.id = tt.tag_id
+              WHERE tt.todo_id = ANY($1)
+              ORDER BY t.name`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, pq.Array(todoIDs))
+	if err != nil {
+		return nil, fmt.Errorf("error querying todo tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var todoID int
+		var tag domain.Tag
+		if err := rows.Scan(&todoID, &tag.ID, &tag.UserID, &tag.Name, &tag.Color, &tag.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning todo tag: %w", err)
+		}
+		result[todoID] = append(result[todoID], tag)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *tagRepository) Create(ctx context.Context, tag *domain.Tag) error {
+	query := `INSERT INTO tags (user_id, name, color, created_at)
+              VALUES ($1, $2, $3, $4)
+              RETURNING id`
+
+	tag.CreatedAt = time.Now()
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, tag.UserID, tag.Name, tag.Color, tag.CreatedAt).Scan(&tag.ID)
+	if isUniqueViolation(err) {
+		return domain.Conflict("tag_exists", fmt.Sprintf("tag %q already exists", tag.Name))
+	}
+	return err
+}
+
+// Update renames or recolours a tag. Todos reference tags by ID, so every
+// todo carrying the tag picks up the new name.
+func (r *tagRepository) Update(ctx context.Context, tag *domain.Tag) error {
+	query := `UPDATE tags SET name = $1, color = $2 WHERE id = $3`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, tag.Name, tag.Color, tag.ID)
+	if isUniqueViolation(err) {
+		return domain.Conflict("tag_exists", fmt.Sprintf("tag %q already exists", tag.Name))
+	}
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrTagNotFound
+	}
+
+	return nil
+}
+
+func (r *tagRepository) Delete(ctx context.Context, id int) error {
+	// todo_tags rows go with it through ON DELETE CASCADE
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM tags WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrTagNotFound
+	}
+
+	return nil
+}
+
+func (r *tagRepository) Merge(ctx context.Context, targetID int, sourceIDs []int) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO todo_tags (todo_id, tag_id)
+        SELECT DISTINCT todo_id, $1::integer FROM todo_tags WHERE tag_id = ANY($2)
+        ON CONFLICT DO NOTHING`, targetID, pq.Array(sourceIDs))
+	if err != nil {
+		return fmt.Errorf("failed to retag todos: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE id = ANY($1)`, pq.Array(sourceIDs)); err != nil {
+		return fmt.Errorf("failed to delete merged tags: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *tagRepository) SetTodoTags(ctx context.Context, todoID int, tagIDs []int) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM todo_tags WHERE todo_id = $1`, todoID); err != nil {
+		return fmt.Errorf("failed to clear todo tags: %w", err)
+	}
+
+	if len(tagIDs) > 0 {
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO todo_tags (todo_id, tag_id)
+            SELECT $1, UNNEST($2::integer[])
+            ON CONFLICT DO NOTHING`, todoID, pq.Array(tagIDs))
+		if err != nil {
+			return fmt.Errorf("failed to tag todo: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// isUniqueViolation reports whether err is a Postgres unique_violation.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
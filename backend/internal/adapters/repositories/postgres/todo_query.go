@@ -0,0 +1,211 @@
+package postgres
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/lib/pq"
+)
+
+// todoSort describes how to order and page by one sort field. Cursor values
+// are strings cast back to the column type, so value must format a todo's
+// field the way Postgres parses it.
+type todoSort struct {
+	expr  string
+	cast  string
+	value func(todo *domain.Todo) string
+}
+
+const timestampLayout = "2006-01-02T15:04:05.999999"
+
+var todoSorts = map[string]todoSort{
+	domain.SortCreatedAt: {
+		expr:  "created_at",
+		cast:  "timestamp",
+		value: func(t *domain.Todo) string { return t.CreatedAt.Format(timestampLayout) },
+	},
+	domain.SortUpdatedAt: {
+		expr:  "updated_at",
+		cast:  "timestamp",
+		value: func(t *domain.Todo) string { return t.UpdatedAt.Format(timestampLayout) },
+	},
+	domain.SortDueAt: {
+		// Todos without a due date sort after every dated one
+		expr: "COALESCE(due_at, 'infinity')",
+		cast: "timestamptz",
+		value: func(t *domain.Todo) string {
+			if t.DueAt == nil {
+				return "infinity"
+			}
+			return t.DueAt.Format(time.RFC3339Nano)
+		},
+	},
+	domain.SortTitle: {
+		expr:  "title",
+		cast:  "text",
+		value: func(t *domain.Todo) string { return t.Title },
+	},
+	domain.SortPriority: {
+		expr: "priority",
+		cast: "smallint",
+		value: func(t *domain.Todo) string {
+			level, _ := domain.PriorityLevel(t.Priority)
+			return strconv.Itoa(level)
+		},
+	},
+	domain.SortArchived: {
+		// Only archived todos have a value; the rest sort as if archived
+		// at the beginning of time
+		expr: "COALESCE(archived_at, '-infinity')",
+		cast: "timestamptz",
+		value: func(t *domain.Todo) string {
+			if t.ArchivedAt == nil {
+				return "-infinity"
+			}
+			return t.ArchivedAt.Format(time.RFC3339Nano)
+		},
+	},
+	domain.SortPosition: {
+		// position is COLLATE "C" so ranks compare byte by byte
+		expr:  "position",
+		cast:  "text",
+		value: func(t *domain.Todo) string { return t.Position },
+	},
+}
+
+// todoQueryBuilder collects WHERE conditions and their positional arguments.
+type todoQueryBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+// add appends a condition; each %s in cond is replaced by the placeholder of
+// the matching argument.
+func (b *todoQueryBuilder) add(cond string, args ...interface{}) {
+	placeholders := make([]interface{}, len(args))
+	for i, arg := range args {
+		b.args = append(b.args, arg)
+		placeholders[i] = fmt.Sprintf("$%d", len(b.args))
+	}
+	b.conditions = append(b.conditions, fmt.Sprintf(cond, placeholders...))
+}
+
+// buildTodoQuery turns a normalized TodoQuery into SQL. It selects one row
+// more than the limit so the caller can tell whether another page exists.
+func buildTodoQuery(q domain.TodoQuery) (string, []interface{}, error) {
+	sort, ok := todoSorts[q.Sort]
+	if !ok {
+		return "", nil, fmt.Errorf("unsupported sort %q", q.Sort)
+	}
+
+	b := &todoQueryBuilder{}
+	b.add("user_id = %s", q.UserID)
+	if q.Trashed {
+		b.add("deleted_at IS NOT NULL")
+	} else {
+		b.add("deleted_at IS NULL")
+	}
+	if q.Archived {
+		b.add("archived_at IS NOT NULL")
+	} else if !q.IncludeArchived {
+		b.add("archived_at IS NULL")
+	}
+
+	if q.ListID != 0 {
+		b.add("list_id = %s", q.ListID)
+	}
+	if len(q.Statuses) > 0 {
+		b.add("status = ANY(%s)", pq.Array(q.Statuses))
+	}
+	if q.Text != "" {
+		pattern := "%" + escapeLike(q.Text) + "%"
+		b.add("(title ILIKE %[1]s OR description ILIKE %[1]s)", pattern)
+	}
+	if q.CreatedAfter != nil {
+		b.add("created_at > %s", *q.CreatedAfter)
+	}
+	if q.CreatedBefore != nil {
+		b.add("created_at < %s", *q.CreatedBefore)
+	}
+	if q.UpdatedAfter != nil {
+		b.add("updated_at > %s", *q.UpdatedAfter)
+	}
+	if q.UpdatedBefore != nil {
+		b.add("updated_at < %s", *q.UpdatedBefore)
+	}
+	if q.DueAfter != nil {
+		b.add("due_at > %s", *q.DueAfter)
+	}
+	if q.DueBefore != nil {
+		b.add("due_at < %s", *q.DueBefore)
+	}
+
+	if len(q.Tags) > 0 {
+		names := make([]string, 0, len(q.Tags))
+		seen := make(map[string]bool)
+		for _, name := range q.Tags {
+			name = strings.ToLower(name)
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		if q.TagMatch == domain.TagMatchAll {
+			b.add(`id IN (
+                SELECT tt.todo_id FROM todo_tags tt JOIN tags t ON t.id = tt.tag_id
+                WHERE LOWER(t.name) = ANY(%s)
+                GROUP BY tt.todo_id HAVING COUNT(DISTINCT t.id) = %s)`, pq.Array(names), len(names))
+		} else {
+			b.add(`id IN (
+                SELECT tt.todo_id FROM todo_tags tt JOIN tags t ON t.id = tt.tag_id
+                WHERE LOWER(t.name) = ANY(%s))`, pq.Array(names))
+		}
+	}
+
+	if q.Overdue {
+		// Mirrors todoService.isOverdue: a due date at midnight in the
+		// user's zone covers the whole day.
+		loc := q.Location
+		if loc == nil {
+			loc = time.UTC
+		}
+		b.add(`completed_at IS NULL AND due_at IS NOT NULL AND
+            CASE WHEN (due_at AT TIME ZONE %[1]s::text)::time = '00:00'
+                THEN ((due_at AT TIME ZONE %[1]s::text) + INTERVAL '1 day') AT TIME ZONE %[1]s::text
+                ELSE due_at
+            END <= %[2]s`, loc.String(), q.Now)
+	}
+
+	direction, comparison := "DESC", "<"
+	if q.Order == domain.SortAsc {
+		direction, comparison = "ASC", ">"
+	}
+
+	if q.Cursor != "" {
+		cursor, err := domain.DecodeTodoCursor(q.Cursor, q)
+		if err != nil {
+			return "", nil, err
+		}
+		b.add(fmt.Sprintf("(%s, id) %s (%%s::%s, %%s)", sort.expr, comparison, sort.cast), cursor.Value, cursor.ID)
+	}
+
+	query := `SELECT ` + todoColumns + `
+        FROM todos
+        WHERE ` + strings.Join(b.conditions, " AND ") + `
+        ORDER BY ` + sort.expr + ` ` + direction + `, id ` + direction
+
+	if q.Limit > 0 {
+		b.args = append(b.args, q.Limit+1)
+		query += fmt.Sprintf(" LIMIT $%d", len(b.args))
+	}
+
+	return query, b.args, nil
+}
+
+// escapeLike escapes the ILIKE wildcards in user input.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
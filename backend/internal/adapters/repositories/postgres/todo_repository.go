@@ -7,6 +7,7 @@ import (
 	"errors"
 	"time"
 	"fmt"
+	"strings"
 
 	"github.com/ChaiyawutTar/MyList/internal/core/domain"
 )
@@ -15,189 +16,315 @@ type todoRepository struct {
 	db *sql.DB
 }
 
-func (r *todoRepository) verifyDatabaseSetup(ctx context.Context) error {
-    // 1. Check database connection
-    if err := r.db.PingContext(ctx); err != nil {
-        return fmt.Errorf("database connection error: %w", err)
-    }
+// todoColumns is the column list scanTodo expects, in order.
+const todoColumns = `id, user_id, COALESCE(list_id, 0), title, description, status, COALESCE(image_id, ''), start_at, due_at,
+    COALESCE(recurrence_rule, ''), series_id, COALESCE(recurrence_index, 0), auto_complete, completed_at, priority, position, archived_at, deleted_at, version, created_at, updated_at`
 
-    // 2. Verify table exists and has correct structure
-    verifyQuery := `
-        SELECT column_name 
-        FROM information_schema.columns 
-        WHERE table_name = 'todos' 
-        ORDER BY ordinal_position;`
+// scanTodo reads a row selected with todoColumns.
+func scanTodo(row interface{ Scan(dest ...interface{}) error }) (*domain.Todo, error) {
+	var todo domain.Todo
+	var startAt, dueAt, completedAt, archivedAt, deletedAt sql.NullTime
+	var seriesID sql.NullInt64
+	var priority int
+	err := row.Scan(
+		&todo.ID,
+		&todo.UserID,
+		&todo.ListID,
+		&todo.Title,
+		&todo.Description,
+		&todo.Status,
+		&todo.ImageID,
+		&startAt,
+		&dueAt,
+		&todo.RecurrenceRule,
+		&seriesID,
+		&todo.RecurrenceIndex,
+		&todo.AutoComplete,
+		&completedAt,
+		&priority,
+		&todo.Position,
+		&archivedAt,
+		&deletedAt,
+		&todo.Version,
+		&todo.CreatedAt,
+		&todo.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
 
-    rows, err := r.db.QueryContext(ctx, verifyQuery)
-    if err != nil {
-        return fmt.Errorf("error verifying table structure: %w", err)
-    }
-    defer rows.Close()
-
-    columns := make([]string, 0)
-    for rows.Next() {
-        var columnName string
-        if err := rows.Scan(&columnName); err != nil {
-            return fmt.Errorf("error scanning column name: %w", err)
-        }
-        columns = append(columns, columnName)
-    }
+	todo.StartAt = nullTimePtr(startAt)
+	todo.DueAt = nullTimePtr(dueAt)
+	todo.CompletedAt = nullTimePtr(completedAt)
+	todo.Priority = domain.PriorityName(priority)
+	todo.ArchivedAt = nullTimePtr(archivedAt)
+	todo.DeletedAt = nullTimePtr(deletedAt)
+	todo.SeriesID = int(seriesID.Int64)
+	return &todo, nil
+}
 
-    fmt.Printf("Found columns in todos table: %v\n", columns)
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
 
-    // 3. Verify we can execute a simple query
-    testQuery := `SELECT COUNT(*) FROM todos WHERE user_id = $1;`
-    var count int
-    if err := r.db.QueryRowContext(ctx, testQuery, 1).Scan(&count); err != nil {
-        return fmt.Errorf("error executing test query: %w", err)
-    }
+// priorityLevel stores a priority as its level; the service has already
+// rejected unknown names.
+func priorityLevel(priority string) int {
+	level, _ := domain.PriorityLevel(priority)
+	return level
+}
 
-    return nil
+// nullIfZero stores 0 as NULL, for optional references.
+func nullIfZero(id int) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
 }
 
 func NewTodoRepository(db *sql.DB) *todoRepository {
-    repo := &todoRepository{db: db}
-    
-    // Verify database setup
-    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-    defer cancel()
-    
-    if err := repo.verifyDatabaseSetup(ctx); err != nil {
-        panic(fmt.Sprintf("database setup verification failed: %v", err))
-    }
-    
-    return repo
+    return &todoRepository{db: db}
 }
 
+func (r *todoRepository) FindAll(ctx context.Context, q domain.TodoQuery) (*domain.TodoPage, error) {
+	query, args, err := buildTodoQuery(q)
+	if err != nil {
+		return nil, err
+	}
 
-func (r *todoRepository) FindAll(ctx context.Context, userID int) ([]domain.Todo, error) {
-    // 1. First, let's verify the table structure
-    verifyQuery := `SELECT COUNT(*) FROM information_schema.columns WHERE table_name = 'todos';`
-    var columnCount int
-    err := r.db.QueryRowContext(ctx, verifyQuery).Scan(&columnCount)
-    if err != nil {
-        return nil, fmt.Errorf("error verifying table structure: %w", err)
-    }
-    fmt.Printf("Number of columns in todos table: %d\n", columnCount)
-
-    // 2. Use a simpler query first to debug
-    query := `
-        SELECT id, user_id, title, description, status, COALESCE(image_id, ''), created_at, updated_at 
-        FROM todos 
-        WHERE user_id = $1 
-        ORDER BY created_at DESC`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying todos: %w", err)
+	}
+	defer rows.Close()
 
-    // 3. Print the query and userID for debugging
-    fmt.Printf("Executing query: %s with userID: %d\n", query, userID)
+	todos := make([]domain.Todo, 0)
+	for rows.Next() {
+		todo, err := scanTodo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning todo: %w", err)
+		}
+		todos = append(todos, *todo)
+	}
 
-    // 4. Execute the query
-    rows, err := r.db.QueryContext(ctx, query, userID)
-    if err != nil {
-        return nil, fmt.Errorf("error querying todos: %w", err)
-    }
-    defer rows.Close()
-
-    // 5. Initialize the slice with capacity
-    todos := make([]domain.Todo, 0)
-
-    // 6. Iterate over rows
-    for rows.Next() {
-        var todo domain.Todo
-        err := rows.Scan(
-            &todo.ID,
-            &todo.UserID,
-            &todo.Title,
-            &todo.Description,
-            &todo.Status,
-            &todo.ImageID,
-            &todo.CreatedAt,
-            &todo.UpdatedAt,
-        )
-        if err != nil {
-            return nil, fmt.Errorf("error scanning todo: %w", err)
-        }
-        todos = append(todos, todo)
-    }
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
 
-    // 7. Check for errors from iterating over rows
-    if err = rows.Err(); err != nil {
-        return nil, fmt.Errorf("error iterating rows: %w", err)
-    }
+	page := &domain.TodoPage{Todos: todos}
+
+	// The extra row only tells us there is another page
+	if q.Limit > 0 && len(todos) > q.Limit {
+		page.Todos = todos[:q.Limit]
+		last := &page.Todos[q.Limit-1]
+		page.NextCursor = domain.TodoCursor{
+			Sort:  q.Sort,
+			Order: q.Order,
+			Value: todoSorts[q.Sort].value(last),
+			ID:    last.ID,
+		}.Encode()
+	}
 
-    return todos, nil
+	return page, nil
 }
+
 func (r *todoRepository) FindByID(ctx context.Context, id int) (*domain.Todo, error) {
-	query := `SELECT id, user_id, title, description, status, image_id, created_at, updated_at 
+	query := `SELECT ` + todoColumns + `
               FROM todos 
               WHERE id = $1`
 
-	var todo domain.Todo
-	var imageID sql.NullString
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&todo.ID,
-		&todo.UserID,
-		&todo.Title,
-		&todo.Description,
-		&todo.Status,
-		&imageID,
-		&todo.CreatedAt,
-		&todo.UpdatedAt,
-	)
-
+	todo, err := scanTodo(conn(ctx, r.db).QueryRowContext(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("todo not found")
+			return nil, domain.ErrTodoNotFound
 		}
 		return nil, err
 	}
 
-	if imageID.Valid {
-		todo.ImageID = imageID.String
+	return todo, nil
+}
+
+func (r *todoRepository) FindSeries(ctx context.Context, seriesID int) ([]domain.Todo, error) {
+	query := `SELECT ` + todoColumns + `
+              FROM todos 
+              WHERE id = $1 OR series_id = $1
+              ORDER BY recurrence_index, id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, seriesID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying series: %w", err)
 	}
+	defer rows.Close()
 
-	return &todo, nil
+	todos := make([]domain.Todo, 0)
+	for rows.Next() {
+		todo, err := scanTodo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning todo: %w", err)
+		}
+		todos = append(todos, *todo)
+	}
+
+	return todos, rows.Err()
+}
+
+func (r *todoRepository) FindTrashedBefore(ctx context.Context, before time.Time, limit int) ([]domain.Todo, error) {
+	query := `SELECT ` + todoColumns + `
+              FROM todos
+              WHERE deleted_at < $1
+              ORDER BY deleted_at, id
+              LIMIT $2`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying trashed todos: %w", err)
+	}
+	defer rows.Close()
+
+	todos := make([]domain.Todo, 0)
+	for rows.Next() {
+		todo, err := scanTodo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning todo: %w", err)
+		}
+		todos = append(todos, *todo)
+	}
+
+	return todos, rows.Err()
+}
+
+func (r *todoRepository) ArchiveCompleted(ctx context.Context, userID, listID int, completedBefore time.Time) (int, error) {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	conditions := []string{"completed_at < $2", "archived_at IS NULL", "deleted_at IS NULL"}
+	args := []interface{}{now, completedBefore}
+	if userID != 0 {
+		args = append(args, userID)
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if listID != 0 {
+		args = append(args, listID)
+		conditions = append(conditions, fmt.Sprintf("list_id = $%d", len(args)))
+	}
+
+	// Every archived todo gets the same revision
+	changes, err := domain.DiffTodos(&domain.Todo{}, &domain.Todo{ArchivedAt: &now})
+	if err != nil {
+		return 0, err
+	}
+	archived, err := insertBulkRevisions(ctx, tx,
+		`UPDATE todos SET archived_at = $1, version = version + 1 WHERE `+strings.Join(conditions, " AND ")+` RETURNING id, user_id`,
+		args, changes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive todos: %w", err)
+	}
+
+	return archived, tx.Commit()
 }
 
 func (r *todoRepository) Create(ctx context.Context, todo *domain.Todo) error {
-    query := `INSERT INTO todos (user_id, title, description, status, image_id, created_at, updated_at) 
-              VALUES ($1, $2, $3, $4, $5, $6, $7) 
-              RETURNING id`
+    query := `INSERT INTO todos (user_id, list_id, title, description, status, image_id, start_at, due_at,
+                  recurrence_rule, series_id, recurrence_index, auto_complete, completed_at, priority, position, created_at, updated_at) 
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17) 
+              RETURNING id, version`
 
     now := time.Now()
     todo.CreatedAt = now
     todo.UpdatedAt = now
 
-    err := r.db.QueryRowContext(
+    tx, err := beginTx(ctx, r.db)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    err = tx.QueryRowContext(
         ctx,
         query,
         todo.UserID,
+        nullIfZero(todo.ListID),
         todo.Title,
         todo.Description,
         todo.Status,
         todo.ImageID,
+        todo.StartAt,
+        todo.DueAt,
+        todo.RecurrenceRule,
+        nullIfZero(todo.SeriesID),
+        todo.RecurrenceIndex,
+        todo.AutoComplete,
+        todo.CompletedAt,
+        priorityLevel(todo.Priority),
+        todo.Position,
         todo.CreatedAt,
         todo.UpdatedAt,
-    ).Scan(&todo.ID)
+    ).Scan(&todo.ID, &todo.Version)
+    if err != nil {
+        return err
+    }
 
-    return err
+    if err := insertRevision(ctx, tx, domain.RevisionCreate, nil, todo); err != nil {
+        return err
+    }
+    return tx.Commit()
 }
 
 func (r *todoRepository) Update(ctx context.Context, todo *domain.Todo) error {
     query := `UPDATE todos 
-              SET title = $1, description = $2, status = $3, image_id = $4, updated_at = $5 
-              WHERE id = $6`
+              SET list_id = $1, title = $2, description = $3, status = $4, image_id = $5, start_at = $6, due_at = $7,
+                  recurrence_rule = $8, series_id = $9, recurrence_index = $10, auto_complete = $11, completed_at = $12,
+                  priority = $13, position = $14, archived_at = $15, deleted_at = $16, updated_at = $17,
+                  version = version + 1
+              WHERE id = $18 AND version = $19`
 
     todo.UpdatedAt = time.Now()
 
-    result, err := r.db.ExecContext(
+    tx, err := beginTx(ctx, r.db)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    // Lock the current row to diff against
+    old, err := scanTodo(tx.QueryRowContext(ctx,
+        `SELECT `+todoColumns+` FROM todos WHERE id = $1 FOR UPDATE`, todo.ID))
+    if errors.Is(err, sql.ErrNoRows) {
+        return domain.ErrTodoNotFound
+    }
+    if err != nil {
+        return err
+    }
+
+    result, err := tx.ExecContext(
         ctx,
         query,
+        nullIfZero(todo.ListID),
         todo.Title,
         todo.Description,
         todo.Status,
         todo.ImageID,
+        todo.StartAt,
+        todo.DueAt,
+        todo.RecurrenceRule,
+        nullIfZero(todo.SeriesID),
+        todo.RecurrenceIndex,
+        todo.AutoComplete,
+        todo.CompletedAt,
+        priorityLevel(todo.Priority),
+        todo.Position,
+        todo.ArchivedAt,
+        todo.DeletedAt,
         todo.UpdatedAt,
         todo.ID,
+        todo.Version,
     )
     if err != nil {
         return err
@@ -209,30 +336,37 @@ func (r *todoRepository) Update(ctx context.Context, todo *domain.Todo) error {
         return err
     }
     
+    // The row is locked, so it exists but was changed since it was read
     if rowsAffected == 0 {
-        return errors.New("todo not found")
+        return domain.ErrVersionConflict
     }
-    
-    return nil
+    todo.Version++
+
+    if err := insertRevision(ctx, tx, domain.RevisionUpdate, old, todo); err != nil {
+        return err
+    }
+    return tx.Commit()
 }
 
 func (r *todoRepository) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM todos WHERE id = $1`
-	
-	result, err := r.db.ExecContext(ctx, query, id)
+	query := `DELETE FROM todos WHERE id = $1 RETURNING ` + todoColumns
+
+	tx, err := beginTx(ctx, r.db)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	
-	// Check if any row was affected
-	rowsAffected, err := result.RowsAffected()
+	defer tx.Rollback()
+
+	old, err := scanTodo(tx.QueryRowContext(ctx, query, id))
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrTodoNotFound
+		}
 		return err
 	}
-	
-	if rowsAffected == 0 {
-		return errors.New("todo not found")
+
+	if err := insertRevision(ctx, tx, domain.RevisionDelete, old, nil); err != nil {
+		return err
 	}
-	
-	return nil
+	return tx.Commit()
 }
\ No newline at end of file
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+// todoRevisionRepository reads the history of todos. Revisions are written
+// by todoRepository, in the same transaction as the change they record.
+type todoRevisionRepository struct {
+	db *sql.DB
+}
+
+func NewTodoRevisionRepository(db *sql.DB) *todoRevisionRepository {
+	return &todoRevisionRepository{db: db}
+}
+
+const todoRevisionColumns = `id, todo_id, revision, user_id, COALESCE(actor_id, 0), action, changes, created_at`
+
+func scanTodoRevision(row interface{ Scan(dest ...interface{}) error }) (*domain.TodoRevision, error) {
+	var revision domain.TodoRevision
+	var changes []byte
+	err := row.Scan(&revision.ID, &revision.TodoID, &revision.Revision, &revision.UserID,
+		&revision.ActorID, &revision.Action, &changes, &revision.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(changes, &revision.Changes); err != nil {
+		return nil, fmt.Errorf("error decoding revision changes: %w", err)
+	}
+	return &revision, nil
+}
+
+// FindByTodoID returns every revision of the todo, oldest first.
+func (r *todoRevisionRepository) FindByTodoID(ctx context.Context, todoID int) ([]domain.TodoRevision, error) {
+	query := `SELECT ` + todoRevisionColumns + `
+              FROM todo_revisions
+              WHERE todo_id = $1
+              ORDER BY revision`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying todo revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := make([]domain.TodoRevision, 0)
+	for rows.Next() {
+		revision, err := scanTodoRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning todo revision: %w", err)
+		}
+		revisions = append(revisions, *revision)
+	}
+	return revisions, rows.Err()
+}
+
+// insertRevision records a change to todo within tx. old is nil for a
+// created todo and new is nil for a deleted one; an update that changes no
+// tracked field is not recorded.
+func insertRevision(ctx context.Context, tx queryer, action string, old, new *domain.Todo) error {
+	changes, err := domain.DiffTodos(old, new)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	todo := new
+	if todo == nil {
+		todo = old
+	}
+
+	query := `INSERT INTO todo_revisions (todo_id, revision, user_id, actor_id, action, changes, created_at)
+              SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4, $5, $6
+              FROM todo_revisions
+              WHERE todo_id = $1`
+
+	_, err = tx.ExecContext(ctx, query, todo.ID, todo.UserID, nullIfZero(domain.ActorFromContext(ctx)),
+		action, encoded, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record todo revision: %w", err)
+	}
+	return nil
+}
+
+// insertBulkRevisions records the same change for every todo returned by
+// the data-modifying statement changed, which must be a CTE body returning
+// id and user_id. args are the arguments of changed; the revision arguments
+// follow them.
+func insertBulkRevisions(ctx context.Context, tx queryer, changed string, args []interface{}, changes map[string]domain.FieldChange) (int, error) {
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return 0, err
+	}
+
+	n := len(args)
+	query := fmt.Sprintf(`WITH changed AS (%s)
+              INSERT INTO todo_revisions (todo_id, revision, user_id, actor_id, action, changes, created_at)
+              SELECT c.id,
+                     (SELECT COALESCE(MAX(r.revision), 0) + 1 FROM todo_revisions r WHERE r.todo_id = c.id),
+                     c.user_id, $%d, $%d, $%d, $%d
+              FROM changed c`, changed, n+1, n+2, n+3, n+4)
+	args = append(args, nullIfZero(domain.ActorFromContext(ctx)), domain.RevisionUpdate, encoded, time.Now())
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
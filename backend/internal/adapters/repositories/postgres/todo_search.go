@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+// todoSearcher searches the generated todos.search_vector column (GIN
+// indexed) and falls back to pg_trgm word similarity on the title, backed
+// by todos_title_trgm_idx, so that a misspelt word still finds its todo.
+type todoSearcher struct {
+	db *sql.DB
+}
+
+func NewTodoSearcher(db *sql.DB) *todoSearcher {
+	return &todoSearcher{db: db}
+}
+
+// minTitleSimilarity is the word_similarity a title needs to match a query
+// that has no full-text hit. It is set as pg_trgm.word_similarity_threshold
+// for the search, since only the <% operator can use the trigram index.
+const minTitleSimilarity = 0.4
+
+// ts_headline marks matches with these control characters rather than with
+// <mark>, so that the text can be HTML-escaped before the marks become
+// tags. They are stripped from the text first, so every one is a mark.
+const (
+	markStart = "\x01"
+	markStop  = "\x02"
+)
+
+var markReplacer = strings.NewReplacer(markStart, "<mark>", markStop, "</mark>")
+
+const headlineOptions = `StartSel=` + markStart + `, StopSel=` + markStop + `, HighlightAll=true`
+const descriptionHeadlineOptions = `StartSel=` + markStart + `, StopSel=` + markStop + `, MaxFragments=2, MaxWords=20, MinWords=5, FragmentDelimiter=" … "`
+
+// unmarked is a column with the mark characters removed.
+func unmarked(column string) string {
+	return `translate(` + column + `, E'\x01\x02', '')`
+}
+
+func (s *todoSearcher) Search(ctx context.Context, q domain.SearchQuery) ([]domain.SearchResult, error) {
+	tsquery := prefixTSQuery(q.Text)
+
+	args := []interface{}{q.UserID, tsquery, q.Text}
+	listFilter := ""
+	if q.ListID != 0 {
+		args = append(args, q.ListID)
+		listFilter = fmt.Sprintf(" AND list_id = $%d", len(args))
+	}
+	args = append(args, q.Limit)
+
+	query := `WITH q AS (
+                  SELECT CASE WHEN $2 = '' THEN NULL ELSE to_tsquery('english', $2) END AS query
+              )
+              SELECT ` + todoColumns + `,
+                  GREATEST(COALESCE(ts_rank_cd(search_vector, q.query), 0), word_similarity($3, title)) AS rank,
+                  COALESCE(ts_headline('english', ` + unmarked("title") + `, q.query, '` + headlineOptions + `'), ` + unmarked("title") + `),
+                  COALESCE(ts_headline('english', ` + unmarked("description") + `, q.query, '` + descriptionHeadlineOptions + `'), ` + unmarked("description") + `)
+              FROM todos, q
+              WHERE user_id = $1 AND deleted_at IS NULL AND archived_at IS NULL` + listFilter + `
+                AND (search_vector @@ q.query OR $3 <% title)
+              ORDER BY rank DESC, id DESC
+              LIMIT $` + fmt.Sprint(len(args))
+
+	// The threshold only holds for this transaction
+	tx, err := beginTx(ctx, s.db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	_, err = tx.ExecContext(ctx, `SET LOCAL pg_trgm.word_similarity_threshold = `+fmt.Sprint(minTitleSimilarity))
+	if err != nil {
+		return nil, fmt.Errorf("error searching todos: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error searching todos: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]domain.SearchResult, 0)
+	for rows.Next() {
+		var result domain.SearchResult
+		todo, err := scanTodo(rowWithExtras{rows, []interface{}{
+			&result.Rank, &result.Highlights.Title, &result.Highlights.Description,
+		}})
+		if err != nil {
+			return nil, fmt.Errorf("error scanning search result: %w", err)
+		}
+		result.Todo = *todo
+		result.Highlights.Title = highlight(result.Highlights.Title)
+		result.Highlights.Description = highlight(result.Highlights.Description)
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, tx.Commit()
+}
+
+// highlight HTML-escapes a headline and turns its marks into <mark> tags.
+func highlight(headline string) string {
+	return markReplacer.Replace(html.EscapeString(headline))
+}
+
+// rowWithExtras lets scanTodo read a row that has extra columns after the
+// todo columns.
+type rowWithExtras struct {
+	row    interface{ Scan(dest ...interface{}) error }
+	extras []interface{}
+}
+
+func (r rowWithExtras) Scan(dest ...interface{}) error {
+	return r.row.Scan(append(dest, r.extras...)...)
+}
+
+// prefixTSQuery turns free text into a to_tsquery expression in which every
+// word must match as a prefix, e.g. "buy mil" becomes "buy:* & mil:*". Only
+// letters and digits survive, so the result is always valid tsquery syntax.
+func prefixTSQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
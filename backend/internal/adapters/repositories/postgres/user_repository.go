@@ -29,11 +29,11 @@ func (r *userRepository) Create(ctx context.Context, user *domain.User, password
     }
 
     // Insert user into database
-    query := `INSERT INTO users (username, email, password_hash, oauth_provider, oauth_provider_id, created_at)
-    VALUES ($1, $2, $3, $4, $5, $6)
+    query := `INSERT INTO users (username, email, password_hash, oauth_provider, oauth_provider_id, timezone, email_verified_at, created_at)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
     RETURNING id`
 
-    err := r.db.QueryRowContext(
+    err := conn(ctx, r.db).QueryRowContext(
         ctx,
         query,
         user.Username,
@@ -41,80 +41,97 @@ func (r *userRepository) Create(ctx context.Context, user *domain.User, password
         hashedPassword,
         user.OAuthProvider,
         user.OAuthProviderID,
+        user.Timezone,
+        user.EmailVerifiedAt,
         time.Now(),
     ).Scan(&user.ID)
+    if isUniqueViolation(err) {
+        return domain.Conflict("user_exists", "a user with this email or username already exists")
+    }
 
     return err
 }
 
 func (r *userRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
-	query := `SELECT id, username, email, password_hash, created_at 
+	query := `SELECT id, username, email, password_hash, COALESCE(timezone, ''), email_verified_at, created_at 
               FROM users 
               WHERE email = $1`
 
 	var user domain.User
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
+	var emailVerifiedAt sql.NullTime
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.PasswordHash,
+		&user.Timezone,
+		&emailVerifiedAt,
 		&user.CreatedAt,
 	)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("user not found")
+			return nil, domain.ErrUserNotFound
 		}
 		return nil, err
 	}
+	user.EmailVerifiedAt = nullTimePtr(emailVerifiedAt)
 
 	return &user, nil
 }
 
 func (r *userRepository) FindByID(ctx context.Context, id int) (*domain.User, error) {
-	query := `SELECT id, username, email, password_hash, created_at 
+	query := `SELECT id, username, email, password_hash, COALESCE(timezone, ''), email_verified_at, created_at 
               FROM users 
               WHERE id = $1`
 
 	var user domain.User
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	var emailVerifiedAt sql.NullTime
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.PasswordHash,
+		&user.Timezone,
+		&emailVerifiedAt,
 		&user.CreatedAt,
 	)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("user not found")
+			return nil, domain.ErrUserNotFound
 		}
 		return nil, err
 	}
+	user.EmailVerifiedAt = nullTimePtr(emailVerifiedAt)
 
 	return &user, nil
 }
 
 func (r *userRepository) FindByOAuthID(ctx context.Context, provider, providerID string) (*domain.User, error) {
-	query := `SELECT id, username, email, password_hash, created_at
+	query := `SELECT id, username, email, password_hash, COALESCE(timezone, ''), email_verified_at, created_at
 	FROM users
 	WHERE oauth_provider = $1 AND oauth_provider_id = $2`
 
 	var user domain.User
-	err := r.db.QueryRowContext(ctx, query, provider, providerID).Scan(
+	var emailVerifiedAt sql.NullTime
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, provider, providerID).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.PasswordHash,
+		&user.Timezone,
+		&emailVerifiedAt,
 		&user.CreatedAt,
 	)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("user not found")
+			return nil, domain.ErrUserNotFound
 		}
 		return nil, err
 	}
+	user.EmailVerifiedAt = nullTimePtr(emailVerifiedAt)
 
 	return &user, nil
 }
@@ -124,7 +141,7 @@ func (r *userRepository) UpdateOAuthInfo(ctx context.Context, user *domain.User)
               SET oauth_provider = $1, oauth_provider_id = $2
               WHERE id = $3`
 
-    _, err := r.db.ExecContext(
+    _, err := conn(ctx, r.db).ExecContext(
         ctx,
         query,
         user.OAuthProvider,
@@ -133,4 +150,69 @@ func (r *userRepository) UpdateOAuthInfo(ctx context.Context, user *domain.User)
     )
 
     return err
-}
\ No newline at end of file
+}
+
+func (r *userRepository) UpdateTimezone(ctx context.Context, userID int, timezone string) error {
+	query := `UPDATE users SET timezone = $1 WHERE id = $2`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, timezone, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, userID int, password string) error {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE users SET password_hash = $1 WHERE id = $2`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, string(hashed), userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepository) MarkEmailVerified(ctx context.Context, userID int, at time.Time) error {
+	query := `UPDATE users SET email_verified_at = COALESCE(email_verified_at, $1) WHERE id = $2`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, at, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
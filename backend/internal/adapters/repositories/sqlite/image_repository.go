@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+)
+
+// imageRepository stores images as BLOBs in the images table.
+type imageRepository struct {
+	db *sql.DB
+}
+
+func NewImageRepository(db *sql.DB) ports.ImageRepository {
+	return &imageRepository{db: db}
+}
+
+func (r *imageRepository) Save(ctx context.Context, file multipart.File, filename string) (string, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	query := `INSERT INTO images (filename, data, content_type, created_at) VALUES ($1, $2, $3, $4) RETURNING id`
+
+	var id int
+	err = conn(ctx, r.db).QueryRowContext(ctx, query, filename, data, http.DetectContentType(data), time.Now()).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("failed to insert image: %w", err)
+	}
+
+	return strconv.Itoa(id), nil
+}
+
+func (r *imageRepository) Delete(ctx context.Context, imageID string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM images WHERE id = $1`, imageID)
+	if err != nil {
+		return fmt.Errorf("failed to delete image: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrImageNotFound
+	}
+
+	return nil
+}
+
+func (r *imageRepository) Get(ctx context.Context, imageID string) ([]byte, string, error) {
+	id, err := strconv.Atoi(imageID)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid image ID: %w", err)
+	}
+
+	var data []byte
+	var contentType sql.NullString
+	query := `SELECT data, content_type FROM images WHERE id = $1`
+	err = conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(&data, &contentType)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", domain.ErrImageNotFound
+		}
+		return nil, "", fmt.Errorf("error querying image: %w", err)
+	}
+
+	if len(data) == 0 {
+		return nil, "", fmt.Errorf("image data is empty for ID: %s", imageID)
+	}
+
+	if !contentType.Valid || contentType.String == "" {
+		return data, http.DetectContentType(data), nil
+	}
+	return data, contentType.String, nil
+}
@@ -0,0 +1,22 @@
+package sqlite
+
+import (
+	"database/sql"
+	"embed"
+	"io/fs"
+
+	"github.com/ChaiyawutTar/MyList/pkg/migrate"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// NewMigrator returns a migrator for the SQLite schema. A database file has
+// one writer at a time, so migrators need no lock of their own.
+func NewMigrator(db *sql.DB) (*migrate.Migrator, error) {
+	files, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(db, files, nil)
+}
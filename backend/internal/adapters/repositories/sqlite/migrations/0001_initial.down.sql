@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS images;
+DROP TABLE IF EXISTS todo_revisions;
+DROP TABLE IF EXISTS checklist_items;
+DROP TABLE IF EXISTS todo_tags;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS todos;
+DROP TABLE IF EXISTS workflows;
+DROP TABLE IF EXISTS lists;
+DROP TABLE IF EXISTS users;
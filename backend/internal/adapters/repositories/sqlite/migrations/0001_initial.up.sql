@@ -0,0 +1,123 @@
+-- The schema of Postgres migrations 0001 and 0002, in SQLite terms. Times
+-- are TIMESTAMP so the driver reads them back as times, JSON is TEXT, and
+-- search needs no columns of its own.
+
+CREATE TABLE users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username TEXT UNIQUE NOT NULL,
+    email TEXT UNIQUE NOT NULL,
+    password_hash TEXT NOT NULL,
+    oauth_provider TEXT,
+    oauth_provider_id TEXT,
+    timezone TEXT,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE lists (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    is_inbox BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX lists_one_inbox_idx ON lists (user_id) WHERE is_inbox;
+
+CREATE TABLE workflows (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    list_id INTEGER REFERENCES lists(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    statuses TEXT NOT NULL,
+    transitions TEXT NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- One default workflow per user (list_id NULL) and at most one per list
+CREATE UNIQUE INDEX workflows_user_list_idx ON workflows (user_id, COALESCE(list_id, 0));
+
+CREATE TABLE todos (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    list_id INTEGER REFERENCES lists(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    description TEXT,
+    status TEXT DEFAULT 'pending',
+    image_id TEXT,
+    start_at TIMESTAMP,
+    due_at TIMESTAMP,
+    recurrence_rule TEXT,
+    series_id INTEGER REFERENCES todos(id) ON DELETE SET NULL,
+    recurrence_index INTEGER,
+    auto_complete BOOLEAN NOT NULL DEFAULT FALSE,
+    completed_at TIMESTAMP,
+    priority INTEGER NOT NULL DEFAULT 0,
+    -- The default BINARY collation compares ranks byte by byte
+    position TEXT NOT NULL DEFAULT '',
+    archived_at TIMESTAMP,
+    deleted_at TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX todos_user_idx ON todos (user_id);
+CREATE INDEX todos_series_idx ON todos (series_id);
+CREATE INDEX todos_list_position_idx ON todos (list_id, position);
+CREATE INDEX todos_deleted_at_idx ON todos (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX todos_archived_idx ON todos (user_id, archived_at) WHERE archived_at IS NOT NULL;
+CREATE INDEX todos_completed_at_idx ON todos (completed_at) WHERE archived_at IS NULL AND deleted_at IS NULL;
+
+CREATE TABLE tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    color TEXT,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- LOWER folds ASCII letters only, so other scripts are case-sensitive
+CREATE UNIQUE INDEX tags_user_name_idx ON tags (user_id, LOWER(name));
+
+CREATE TABLE todo_tags (
+    todo_id INTEGER REFERENCES todos(id) ON DELETE CASCADE,
+    tag_id INTEGER REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (todo_id, tag_id)
+);
+
+CREATE INDEX todo_tags_tag_idx ON todo_tags (tag_id);
+
+CREATE TABLE checklist_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    todo_id INTEGER REFERENCES todos(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    done BOOLEAN NOT NULL DEFAULT FALSE,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX checklist_items_todo_idx ON checklist_items (todo_id, position);
+
+-- One immutable row per change to a todo; kept after the todo is purged
+CREATE TABLE todo_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    todo_id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    action TEXT NOT NULL,
+    changes TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (todo_id, revision)
+);
+
+CREATE TABLE images (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    filename TEXT NOT NULL,
+    data BLOB NOT NULL,
+    content_type TEXT,
+    created_at TIMESTAMP NOT NULL
+);
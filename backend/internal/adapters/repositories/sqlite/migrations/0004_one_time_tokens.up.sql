@@ -0,0 +1,15 @@
+-- Single-use tokens mailed to users, such as password reset links, stored
+-- by hash.
+
+CREATE TABLE one_time_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose TEXT NOT NULL,
+    token_hash TEXT UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX one_time_tokens_user_idx ON one_time_tokens (user_id, purpose);
+CREATE INDEX one_time_tokens_expires_at_idx ON one_time_tokens (expires_at);
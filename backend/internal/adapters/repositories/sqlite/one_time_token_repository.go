@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+type oneTimeTokenRepository struct {
+	db *sql.DB
+}
+
+func NewOneTimeTokenRepository(db *sql.DB) *oneTimeTokenRepository {
+	return &oneTimeTokenRepository{db: db}
+}
+
+const oneTimeTokenColumns = `id, user_id, purpose, token_hash, expires_at, used_at, created_at`
+
+func scanOneTimeToken(row interface{ Scan(dest ...interface{}) error }) (*domain.OneTimeToken, error) {
+	var token domain.OneTimeToken
+	var usedAt sql.NullTime
+	err := row.Scan(&token.ID, &token.UserID, &token.Purpose, &token.TokenHash, &token.ExpiresAt, &usedAt, &token.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrOneTimeTokenNotFound
+		}
+		return nil, err
+	}
+	token.UsedAt = nullTimePtr(usedAt)
+	return &token, nil
+}
+
+func (r *oneTimeTokenRepository) Create(ctx context.Context, token *domain.OneTimeToken) error {
+	query := `INSERT INTO one_time_tokens (user_id, purpose, token_hash, expires_at, created_at)
+              VALUES ($1, $2, $3, $4, $5)
+              RETURNING id`
+
+	token.CreatedAt = time.Now()
+	return conn(ctx, r.db).QueryRowContext(ctx, query, token.UserID, token.Purpose, token.TokenHash,
+		token.ExpiresAt, token.CreatedAt).Scan(&token.ID)
+}
+
+func (r *oneTimeTokenRepository) FindByHash(ctx context.Context, purpose, hash string) (*domain.OneTimeToken, error) {
+	query := `SELECT ` + oneTimeTokenColumns + ` FROM one_time_tokens WHERE token_hash = $1 AND purpose = $2`
+	return scanOneTimeToken(conn(ctx, r.db).QueryRowContext(ctx, query, hash, purpose))
+}
+
+func (r *oneTimeTokenRepository) FindLatest(ctx context.Context, userID int, purpose string) (*domain.OneTimeToken, error) {
+	query := `SELECT ` + oneTimeTokenColumns + ` FROM one_time_tokens
+              WHERE user_id = $1 AND purpose = $2
+              ORDER BY created_at DESC, id DESC
+              LIMIT 1`
+	return scanOneTimeToken(conn(ctx, r.db).QueryRowContext(ctx, query, userID, purpose))
+}
+
+// Consume only updates an unused token. Writes to SQLite are serialized,
+// so of two Consume calls for one token the second finds it used.
+func (r *oneTimeTokenRepository) Consume(ctx context.Context, id int, at time.Time) (bool, error) {
+	query := `UPDATE one_time_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, at, id)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected == 1, nil
+}
+
+func (r *oneTimeTokenRepository) ConsumeAll(ctx context.Context, userID int, purpose string, at time.Time) error {
+	query := `UPDATE one_time_tokens SET used_at = $1 WHERE user_id = $2 AND purpose = $3 AND used_at IS NULL`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, at, userID, purpose)
+	return err
+}
+
+func (r *oneTimeTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	return deleteExpired(ctx, conn(ctx, r.db), `DELETE FROM one_time_tokens WHERE expires_at < $1`, before)
+}
@@ -0,0 +1,132 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+type sessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *sessionRepository {
+	return &sessionRepository{db: db}
+}
+
+const sessionColumns = `id, user_id, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at`
+
+func scanSession(row interface{ Scan(dest ...interface{}) error }) (*domain.Session, error) {
+	var session domain.Session
+	var revokedAt sql.NullTime
+	err := row.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IP,
+		&session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	session.RevokedAt = nullTimePtr(revokedAt)
+	return &session, nil
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	query := `INSERT INTO sessions (id, user_id, user_agent, ip, created_at, last_seen_at, expires_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, session.ID, session.UserID, session.UserAgent, session.IP,
+		session.CreatedAt, session.LastSeenAt, session.ExpiresAt)
+	return err
+}
+
+func (r *sessionRepository) FindByID(ctx context.Context, id string) (*domain.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE id = $1`
+
+	session, err := scanSession(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return session, nil
+}
+
+func (r *sessionRepository) FindActive(ctx context.Context, userID int, now time.Time) ([]domain.Session, error) {
+	query := `SELECT ` + sessionColumns + `
+              FROM sessions
+              WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+              ORDER BY last_seen_at DESC, id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("error querying sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]domain.Session, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning session: %w", err)
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *sessionRepository) Extend(ctx context.Context, id string, seenAt, expiresAt time.Time) error {
+	query := `UPDATE sessions SET last_seen_at = MAX(last_seen_at, $1), expires_at = $2 WHERE id = $3`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, seenAt, expiresAt, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	query := `UPDATE sessions SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, at, id)
+	return err
+}
+
+func (r *sessionRepository) Touch(ctx context.Context, lastSeen map[string]time.Time) error {
+	if len(lastSeen) == 0 {
+		return nil
+	}
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// One transaction for the batch; SQLite has no arrays to send it in
+	for id, at := range lastSeen {
+		_, err := tx.ExecContext(ctx,
+			`UPDATE sessions SET last_seen_at = $1 WHERE id = $2 AND last_seen_at < $1`, at, id)
+		if err != nil {
+			return fmt.Errorf("failed to record session activity: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *sessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	return deleteExpired(ctx, conn(ctx, r.db), `DELETE FROM sessions WHERE expires_at < $1`, before)
+}
@@ -0,0 +1,70 @@
+// Package sqlite implements the repository ports on a SQLite database file,
+// for self-hosted deployments that run as a single binary without Postgres.
+//
+// The schema mirrors the Postgres one. Where SQLite lacks a feature the
+// adapter makes up for it: arrays are passed as JSON and read with
+// json_each, times are stored as UTC text so that they compare in order,
+// and search ranks candidates with textmatch instead of a full-text index.
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// timeFormat is how the driver writes times (_time_format=sqlite). Values
+// compared with time columns, such as cursors, must use it too.
+const timeFormat = "2006-01-02 15:04:05.999999999-07:00"
+
+// Open opens the database file named by databaseURL, such as
+// sqlite:///var/lib/mylist/mylist.db or sqlite:mylist.db for a path
+// relative to the working directory, and creates it if it does not exist.
+// Query parameters are passed on to the driver.
+func Open(databaseURL string) (*sql.DB, error) {
+	path, query, _ := strings.Cut(strings.TrimPrefix(strings.TrimPrefix(databaseURL, "sqlite:"), "//"), "?")
+	if path == "" {
+		return nil, errors.New("sqlite: DATABASE_URL names no database file")
+	}
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Set("_time_format", "sqlite")
+
+	db, err := sql.Open("sqlite", path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	// SQLite has a single writer. One connection queues writers in Go
+	// rather than failing them with SQLITE_BUSY, at the cost of also
+	// serializing reads.
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// jsonArray encodes a slice for json_each, which stands in for Postgres
+// arrays.
+func jsonArray(values interface{}) string {
+	encoded, _ := json.Marshal(values)
+	return string(encoded)
+}
+
+// isUniqueViolation reports whether err is a SQLite UNIQUE or PRIMARY KEY
+// constraint failure.
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code()
+	return code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
@@ -0,0 +1,343 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+type todoRepository struct {
+	db *sql.DB
+}
+
+// todoColumns is the column list scanTodo expects, in order. Time columns
+// are selected bare so that the driver sees their TIMESTAMP type and reads
+// them as times.
+const todoColumns = `id, user_id, COALESCE(list_id, 0), title, COALESCE(description, ''), status, COALESCE(image_id, ''), start_at, due_at,
+    COALESCE(recurrence_rule, ''), series_id, COALESCE(recurrence_index, 0), auto_complete, completed_at, priority, position, archived_at, deleted_at, version, created_at, updated_at`
+
+// scanTodo reads a row selected with todoColumns.
+func scanTodo(row interface{ Scan(dest ...interface{}) error }) (*domain.Todo, error) {
+	var todo domain.Todo
+	var startAt, dueAt, completedAt, archivedAt, deletedAt sql.NullTime
+	var seriesID sql.NullInt64
+	var priority int
+	err := row.Scan(
+		&todo.ID,
+		&todo.UserID,
+		&todo.ListID,
+		&todo.Title,
+		&todo.Description,
+		&todo.Status,
+		&todo.ImageID,
+		&startAt,
+		&dueAt,
+		&todo.RecurrenceRule,
+		&seriesID,
+		&todo.RecurrenceIndex,
+		&todo.AutoComplete,
+		&completedAt,
+		&priority,
+		&todo.Position,
+		&archivedAt,
+		&deletedAt,
+		&todo.Version,
+		&todo.CreatedAt,
+		&todo.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	todo.StartAt = nullTimePtr(startAt)
+	todo.DueAt = nullTimePtr(dueAt)
+	todo.CompletedAt = nullTimePtr(completedAt)
+	todo.Priority = domain.PriorityName(priority)
+	todo.ArchivedAt = nullTimePtr(archivedAt)
+	todo.DeletedAt = nullTimePtr(deletedAt)
+	todo.SeriesID = int(seriesID.Int64)
+	return &todo, nil
+}
+
+// queryTodos runs a query that selects todoColumns.
+func queryTodos(ctx context.Context, q queryer, query string, args ...interface{}) ([]domain.Todo, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying todos: %w", err)
+	}
+	defer rows.Close()
+
+	todos := make([]domain.Todo, 0)
+	for rows.Next() {
+		todo, err := scanTodo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning todo: %w", err)
+		}
+		todos = append(todos, *todo)
+	}
+
+	return todos, rows.Err()
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// priorityLevel stores a priority as its level; the service has already
+// rejected unknown names.
+func priorityLevel(priority string) int {
+	level, _ := domain.PriorityLevel(priority)
+	return level
+}
+
+// nullIfZero stores 0 as NULL, for optional references.
+func nullIfZero(id int) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+func NewTodoRepository(db *sql.DB) *todoRepository {
+	return &todoRepository{db: db}
+}
+
+func (r *todoRepository) FindAll(ctx context.Context, q domain.TodoQuery) (*domain.TodoPage, error) {
+	query, args, err := buildTodoQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	todos, err := queryTodos(ctx, conn(ctx, r.db), query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.TodoPage{Todos: todos}
+
+	// The extra row only tells us there is another page
+	if q.Limit > 0 && len(todos) > q.Limit {
+		page.Todos = todos[:q.Limit]
+		last := &page.Todos[q.Limit-1]
+		page.NextCursor = domain.TodoCursor{
+			Sort:  q.Sort,
+			Order: q.Order,
+			Value: todoSorts[q.Sort].value(last),
+			ID:    last.ID,
+		}.Encode()
+	}
+
+	return page, nil
+}
+
+func (r *todoRepository) FindByID(ctx context.Context, id int) (*domain.Todo, error) {
+	return findTodo(ctx, conn(ctx, r.db), id)
+}
+
+func findTodo(ctx context.Context, q queryer, id int) (*domain.Todo, error) {
+	todo, err := scanTodo(q.QueryRowContext(ctx, `SELECT `+todoColumns+` FROM todos WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTodoNotFound
+		}
+		return nil, err
+	}
+	return todo, nil
+}
+
+func (r *todoRepository) FindSeries(ctx context.Context, seriesID int) ([]domain.Todo, error) {
+	query := `SELECT ` + todoColumns + `
+              FROM todos
+              WHERE id = $1 OR series_id = $1
+              ORDER BY recurrence_index, id`
+
+	return queryTodos(ctx, conn(ctx, r.db), query, seriesID)
+}
+
+func (r *todoRepository) FindTrashedBefore(ctx context.Context, before time.Time, limit int) ([]domain.Todo, error) {
+	query := `SELECT ` + todoColumns + `
+              FROM todos
+              WHERE deleted_at < $1
+              ORDER BY deleted_at, id
+              LIMIT $2`
+
+	return queryTodos(ctx, conn(ctx, r.db), query, before, limit)
+}
+
+func (r *todoRepository) ArchiveCompleted(ctx context.Context, userID, listID int, completedBefore time.Time) (int, error) {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	conditions := []string{"completed_at < $2", "archived_at IS NULL", "deleted_at IS NULL"}
+	args := []interface{}{now, completedBefore}
+	if userID != 0 {
+		args = append(args, userID)
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if listID != 0 {
+		args = append(args, listID)
+		conditions = append(conditions, fmt.Sprintf("list_id = $%d", len(args)))
+	}
+
+	// Every archived todo gets the same revision
+	changes, err := domain.DiffTodos(&domain.Todo{}, &domain.Todo{ArchivedAt: &now})
+	if err != nil {
+		return 0, err
+	}
+	archived, err := insertBulkRevisions(ctx, tx,
+		`UPDATE todos SET archived_at = $1, version = version + 1 WHERE `+strings.Join(conditions, " AND ")+` RETURNING id, user_id`,
+		args, changes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive todos: %w", err)
+	}
+
+	return archived, tx.Commit()
+}
+
+func (r *todoRepository) Create(ctx context.Context, todo *domain.Todo) error {
+	query := `INSERT INTO todos (user_id, list_id, title, description, status, image_id, start_at, due_at,
+                  recurrence_rule, series_id, recurrence_index, auto_complete, completed_at, priority, position, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+              RETURNING id, version`
+
+	now := time.Now()
+	todo.CreatedAt = now
+	todo.UpdatedAt = now
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		todo.UserID,
+		nullIfZero(todo.ListID),
+		todo.Title,
+		todo.Description,
+		todo.Status,
+		todo.ImageID,
+		todo.StartAt,
+		todo.DueAt,
+		todo.RecurrenceRule,
+		nullIfZero(todo.SeriesID),
+		todo.RecurrenceIndex,
+		todo.AutoComplete,
+		todo.CompletedAt,
+		priorityLevel(todo.Priority),
+		todo.Position,
+		todo.CreatedAt,
+		todo.UpdatedAt,
+	).Scan(&todo.ID, &todo.Version)
+	if err != nil {
+		return err
+	}
+
+	if err := insertRevision(ctx, tx, domain.RevisionCreate, nil, todo); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *todoRepository) Update(ctx context.Context, todo *domain.Todo) error {
+	query := `UPDATE todos
+              SET list_id = $1, title = $2, description = $3, status = $4, image_id = $5, start_at = $6, due_at = $7,
+                  recurrence_rule = $8, series_id = $9, recurrence_index = $10, auto_complete = $11, completed_at = $12,
+                  priority = $13, position = $14, archived_at = $15, deleted_at = $16, updated_at = $17,
+                  version = version + 1
+              WHERE id = $18 AND version = $19`
+
+	todo.UpdatedAt = time.Now()
+
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The database has a single writer, so the row cannot change between
+	// reading it and updating it
+	old, err := findTodo(ctx, tx, todo.ID)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		nullIfZero(todo.ListID),
+		todo.Title,
+		todo.Description,
+		todo.Status,
+		todo.ImageID,
+		todo.StartAt,
+		todo.DueAt,
+		todo.RecurrenceRule,
+		nullIfZero(todo.SeriesID),
+		todo.RecurrenceIndex,
+		todo.AutoComplete,
+		todo.CompletedAt,
+		priorityLevel(todo.Priority),
+		todo.Position,
+		todo.ArchivedAt,
+		todo.DeletedAt,
+		todo.UpdatedAt,
+		todo.ID,
+		todo.Version,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	// The row exists but was changed since the caller read it
+	if rowsAffected == 0 {
+		return domain.ErrVersionConflict
+	}
+	todo.Version++
+
+	if err := insertRevision(ctx, tx, domain.RevisionUpdate, old, todo); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *todoRepository) Delete(ctx context.Context, id int) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	old, err := findTodo(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM todos WHERE id = $1`, id); err != nil {
+		return err
+	}
+
+	if err := insertRevision(ctx, tx, domain.RevisionDelete, old, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
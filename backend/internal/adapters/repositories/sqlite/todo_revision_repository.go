@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+// todoRevisionRepository reads the history of todos. Revisions are written
+// by todoRepository, in the same transaction as the change they record.
+type todoRevisionRepository struct {
+	db *sql.DB
+}
+
+func NewTodoRevisionRepository(db *sql.DB) *todoRevisionRepository {
+	return &todoRevisionRepository{db: db}
+}
+
+const todoRevisionColumns = `id, todo_id, revision, user_id, COALESCE(actor_id, 0), action, changes, created_at`
+
+func scanTodoRevision(row interface{ Scan(dest ...interface{}) error }) (*domain.TodoRevision, error) {
+	var revision domain.TodoRevision
+	var changes []byte
+	err := row.Scan(&revision.ID, &revision.TodoID, &revision.Revision, &revision.UserID,
+		&revision.ActorID, &revision.Action, &changes, &revision.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(changes, &revision.Changes); err != nil {
+		return nil, fmt.Errorf("error decoding revision changes: %w", err)
+	}
+	return &revision, nil
+}
+
+// FindByTodoID returns every revision of the todo, oldest first.
+func (r *todoRevisionRepository) FindByTodoID(ctx context.Context, todoID int) ([]domain.TodoRevision, error) {
+	query := `SELECT ` + todoRevisionColumns + `
+              FROM todo_revisions
+              WHERE todo_id = $1
+              ORDER BY revision`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying todo revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := make([]domain.TodoRevision, 0)
+	for rows.Next() {
+		revision, err := scanTodoRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning todo revision: %w", err)
+		}
+		revisions = append(revisions, *revision)
+	}
+	return revisions, rows.Err()
+}
+
+// insertRevision records a change to todo within tx. old is nil for a
+// created todo and new is nil for a deleted one; an update that changes no
+// tracked field is not recorded.
+func insertRevision(ctx context.Context, tx queryer, action string, old, new *domain.Todo) error {
+	changes, err := domain.DiffTodos(old, new)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	todo := new
+	if todo == nil {
+		todo = old
+	}
+	return insertRevisionRow(ctx, tx, todo.ID, todo.UserID, action, encoded)
+}
+
+// insertBulkRevisions runs changed, an UPDATE of todos returning id and
+// user_id, with args and records the same change for every todo it
+// updated. SQLite has no data-modifying CTEs, so the revisions are
+// inserted one by one after the update.
+func insertBulkRevisions(ctx context.Context, tx queryer, changed string, args []interface{}, changes map[string]domain.FieldChange) (int, error) {
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.QueryContext(ctx, changed, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var todos []domain.Todo
+	for rows.Next() {
+		var todo domain.Todo
+		if err := rows.Scan(&todo.ID, &todo.UserID); err != nil {
+			return 0, err
+		}
+		todos = append(todos, todo)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	rows.Close()
+
+	for _, todo := range todos {
+		if err := insertRevisionRow(ctx, tx, todo.ID, todo.UserID, domain.RevisionUpdate, encoded); err != nil {
+			return 0, err
+		}
+	}
+	return len(todos), nil
+}
+
+func insertRevisionRow(ctx context.Context, tx queryer, todoID, userID int, action string, changes []byte) error {
+	query := `INSERT INTO todo_revisions (todo_id, revision, user_id, actor_id, action, changes, created_at)
+              SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4, $5, $6
+              FROM todo_revisions
+              WHERE todo_id = $1`
+
+	_, err := tx.ExecContext(ctx, query, todoID, userID, nullIfZero(domain.ActorFromContext(ctx)),
+		action, string(changes), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record todo revision: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/pkg/textmatch"
+)
+
+// todoSearcher ranks the user's todos with textmatch, which approximates
+// the Postgres full-text search without stemming. It reads every candidate
+// todo, which suits the size of a self-hosted database.
+type todoSearcher struct {
+	db *sql.DB
+}
+
+func NewTodoSearcher(db *sql.DB) *todoSearcher {
+	return &todoSearcher{db: db}
+}
+
+func (s *todoSearcher) Search(ctx context.Context, q domain.SearchQuery) ([]domain.SearchResult, error) {
+	args := []interface{}{q.UserID}
+	listFilter := ""
+	if q.ListID != 0 {
+		args = append(args, q.ListID)
+		listFilter = " AND list_id = $2"
+	}
+
+	query := `SELECT ` + todoColumns + `
+              FROM todos
+              WHERE user_id = $1 AND deleted_at IS NULL AND archived_at IS NULL` + listFilter
+
+	todos, err := queryTodos(ctx, conn(ctx, s.db), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error searching todos: %w", err)
+	}
+
+	results := make([]domain.SearchResult, 0)
+	for _, todo := range todos {
+		rank, ok := textmatch.Rank(q.Text, todo.Title, todo.Description)
+		if !ok {
+			continue
+		}
+		results = append(results, domain.SearchResult{
+			Todo: todo,
+			Rank: rank,
+			Highlights: domain.SearchHighlight{
+				Title:       textmatch.Highlight(todo.Title, q.Text),
+				Description: textmatch.Highlight(todo.Description, q.Text),
+			},
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Todo.ID > results[j].Todo.ID
+	})
+	if len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+	return results, nil
+}
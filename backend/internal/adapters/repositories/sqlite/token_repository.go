@@ -0,0 +1,142 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+type refreshTokenRepository struct {
+	db *sql.DB
+}
+
+func NewRefreshTokenRepository(db *sql.DB) *refreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+const refreshTokenColumns = `id, user_id, family_id, token_hash, access_token_id, access_expires_at,
+              expires_at, used_at, revoked_at, created_at`
+
+func scanRefreshToken(row interface{ Scan(dest ...interface{}) error }) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	var usedAt, revokedAt sql.NullTime
+	err := row.Scan(&token.ID, &token.UserID, &token.FamilyID, &token.TokenHash, &token.AccessTokenID,
+		&token.AccessExpiresAt, &token.ExpiresAt, &usedAt, &revokedAt, &token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	token.UsedAt = nullTimePtr(usedAt)
+	token.RevokedAt = nullTimePtr(revokedAt)
+	return &token, nil
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (user_id, family_id, token_hash, access_token_id, access_expires_at, expires_at, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7)
+              RETURNING id`
+
+	token.CreatedAt = time.Now()
+	return conn(ctx, r.db).QueryRowContext(ctx, query, token.UserID, token.FamilyID, token.TokenHash,
+		token.AccessTokenID, token.AccessExpiresAt, token.ExpiresAt, token.CreatedAt).Scan(&token.ID)
+}
+
+func (r *refreshTokenRepository) FindByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	query := `SELECT ` + refreshTokenColumns + ` FROM refresh_tokens WHERE token_hash = $1`
+
+	token, err := scanRefreshToken(conn(ctx, r.db).QueryRowContext(ctx, query, hash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *refreshTokenRepository) FindByFamily(ctx context.Context, familyID string) ([]domain.RefreshToken, error) {
+	query := `SELECT ` + refreshTokenColumns + ` FROM refresh_tokens WHERE family_id = $1 ORDER BY id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying refresh tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := make([]domain.RefreshToken, 0)
+	for rows.Next() {
+		token, err := scanRefreshToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning refresh token: %w", err)
+		}
+		tokens = append(tokens, *token)
+	}
+	return tokens, rows.Err()
+}
+
+// MarkUsed only updates a live token. Writes to SQLite are serialized, so
+// of two MarkUsed calls for one token the second finds it used.
+func (r *refreshTokenRepository) MarkUsed(ctx context.Context, id int, at time.Time) (bool, error) {
+	query := `UPDATE refresh_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL AND revoked_at IS NULL`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, at, id)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected == 1, nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	query := `UPDATE refresh_tokens SET revoked_at = $1 WHERE family_id = $2 AND revoked_at IS NULL`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, at, familyID)
+	return err
+}
+
+func (r *refreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	return deleteExpired(ctx, conn(ctx, r.db), `DELETE FROM refresh_tokens WHERE expires_at < $1`, before)
+}
+
+type revokedTokenRepository struct {
+	db *sql.DB
+}
+
+func NewRevokedTokenRepository(db *sql.DB) *revokedTokenRepository {
+	return &revokedTokenRepository{db: db}
+}
+
+func (r *revokedTokenRepository) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	query := `INSERT OR IGNORE INTO revoked_tokens (token_id, expires_at) VALUES ($1, $2)`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, tokenID, expiresAt)
+	return err
+}
+
+func (r *revokedTokenRepository) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	var revoked bool
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE token_id = $1)`, tokenID).
+		Scan(&revoked)
+	return revoked, err
+}
+
+func (r *revokedTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	return deleteExpired(ctx, conn(ctx, r.db), `DELETE FROM revoked_tokens WHERE expires_at < $1`, before)
+}
+
+// deleteExpired runs a DELETE with the cutoff time and returns how many
+// rows it removed.
+func deleteExpired(ctx context.Context, q queryer, query string, before time.Time) (int, error) {
+	result, err := q.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	return int(rowsAffected), err
+}
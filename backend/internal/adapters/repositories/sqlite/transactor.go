@@ -0,0 +1,148 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// txKey marks the transaction repositories should run in.
+type txKey struct{}
+
+type txState struct {
+	tx    *sql.Tx
+	depth int
+}
+
+// transactor lets services group repository calls into one transaction.
+// Repositories pick the transaction up from the context through conn and
+// beginTx.
+type transactor struct {
+	db *sql.DB
+}
+
+func NewTransactor(db *sql.DB) *transactor {
+	return &transactor{db: db}
+}
+
+// WithinTx runs fn in a transaction and commits it if fn returns nil. Inside
+// another WithinTx it runs fn under a savepoint instead, so a failing fn
+// only undoes its own changes.
+func (t *transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return withinSavepoint(ctx, state, fn)
+	}
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, &txState{tx: tx})); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func withinSavepoint(ctx context.Context, state *txState, fn func(ctx context.Context) error) error {
+	name := fmt.Sprintf("sp_%d", state.depth+1)
+	if _, err := state.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+
+	inner := &txState{tx: state.tx, depth: state.depth + 1}
+	if err := fn(context.WithValue(ctx, txKey{}, inner)); err != nil {
+		if _, rbErr := state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return fmt.Errorf("%w (rolling back to savepoint also failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	_, err := state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
+// queryer is what *sql.DB and *sql.Tx have in common.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// inUTC binds times in UTC. SQLite stores times as text, which only sorts
+// and compares in time order when every value has the same offset.
+type inUTC struct {
+	q queryer
+}
+
+func (u inUTC) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return u.q.ExecContext(ctx, query, utcArgs(args)...)
+}
+
+func (u inUTC) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return u.q.QueryContext(ctx, query, utcArgs(args)...)
+}
+
+func (u inUTC) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return u.q.QueryRowContext(ctx, query, utcArgs(args)...)
+}
+
+func utcArgs(args []interface{}) []interface{} {
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch t := arg.(type) {
+		case time.Time:
+			arg = t.UTC()
+		case *time.Time:
+			if t != nil {
+				arg = t.UTC()
+			}
+		}
+		converted[i] = arg
+	}
+	return converted
+}
+
+// conn returns the transaction of ctx, if there is one, or else db.
+func conn(ctx context.Context, db *sql.DB) queryer {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return inUTC{state.tx}
+	}
+	return inUTC{db}
+}
+
+// txn is the transaction a repository method runs in. When ctx already
+// carries a transaction the method joins it and leaves committing or
+// rolling back to its owner.
+type txn struct {
+	inUTC
+	tx    *sql.Tx
+	owned bool
+}
+
+func beginTx(ctx context.Context, db *sql.DB) (*txn, error) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return &txn{inUTC: inUTC{state.tx}, tx: state.tx}, nil
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &txn{inUTC: inUTC{tx}, tx: tx, owned: true}, nil
+}
+
+func (t *txn) Commit() error {
+	if !t.owned {
+		return nil
+	}
+	return t.tx.Commit()
+}
+
+func (t *txn) Rollback() error {
+	if !t.owned {
+		return nil
+	}
+	return t.tx.Rollback()
+}
@@ -0,0 +1,145 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type userRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *userRepository {
+	return &userRepository{db: db}
+}
+
+const userColumns = `id, username, email, password_hash, COALESCE(timezone, ''), email_verified_at, created_at`
+
+func scanUser(row interface{ Scan(dest ...interface{}) error }) (*domain.User, error) {
+	var user domain.User
+	var emailVerifiedAt sql.NullTime
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.Timezone, &emailVerifiedAt, &user.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	user.EmailVerifiedAt = nullTimePtr(emailVerifiedAt)
+	return &user, nil
+}
+
+func (r *userRepository) Create(ctx context.Context, user *domain.User, password string) error {
+	// Hash password if provided
+	var hashedPassword string
+	if password != "" {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		hashedPassword = string(hashed)
+	}
+
+	query := `INSERT INTO users (username, email, password_hash, oauth_provider, oauth_provider_id, timezone, email_verified_at, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+              RETURNING id`
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, user.Username, user.Email, hashedPassword,
+		user.OAuthProvider, user.OAuthProviderID, user.Timezone, user.EmailVerifiedAt, time.Now()).Scan(&user.ID)
+	if isUniqueViolation(err) {
+		return domain.Conflict("user_exists", "a user with this email or username already exists")
+	}
+	return err
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
+	return scanUser(conn(ctx, r.db).QueryRowContext(ctx, query, email))
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id int) (*domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	return scanUser(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+}
+
+func (r *userRepository) FindByOAuthID(ctx context.Context, provider, providerID string) (*domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE oauth_provider = $1 AND oauth_provider_id = $2`
+	return scanUser(conn(ctx, r.db).QueryRowContext(ctx, query, provider, providerID))
+}
+
+func (r *userRepository) UpdateOAuthInfo(ctx context.Context, user *domain.User) error {
+	query := `UPDATE users SET oauth_provider = $1, oauth_provider_id = $2 WHERE id = $3`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, user.OAuthProvider, user.OAuthProviderID, user.ID)
+	return err
+}
+
+func (r *userRepository) UpdateTimezone(ctx context.Context, userID int, timezone string) error {
+	query := `UPDATE users SET timezone = $1 WHERE id = $2`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, timezone, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, userID int, password string) error {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE users SET password_hash = $1 WHERE id = $2`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, string(hashed), userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *userRepository) MarkEmailVerified(ctx context.Context, userID int, at time.Time) error {
+	query := `UPDATE users SET email_verified_at = COALESCE(email_verified_at, $1) WHERE id = $2`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, at, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
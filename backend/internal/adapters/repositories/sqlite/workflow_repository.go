@@ -0,0 +1,184 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+type workflowRepository struct {
+	db *sql.DB
+}
+
+func NewWorkflowRepository(db *sql.DB) *workflowRepository {
+	return &workflowRepository{db: db}
+}
+
+// Statuses and transitions are stored as JSON text; they are always read
+// and written together with their workflow.
+const workflowColumns = `id, user_id, COALESCE(list_id, 0), name, statuses, transitions, created_at, updated_at`
+
+func scanWorkflow(row interface{ Scan(dest ...interface{}) error }) (*domain.Workflow, error) {
+	var workflow domain.Workflow
+	var statuses, transitions []byte
+	err := row.Scan(&workflow.ID, &workflow.UserID, &workflow.ListID, &workflow.Name,
+		&statuses, &transitions, &workflow.CreatedAt, &workflow.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(statuses, &workflow.Statuses); err != nil {
+		return nil, fmt.Errorf("error decoding workflow statuses: %w", err)
+	}
+	if err := json.Unmarshal(transitions, &workflow.Transitions); err != nil {
+		return nil, fmt.Errorf("error decoding workflow transitions: %w", err)
+	}
+	return &workflow, nil
+}
+
+func encodeWorkflow(workflow *domain.Workflow) ([]byte, []byte, error) {
+	statuses, err := json.Marshal(workflow.Statuses)
+	if err != nil {
+		return nil, nil, err
+	}
+	transitions := workflow.Transitions
+	if transitions == nil {
+		transitions = []domain.WorkflowTransition{}
+	}
+	encoded, err := json.Marshal(transitions)
+	if err != nil {
+		return nil, nil, err
+	}
+	return statuses, encoded, nil
+}
+
+func (r *workflowRepository) FindAll(ctx context.Context, userID int) ([]domain.Workflow, error) {
+	query := `SELECT ` + workflowColumns + `
+              FROM workflows
+              WHERE user_id = $1
+              ORDER BY list_id NULLS FIRST, name`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying workflows: %w", err)
+	}
+	defer rows.Close()
+
+	workflows := make([]domain.Workflow, 0)
+	for rows.Next() {
+		workflow, err := scanWorkflow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning workflow: %w", err)
+		}
+		workflows = append(workflows, *workflow)
+	}
+
+	return workflows, rows.Err()
+}
+
+func (r *workflowRepository) FindByID(ctx context.Context, id int) (*domain.Workflow, error) {
+	query := `SELECT ` + workflowColumns + ` FROM workflows WHERE id = $1`
+
+	workflow, err := scanWorkflow(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrWorkflowNotFound
+		}
+		return nil, err
+	}
+
+	return workflow, nil
+}
+
+func (r *workflowRepository) FindEffective(ctx context.Context, userID, listID int) (*domain.Workflow, error) {
+	// A list's own workflow sorts before the user's default
+	query := `SELECT ` + workflowColumns + `
+              FROM workflows
+              WHERE user_id = $1 AND (list_id = $2 OR list_id IS NULL)
+              ORDER BY list_id NULLS LAST
+              LIMIT 1`
+
+	workflow, err := scanWorkflow(conn(ctx, r.db).QueryRowContext(ctx, query, userID, listID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return workflow, nil
+}
+
+func (r *workflowRepository) Create(ctx context.Context, workflow *domain.Workflow) error {
+	query := `INSERT INTO workflows (user_id, list_id, name, statuses, transitions, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7)
+              RETURNING id`
+
+	statuses, transitions, err := encodeWorkflow(workflow)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	workflow.CreatedAt = now
+	workflow.UpdatedAt = now
+
+	err = conn(ctx, r.db).QueryRowContext(ctx, query, workflow.UserID, nullIfZero(workflow.ListID), workflow.Name,
+		string(statuses), string(transitions), workflow.CreatedAt, workflow.UpdatedAt).Scan(&workflow.ID)
+	if isUniqueViolation(err) {
+		if workflow.ListID == 0 {
+			return domain.Conflict("workflow_exists", "a default workflow already exists")
+		}
+		return domain.Conflict("workflow_exists", "this list already has a workflow")
+	}
+	return err
+}
+
+func (r *workflowRepository) Update(ctx context.Context, workflow *domain.Workflow) error {
+	query := `UPDATE workflows SET name = $1, statuses = $2, transitions = $3, updated_at = $4 WHERE id = $5`
+
+	statuses, transitions, err := encodeWorkflow(workflow)
+	if err != nil {
+		return err
+	}
+
+	workflow.UpdatedAt = time.Now()
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, workflow.Name, string(statuses), string(transitions), workflow.UpdatedAt, workflow.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrWorkflowNotFound
+	}
+
+	return nil
+}
+
+func (r *workflowRepository) Delete(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM workflows WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrWorkflowNotFound
+	}
+
+	return nil
+}
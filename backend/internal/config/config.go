@@ -7,10 +7,54 @@ import (
 	"github.com/spf13/viper"
 )
 
+// Mailers the server can send email with.
+const (
+	MailerLog  = "log"  // messages are written to MAIL_LOG_FILE, or stderr
+	MailerSMTP = "smtp"
+)
+
+// Storage backends the server can run on.
+const (
+	StorageDatabase = "database" // the scheme of DATABASE_URL picks Postgres or SQLite
+	StorageMemory   = "memory"   // nothing survives a restart
+)
+
 type Config struct {
+	// Storage selects the repository adapters; DatabaseURL is only read
+	// for StorageDatabase.
+	Storage          string
 	DatabaseURL      string
 	JWTSecret        string
-	JWTExpiry        time.Duration
+	// AccessTokenTTL is how long an access token is valid; RefreshTokenTTL
+	// is how long a session may sit idle before it has to log in again.
+	AccessTokenTTL     time.Duration
+	RefreshTokenTTL    time.Duration
+	TokenSweepInterval time.Duration
+	// LastSeenInterval is how often the last-seen times of sessions are
+	// written. TrustProxy takes client IPs from X-Forwarded-For and
+	// X-Real-IP, for running behind a reverse proxy.
+	LastSeenInterval time.Duration
+	TrustProxy       bool
+	// PasswordResetTTL is how long a password reset link works; a user is
+	// sent at most one every PasswordResetInterval.
+	PasswordResetTTL      time.Duration
+	PasswordResetInterval time.Duration
+	// EmailVerificationTTL is how long an email verification link works;
+	// a user can ask for a new one every VerificationResendInterval.
+	// RequireVerifiedEmail keeps users who have not verified their email
+	// from changing anything but their account.
+	EmailVerificationTTL       time.Duration
+	VerificationResendInterval time.Duration
+	RequireVerifiedEmail       bool
+	// Mailer selects how email is sent; the SMTP settings are only read
+	// for MailerSMTP and MailLogFile only for MailerLog.
+	Mailer       string
+	MailFrom     string
+	MailLogFile  string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
 	ServerPort       string
 	AllowedOrigins   []string
 	AllowCredentials bool
@@ -19,6 +63,16 @@ type Config struct {
     OAuthCallbackURL string
 	FrontendURL string
 	SessionSecret string
+	// TrashRetention is how long deleted todos stay restorable; 0 keeps
+	// them until the trash is emptied by hand.
+	TrashRetention     time.Duration
+	TrashSweepInterval time.Duration
+	// ArchiveAfter is how long a todo stays complete before it is archived
+	// automatically; 0 turns automatic archiving off.
+	ArchiveAfter    time.Duration
+	ArchiveInterval time.Duration
+	// MigrateOnStart applies pending migrations before serving.
+	MigrateOnStart bool
 }
 
 func LoadConfig() *Config {
@@ -28,6 +82,7 @@ func LoadConfig() *Config {
 	_ = viper.ReadInConfig() // No log, no panic
 
 	// Set default values if not provided
+	viper.SetDefault("STORAGE", StorageDatabase)
 	viper.SetDefault("DATABASE_URL", "")
 	viper.SetDefault("PORT", "8080")
 	viper.SetDefault("FRONTEND_URL", "http://localhost:3000")
@@ -35,6 +90,24 @@ func LoadConfig() *Config {
     viper.SetDefault("GOOGLE_CLIENT_SECRET", "")
     viper.SetDefault("OAUTH_CALLBACK_URL", "http://localhost:8080/auth/google/callback")
 	viper.SetDefault("SESSION_SECRET","")
+	viper.SetDefault("ACCESS_TOKEN_TTL", "15m")
+	viper.SetDefault("REFRESH_TOKEN_TTL", "720h")
+	viper.SetDefault("TOKEN_SWEEP_INTERVAL", "1h")
+	viper.SetDefault("LAST_SEEN_INTERVAL", "1m")
+	viper.SetDefault("TRUST_PROXY", false)
+	viper.SetDefault("PASSWORD_RESET_TTL", "1h")
+	viper.SetDefault("PASSWORD_RESET_INTERVAL", "1m")
+	viper.SetDefault("EMAIL_VERIFICATION_TTL", "48h")
+	viper.SetDefault("VERIFICATION_RESEND_INTERVAL", "1m")
+	viper.SetDefault("REQUIRE_VERIFIED_EMAIL", false)
+	viper.SetDefault("MAILER", MailerLog)
+	viper.SetDefault("MAIL_FROM", "MyList <no-reply@localhost>")
+	viper.SetDefault("SMTP_PORT", "587")
+	viper.SetDefault("TRASH_RETENTION", "720h")
+	viper.SetDefault("TRASH_SWEEP_INTERVAL", "1h")
+	viper.SetDefault("ARCHIVE_AFTER_DAYS", 0)
+	viper.SetDefault("ARCHIVE_INTERVAL", "1h")
+	viper.SetDefault("MIGRATE_ON_START", true)
 
 
 	originsStr := viper.GetString("ALLOWED_ORIGINS")
@@ -51,9 +124,26 @@ func LoadConfig() *Config {
     }
 
 	return &Config{
+		Storage:          viper.GetString("STORAGE"),
 		DatabaseURL:      viper.GetString("DATABASE_URL"),
 		JWTSecret:        viper.GetString("JWT_SECRET"),
-		JWTExpiry:        24 * time.Hour,
+		AccessTokenTTL:     viper.GetDuration("ACCESS_TOKEN_TTL"),
+		RefreshTokenTTL:    viper.GetDuration("REFRESH_TOKEN_TTL"),
+		TokenSweepInterval: viper.GetDuration("TOKEN_SWEEP_INTERVAL"),
+		LastSeenInterval:   viper.GetDuration("LAST_SEEN_INTERVAL"),
+		TrustProxy:         viper.GetBool("TRUST_PROXY"),
+		PasswordResetTTL:   viper.GetDuration("PASSWORD_RESET_TTL"),
+		PasswordResetInterval: viper.GetDuration("PASSWORD_RESET_INTERVAL"),
+		EmailVerificationTTL:       viper.GetDuration("EMAIL_VERIFICATION_TTL"),
+		VerificationResendInterval: viper.GetDuration("VERIFICATION_RESEND_INTERVAL"),
+		RequireVerifiedEmail:       viper.GetBool("REQUIRE_VERIFIED_EMAIL"),
+		Mailer:             viper.GetString("MAILER"),
+		MailFrom:           viper.GetString("MAIL_FROM"),
+		MailLogFile:        viper.GetString("MAIL_LOG_FILE"),
+		SMTPHost:           viper.GetString("SMTP_HOST"),
+		SMTPPort:           viper.GetString("SMTP_PORT"),
+		SMTPUsername:       viper.GetString("SMTP_USERNAME"),
+		SMTPPassword:       viper.GetString("SMTP_PASSWORD"),
 		ServerPort:       viper.GetString("PORT"),
 		AllowedOrigins:   []string{viper.GetString("FRONTEND_URL")},
 		AllowCredentials: true,
@@ -62,5 +152,10 @@ func LoadConfig() *Config {
         OAuthCallbackURL:   viper.GetString("OAUTH_CALLBACK_URL"),
 		FrontendURL: viper.GetString("FRONTEND_URL"),
 		SessionSecret: viper.GetString("SESSION_SECRET"),
+		TrashRetention:     viper.GetDuration("TRASH_RETENTION"),
+		TrashSweepInterval: viper.GetDuration("TRASH_SWEEP_INTERVAL"),
+		ArchiveAfter:       time.Duration(viper.GetInt("ARCHIVE_AFTER_DAYS")) * 24 * time.Hour,
+		ArchiveInterval:    viper.GetDuration("ARCHIVE_INTERVAL"),
+		MigrateOnStart:     viper.GetBool("MIGRATE_ON_START"),
 	}
 }
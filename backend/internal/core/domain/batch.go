@@ -0,0 +1,61 @@
+package domain
+
+// Batch operations on todos.
+const (
+	BatchUpdateStatus = "update_status" // set Status
+	BatchDelete       = "delete"        // move to the trash
+	BatchMove         = "move"          // move to ListID
+	BatchTag          = "tag"           // add Tags and remove RemoveTags
+)
+
+// Batch modes.
+const (
+	BatchAtomic     = "atomic"      // all items are applied or none is
+	BatchBestEffort = "best_effort" // failing items are skipped
+)
+
+// Batch item outcomes.
+const (
+	BatchItemOK         = "ok"
+	BatchItemFailed     = "failed"
+	BatchItemRolledBack = "rolled_back" // succeeded, but an atomic batch failed
+	BatchItemSkipped    = "skipped"     // not tried after an atomic batch failed
+)
+
+// MaxBatchItems caps the number of todo IDs across all operations of a batch.
+const MaxBatchItems = 200
+
+// BatchOperation applies one operation to each of IDs.
+type BatchOperation struct {
+	Op         string   `json:"op"`
+	IDs        []int    `json:"ids"`
+	Status     string   `json:"status,omitempty"`
+	ListID     int      `json:"list_id,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	RemoveTags []string `json:"remove_tags,omitempty"`
+}
+
+// BatchRequest runs its operations in order in one transaction. Mode
+// defaults to BatchAtomic.
+type BatchRequest struct {
+	Mode       string           `json:"mode"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchItemResult is the outcome of one operation on one todo. Todo is the
+// todo as it was left, for successful items that did not delete it. Err is
+// why a failed item failed; adapters decide how much of it to show.
+type BatchItemResult struct {
+	Op     string `json:"op"`
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+	Err    error  `json:"-"`
+	Todo   *Todo  `json:"todo,omitempty"`
+}
+
+// BatchResult lists one result per operation and ID, in request order.
+type BatchResult struct {
+	Mode      string            `json:"mode"`
+	Committed bool              `json:"committed"`
+	Results   []BatchItemResult `json:"results"`
+}
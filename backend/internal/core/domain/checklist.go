@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// ChecklistItem is a single tickable step inside a todo.
+type ChecklistItem struct {
+	ID        int       `json:"id"`
+	TodoID    int       `json:"todo_id"`
+	Text      string    `json:"text"`
+	Done      bool      `json:"done"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ChecklistProgress counts the checklist items of a todo.
+type ChecklistProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// Complete reports whether there are items and all of them are done.
+func (p ChecklistProgress) Complete() bool {
+	return p.Total > 0 && p.Done == p.Total
+}
+
+type CreateChecklistItemRequest struct {
+	Text string `json:"text"`
+}
+
+// UpdateChecklistItemRequest changes only the fields that are set.
+type UpdateChecklistItemRequest struct {
+	Text *string `json:"text,omitempty"`
+	Done *bool   `json:"done,omitempty"`
+}
+
+// ReorderChecklistRequest lists every item of the checklist in its new order.
+type ReorderChecklistRequest struct {
+	ItemIDs []int `json:"item_ids"`
+}
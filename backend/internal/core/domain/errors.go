@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Kinds of domain errors. Every error a service or repository reports to
+// its caller on purpose is of one of these kinds, so adapters can tell them
+// apart with errors.Is; anything else is an internal failure.
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrForbidden       = errors.New("forbidden")
+	ErrValidation      = errors.New("validation failed")
+	ErrConflict        = errors.New("conflict")
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrThrottled       = errors.New("too many requests")
+)
+
+// Error is a domain error of one Kind. Code is stable and meant for
+// clients to switch on; Message is for humans.
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func NotFound(code, message string) *Error {
+	return &Error{Kind: ErrNotFound, Code: code, Message: message}
+}
+
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: ErrForbidden, Code: code, Message: message}
+}
+
+func Conflict(code, message string) *Error {
+	return &Error{Kind: ErrConflict, Code: code, Message: message}
+}
+
+func Unauthenticated(code, message string) *Error {
+	return &Error{Kind: ErrUnauthenticated, Code: code, Message: message}
+}
+
+// ThrottledError reports a request that came too soon after an earlier
+// one. It is of kind ErrThrottled and may be retried after RetryAfter.
+type ThrottledError struct {
+	Code       string
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return e.Message
+}
+
+func (e *ThrottledError) Unwrap() error {
+	return ErrThrottled
+}
+
+// Errors shared by several services and repositories.
+var (
+	ErrTodoNotFound          = NotFound("todo_not_found", "todo not found")
+	ErrListNotFound          = NotFound("list_not_found", "list not found")
+	ErrTagNotFound           = NotFound("tag_not_found", "tag not found")
+	ErrWorkflowNotFound      = NotFound("workflow_not_found", "workflow not found")
+	ErrChecklistItemNotFound = NotFound("checklist_item_not_found", "checklist item not found")
+	ErrUserNotFound          = NotFound("user_not_found", "user not found")
+	ErrImageNotFound         = NotFound("image_not_found", "image not found")
+	ErrSessionNotFound       = NotFound("session_not_found", "session not found")
+	ErrOneTimeTokenNotFound  = NotFound("token_not_found", "token not found")
+	ErrMFANotFound           = NotFound("mfa_not_found", "MFA is not set up")
+
+	// ErrNotOwner is returned for resources of another user.
+	ErrNotOwner = Forbidden("not_owner", "this resource belongs to another user")
+
+	// ErrEmailNotVerified is returned for actions that need a verified
+	// email address when the user has not verified theirs.
+	ErrEmailNotVerified = Forbidden("email_not_verified", "verify your email address first")
+
+	ErrEmailAlreadyVerified = Conflict("email_already_verified", "your email address is already verified")
+
+	ErrMFAAlreadyEnabled = Conflict("mfa_already_enabled", "MFA is already enabled")
+	ErrMFANotEnabled     = Conflict("mfa_not_enabled", "MFA is not enabled")
+	// ErrMFANotEnrolled is returned for confirming MFA before enrolling.
+	ErrMFANotEnrolled = Conflict("mfa_not_enrolled", "start TOTP enrollment first")
+
+	// ErrVersionConflict reports an update based on a stale version of a todo.
+	ErrVersionConflict = Conflict("version_conflict", "todo was changed by someone else")
+
+	ErrInvalidCredentials  = Unauthenticated("invalid_credentials", "invalid credentials")
+	ErrInvalidToken        = Unauthenticated("unauthenticated", "a valid bearer token is required")
+	ErrTokenRevoked        = Unauthenticated("token_revoked", "this token has been revoked")
+	ErrInvalidRefreshToken = Unauthenticated("invalid_refresh_token", "refresh token is invalid or expired")
+	ErrInvalidMFAToken     = Unauthenticated("invalid_mfa_token", "MFA token is invalid or expired; log in again")
+	ErrInvalidOAuthCode    = Unauthenticated("invalid_oauth_code", "OAuth login code is invalid or expired; log in again")
+
+	// ErrRefreshTokenReused reports a refresh token presented after it was
+	// used up, which logs out every token of its family.
+	ErrRefreshTokenReused = Unauthenticated("refresh_token_reused", "refresh token was already used; its session has been logged out")
+)
+
+// ValidationError reports a request that is well-formed but breaks a
+// domain rule, such as a status change the workflow does not allow. Code
+// is one of the Code* constants; an empty Code reads as CodeInvalid.
+type ValidationError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
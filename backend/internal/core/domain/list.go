@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// InboxListName is the name of the list every user gets by default. Todos
+// created without a list_id land there.
+const InboxListName = "Inbox"
+
+// What happens to the todos of a list that is deleted.
+const (
+	ListDeleteReassign = "reassign" // move them to the inbox
+	ListDeleteCascade  = "cascade"  // delete them along with the list
+)
+
+// List is a project that groups a user's todos.
+type List struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	Name      string    `json:"name"`
+	IsInbox   bool      `json:"is_inbox"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateListRequest struct {
+	Name string `json:"name"`
+}
+
+type UpdateListRequest struct {
+	Name string `json:"name"`
+}
+
+// MoveTodosRequest moves todos into the list named in the URL.
+type MoveTodosRequest struct {
+	TodoIDs []int `json:"todo_ids"`
+}
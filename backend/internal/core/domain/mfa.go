@@ -0,0 +1,82 @@
+package domain
+
+import "time"
+
+// RecoveryCodeCount is how many recovery codes a user gets at a time.
+const RecoveryCodeCount = 10
+
+// MFA is a user's TOTP multi-factor authentication. It is pending from
+// enrollment until the user confirms it with a code, and only then does
+// logging in ask for one.
+type MFA struct {
+	UserID int
+	// Secret is the base32 TOTP secret shared with the authenticator app.
+	Secret    string
+	EnabledAt *time.Time
+	// LastUsedStep is the time step of the last code accepted; codes of
+	// it and earlier steps are refused, so every code works once.
+	LastUsedStep int64
+	// FailedAttempts counts wrong codes since the last success; too many
+	// lock verification until LockedUntil.
+	FailedAttempts int
+	LockedUntil    *time.Time
+	CreatedAt      time.Time
+}
+
+// RecoveryCode is a one-time code that stands in for a TOTP code when the
+// authenticator is lost. Only its hash is stored.
+type RecoveryCode struct {
+	ID        int
+	UserID    int
+	CodeHash  string
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// MFAStatus tells a user whether MFA is on for their account.
+type MFAStatus struct {
+	Enabled           bool       `json:"enabled"`
+	EnabledAt         *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesLeft int        `json:"recovery_codes_left"`
+}
+
+// TOTPEnrollment is what an authenticator app is set up with: the secret,
+// or the otpauth:// URI that a QR code of it encodes.
+type TOTPEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// RecoveryCodes are new recovery codes, shown to the user only once.
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFACodeRequest proves the user holds their second factor, with either a
+// TOTP code or a recovery code.
+type MFACodeRequest struct {
+	Code         string `json:"code,omitempty"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+// MFAChallenge is what logging in returns instead of tokens when the user
+// has MFA on. MFAToken completes the login with POST /auth/mfa/verify.
+type MFAChallenge struct {
+	Status   string `json:"status"` // always "mfa_required"
+	MFAToken string `json:"mfa_token"`
+	// ExpiresIn is how many seconds MFAToken stays valid.
+	ExpiresIn int `json:"expires_in"`
+}
+
+// MFAVerifyRequest completes a login that was challenged for MFA.
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token"`
+	MFACodeRequest
+}
+
+// LoginResult is the outcome of a login: either tokens, or a challenge to
+// complete the login with when the user has MFA on.
+type LoginResult struct {
+	Auth      *AuthResponse
+	Challenge *MFAChallenge
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// Purposes of one-time tokens.
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposeMFAChallenge      = "mfa_challenge"
+	TokenPurposeOAuthLogin        = "oauth_login"
+)
+
+// OneTimeToken is a secret mailed to a user to prove they can read their
+// email, such as a password reset link. Only a hash of it is stored, and
+// it is good for one use before it expires.
+type OneTimeToken struct {
+	ID        int
+	UserID    int
+	Purpose   string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// VerifyEmailRequest verifies an email address with the token of a
+// verification link.
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// ResetPasswordRequest sets a new password with the token of a reset link.
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "fmt"
+
+// Priorities from lowest to highest.
+const (
+	PriorityNone   = "none"
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+var priorities = []string{PriorityNone, PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent}
+
+// PriorityLevel returns the numeric level of a priority, 0 for none up to 4
+// for urgent, which is how priorities are stored and sorted.
+func PriorityLevel(priority string) (int, error) {
+	for level, p := range priorities {
+		if p == priority {
+			return level, nil
+		}
+	}
+	return 0, &ValidationError{Field: "priority", Message: fmt.Sprintf("invalid priority %q, expected none, low, medium, high or urgent", priority)}
+}
+
+// PriorityName is the inverse of PriorityLevel. Unknown levels read as none.
+func PriorityName(level int) string {
+	if level < 0 || level >= len(priorities) {
+		return PriorityNone
+	}
+	return priorities[level]
+}
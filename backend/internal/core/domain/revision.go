@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// Revision actions.
+const (
+	RevisionCreate = "create"
+	RevisionUpdate = "update"
+	RevisionDelete = "delete"
+)
+
+// FieldChange holds the JSON encoded value of a todo field before and after
+// a change. Old is null for a created todo and New is null for a deleted one.
+type FieldChange struct {
+	Old json.RawMessage `json:"old"`
+	New json.RawMessage `json:"new"`
+}
+
+// TodoRevision is the immutable record of one change to a todo. Revisions
+// of a todo are numbered from 1 in the order they were made.
+type TodoRevision struct {
+	ID       int `json:"id"`
+	TodoID   int `json:"todo_id"`
+	Revision int `json:"revision"`
+	UserID   int `json:"user_id"`
+	// ActorID is the user who made the change, or 0 for background jobs.
+	ActorID   int                    `json:"actor_id,omitempty"`
+	Action    string                 `json:"action"`
+	Changes   map[string]FieldChange `json:"changes"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+type actorKey struct{}
+
+// WithActor returns a context that attributes changes made with it to the
+// given user.
+func WithActor(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// ActorFromContext returns the user changes made with ctx are attributed
+// to, or 0 when there is none.
+func ActorFromContext(ctx context.Context) int {
+	userID, _ := ctx.Value(actorKey{}).(int)
+	return userID
+}
+
+// todoField reads and writes one tracked field of a todo as JSON.
+type todoField struct {
+	get func(t *Todo) interface{}
+	set func(t *Todo, raw json.RawMessage) error
+	// revertible fields are restored by RevertTodo. The others are either
+	// derived or refer to data that may be gone, such as deleted images.
+	revertible bool
+}
+
+func valueField(ptr func(t *Todo) interface{}, revertible bool) todoField {
+	return todoField{
+		get: func(t *Todo) interface{} { return ptr(t) },
+		set: func(t *Todo, raw json.RawMessage) error {
+			return json.Unmarshal(raw, ptr(t))
+		},
+		revertible: revertible,
+	}
+}
+
+// timeField compares times at the database's microsecond precision and
+// regardless of their location.
+func timeField(ptr func(t *Todo) **time.Time, revertible bool) todoField {
+	return todoField{
+		get: func(t *Todo) interface{} {
+			if p := *ptr(t); p != nil {
+				return p.UTC().Truncate(time.Microsecond)
+			}
+			return nil
+		},
+		set: func(t *Todo, raw json.RawMessage) error {
+			// Decode into a new value so copies of the todo are untouched
+			var value *time.Time
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return err
+			}
+			*ptr(t) = value
+			return nil
+		},
+		revertible: revertible,
+	}
+}
+
+var todoFields = map[string]todoField{
+	"list_id":          valueField(func(t *Todo) interface{} { return &t.ListID }, true),
+	"title":            valueField(func(t *Todo) interface{} { return &t.Title }, true),
+	"description":      valueField(func(t *Todo) interface{} { return &t.Description }, true),
+	"status":           valueField(func(t *Todo) interface{} { return &t.Status }, true),
+	"image_id":         valueField(func(t *Todo) interface{} { return &t.ImageID }, false),
+	"start_at":         timeField(func(t *Todo) **time.Time { return &t.StartAt }, true),
+	"due_at":           timeField(func(t *Todo) **time.Time { return &t.DueAt }, true),
+	"priority":         valueField(func(t *Todo) interface{} { return &t.Priority }, true),
+	"position":         valueField(func(t *Todo) interface{} { return &t.Position }, false),
+	"recurrence_rule":  valueField(func(t *Todo) interface{} { return &t.RecurrenceRule }, true),
+	"series_id":        valueField(func(t *Todo) interface{} { return &t.SeriesID }, false),
+	"recurrence_index": valueField(func(t *Todo) interface{} { return &t.RecurrenceIndex }, false),
+	"auto_complete":    valueField(func(t *Todo) interface{} { return &t.AutoComplete }, true),
+	"completed_at":     timeField(func(t *Todo) **time.Time { return &t.CompletedAt }, false),
+	"archived_at":      timeField(func(t *Todo) **time.Time { return &t.ArchivedAt }, false),
+	"deleted_at":       timeField(func(t *Todo) **time.Time { return &t.DeletedAt }, false),
+}
+
+// DiffTodos returns the tracked fields that differ between two versions of
+// a todo. Pass a nil old for a created todo and a nil new for a deleted one.
+func DiffTodos(old, new *Todo) (map[string]FieldChange, error) {
+	changes := make(map[string]FieldChange)
+	for name, field := range todoFields {
+		var change FieldChange
+		var err error
+		if old != nil {
+			if change.Old, err = json.Marshal(field.get(old)); err != nil {
+				return nil, err
+			}
+		}
+		if new != nil {
+			if change.New, err = json.Marshal(field.get(new)); err != nil {
+				return nil, err
+			}
+		}
+		if !bytes.Equal(change.Old, change.New) {
+			changes[name] = change
+		}
+	}
+	return changes, nil
+}
+
+// RevertTodo sets the revertible fields of todo to the values they had right
+// after revision rev, replaying revisions from the first one.
+func RevertTodo(todo *Todo, revisions []TodoRevision, rev int) error {
+	sorted := make([]TodoRevision, len(revisions))
+	copy(sorted, revisions)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Revision < sorted[j].Revision })
+
+	found := false
+	for _, revision := range sorted {
+		if revision.Revision > rev {
+			break
+		}
+		if revision.Revision == rev {
+			if revision.Action == RevisionDelete {
+				return &ValidationError{Field: "revision", Message: "cannot revert to a deletion"}
+			}
+			found = true
+		}
+		for name, change := range revision.Changes {
+			field, ok := todoFields[name]
+			if !ok || !field.revertible {
+				continue
+			}
+			if err := field.set(todo, change.New); err != nil {
+				return err
+			}
+		}
+	}
+	if !found {
+		return &ValidationError{Field: "revision", Message: "revision not found"}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package domain
+
+// Search result sizes.
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// SearchQuery is a free-text search over one user's todos. Adapters decide
+// how Text is tokenised, but should match word prefixes and tolerate small
+// typos.
+type SearchQuery struct {
+	UserID int
+	Text   string
+	ListID int // 0 means every list
+	Limit  int
+}
+
+// SearchResult is a matching todo with its relevance and highlighted
+// snippets. Snippets are HTML: the todo's own text, HTML-escaped, with
+// matched terms wrapped in <mark></mark>.
+type SearchResult struct {
+	Todo       Todo            `json:"todo"`
+	Rank       float64         `json:"rank"`
+	Highlights SearchHighlight `json:"highlights"`
+}
+
+type SearchHighlight struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
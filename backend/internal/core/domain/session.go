@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// MaxUserAgentLength bounds the bytes of user agent a session keeps.
+const MaxUserAgentLength = 512
+
+// Session is one login of a user, by password or OAuth. Its ID is the
+// FamilyID of the refresh tokens it issues and the sid claim of its access
+// tokens; it lasts until it is revoked or its refresh token expires unused.
+type Session struct {
+	ID         string     `json:"id"`
+	UserID     int        `json:"-"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"-"`
+	// Current marks the session of the request that listed it.
+	Current bool `json:"current"`
+}
+
+// Client describes where a request comes from.
+type Client struct {
+	UserAgent string
+	IP        string
+}
+
+type clientKey struct{}
+
+// WithClient returns a context that records the client of a request, for
+// the sessions logins made with it start.
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// ClientFromContext returns the client recorded in ctx, or the zero Client.
+func ClientFromContext(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey{}).(Client)
+	return client
+}
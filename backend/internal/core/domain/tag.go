@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// How multiple tag filters combine when listing todos.
+const (
+	TagMatchAny = "any" // todos carrying at least one of the tags
+	TagMatchAll = "all" // todos carrying every tag
+)
+
+// Tag is a user-owned label that can be attached to many todos.
+type Tag struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateTagRequest struct {
+	Name  string `json:"name"`
+	Color string `json:"color,omitempty"`
+}
+
+type UpdateTagRequest struct {
+	Name  string `json:"name"`
+	Color string `json:"color,omitempty"`
+}
+
+// MergeTagsRequest folds the source tags into the tag named in the URL.
+type MergeTagsRequest struct {
+	SourceIDs []int `json:"source_ids"`
+}
@@ -2,24 +2,137 @@ package domain
 
 import "time"
 
+// Statuses of the default workflow, which the frontend was built around.
+const (
+	StatusPending    = "pending"
+	StatusInProgress = "in_progress"
+	StatusDone       = "done"
+)
+
+// Scopes for edits to a recurring todo.
+const (
+	ScopeThis      = "this"      // only the todo being edited
+	ScopeFollowing = "following" // the todo and every later occurrence
+)
+
 type Todo struct {
-	ID          int       `json:"id"`
-	UserID      int       `json:"user_id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Status      string    `json:"status"`
-	ImageID     string    `json:"image_id,omitempty"` // Changed from ImagePath to ImageID
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int        `json:"id"`
+	UserID      int        `json:"user_id"`
+	ListID      int        `json:"list_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	ImageID     string     `json:"image_id,omitempty"` // Changed from ImagePath to ImageID
+	StartAt     *time.Time `json:"start_at,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	Overdue     bool       `json:"overdue"` // Computed by the service, not stored
+
+	// Priority is one of the Priority* values. Position is the todo's rank
+	// (see pkg/rank) within its list for manual ordering.
+	Priority string `json:"priority"`
+	Position string `json:"position"`
+
+	// RecurrenceRule is an RFC 5545 RRULE. SeriesID points at the first
+	// todo of the series and is 0 on that first todo itself.
+	RecurrenceRule  string `json:"recurrence_rule,omitempty"`
+	SeriesID        int    `json:"series_id,omitempty"`
+	RecurrenceIndex int    `json:"recurrence_index,omitempty"` // 1-based occurrence number
+
+	Tags []Tag `json:"tags"`
+
+	// Checklist is only filled in when a single todo is fetched; Progress
+	// is always present. AutoComplete marks the todo done once every
+	// checklist item is done.
+	Checklist    []ChecklistItem   `json:"checklist,omitempty"`
+	Progress     ChecklistProgress `json:"progress"`
+	AutoComplete bool              `json:"auto_complete"`
+
+	// CompletedAt is set while the todo is in one of its workflow's
+	// complete statuses.
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+
+	// ArchivedAt is set while the todo is archived, which hides it from the
+	// default listing.
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+
+	// DeletedAt is set while the todo is in the trash.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// Version goes up by one with every change and guards against lost
+	// updates: a todo is only saved over the version it was read at.
+	Version int `json:"version"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 type CreateTodoRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Status      string `json:"status"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	StartAt     *time.Time `json:"start_at,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+
+	RecurrenceRule string `json:"recurrence_rule,omitempty"`
+
+	// ListID defaults to the user's inbox.
+	ListID int `json:"list_id,omitempty"`
+
+	AutoComplete bool `json:"auto_complete,omitempty"`
+
+	// Priority defaults to PriorityNone. New todos go to the end of their list.
+	Priority string `json:"priority,omitempty"`
+
+	// Tags are tag names; unknown names are created for the user.
+	Tags []string `json:"tags,omitempty"`
 }
 
+// UpdateTodoRequest replaces the basic fields of a todo. Optional fields left
+// nil keep their current value.
 type UpdateTodoRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Status      string `json:"status"`
-}
\ No newline at end of file
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	StartAt     *time.Time `json:"start_at,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	// ClearStartAt and ClearDueAt remove the dates, which a nil StartAt or
+	// DueAt leaves alone. RemoveImage deletes the todo's image.
+	ClearStartAt bool `json:"clear_start_at,omitempty"`
+	ClearDueAt   bool `json:"clear_due_at,omitempty"`
+	RemoveImage  bool `json:"remove_image,omitempty"`
+
+	// RecurrenceRule set to "" stops the todo from recurring.
+	RecurrenceRule *string `json:"recurrence_rule,omitempty"`
+	// Scope decides whether title, description and rule changes also apply
+	// to later occurrences. Defaults to ScopeThis.
+	Scope string `json:"scope,omitempty"`
+
+	// ListID moves the todo to another list when non-nil.
+	ListID *int `json:"list_id,omitempty"`
+
+	AutoComplete *bool `json:"auto_complete,omitempty"`
+
+	Priority *string `json:"priority,omitempty"`
+
+	// Tags replaces the todo's tags when non-nil; an empty list clears them.
+	Tags []string `json:"tags,omitempty"`
+
+	// Version, when not 0, is the version the change is based on; the
+	// update fails with ErrVersionConflict if the todo has moved on.
+	Version int `json:"version,omitempty"`
+}
+
+// MoveTodoRequest places a todo between two neighbours in its list: after
+// AfterID and before BeforeID. Either may be 0; with both 0 the todo moves
+// to the end of the list.
+type MoveTodoRequest struct {
+	AfterID  int `json:"after_id"`
+	BeforeID int `json:"before_id"`
+}
+
+// SeriesRootID returns the ID identifying the recurring series of the todo.
+func (t *Todo) SeriesRootID() int {
+	if t.SeriesID != 0 {
+		return t.SeriesID
+	}
+	return t.ID
+}
@@ -0,0 +1,58 @@
+package domain
+
+import "time"
+
+// Patch formats accepted by PatchTodoRequest.
+const (
+	PatchMerge = "merge" // RFC 7396 JSON Merge Patch
+	PatchJSON  = "json"  // RFC 6902 JSON Patch
+)
+
+// PatchTodoRequest changes a todo by patching its TodoDocument.
+type PatchTodoRequest struct {
+	Format string
+	Patch  []byte
+
+	// Version works as in UpdateTodoRequest.
+	Version int
+}
+
+// TodoDocument is the editable view of a todo that patches apply to.
+// Members that are null or missing after patching are cleared; the image can
+// only be removed, not replaced.
+type TodoDocument struct {
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	Status         string     `json:"status"`
+	ListID         int        `json:"list_id"`
+	StartAt        *time.Time `json:"start_at"`
+	DueAt          *time.Time `json:"due_at"`
+	RecurrenceRule string     `json:"recurrence_rule"`
+	Priority       string     `json:"priority"`
+	AutoComplete   bool       `json:"auto_complete"`
+	ImageID        *string    `json:"image_id"`
+	Tags           []string   `json:"tags"`
+}
+
+// Document returns the editable view of the todo.
+func (t *Todo) Document() TodoDocument {
+	doc := TodoDocument{
+		Title:          t.Title,
+		Description:    t.Description,
+		Status:         t.Status,
+		ListID:         t.ListID,
+		StartAt:        t.StartAt,
+		DueAt:          t.DueAt,
+		RecurrenceRule: t.RecurrenceRule,
+		Priority:       t.Priority,
+		AutoComplete:   t.AutoComplete,
+		Tags:           make([]string, len(t.Tags)),
+	}
+	if t.ImageID != "" {
+		doc.ImageID = &t.ImageID
+	}
+	for i, tag := range t.Tags {
+		doc.Tags[i] = tag.Name
+	}
+	return doc
+}
@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Fields todos can be sorted by.
+const (
+	SortCreatedAt = "created_at"
+	SortUpdatedAt = "updated_at"
+	SortDueAt     = "due_at"
+	SortTitle     = "title"
+	SortPriority  = "priority"
+	SortPosition  = "position"
+	SortArchived  = "archived_at"
+)
+
+// Sort directions.
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// MaxTodoPageSize caps TodoQuery.Limit.
+const MaxTodoPageSize = 200
+
+// TodoQuery selects, orders and pages the todos of one user. Repository
+// adapters must honour every field.
+type TodoQuery struct {
+	UserID int
+	ListID int // 0 means every list
+
+	Statuses []string // any of these; empty means every status
+	Text     string   // case-insensitive match on title or description
+
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	DueAfter      *time.Time
+	DueBefore     *time.Time
+	Overdue       bool
+
+	// Trashed selects todos in the trash instead of the live ones.
+	Trashed bool
+
+	// Archived selects only archived todos. Otherwise archived todos are
+	// left out unless IncludeArchived is set.
+	Archived        bool
+	IncludeArchived bool
+
+	Tags     []string
+	TagMatch string // TagMatchAny or TagMatchAll
+
+	Sort  string // one of the Sort* fields, defaults to SortCreatedAt
+	Order string // SortAsc or SortDesc, defaults to SortDesc (SortAsc for SortPosition)
+
+	// Limit is the page size; 0 returns every match. Cursor continues from
+	// a previous page's NextCursor.
+	Limit  int
+	Cursor string
+
+	// Now and Location are filled in by the service so that the overdue
+	// check runs against the user's clock instead of the server's.
+	Now      time.Time
+	Location *time.Location
+}
+
+// Normalize fills in defaults and rejects unknown sort, order, tag match
+// and page size values.
+func (q *TodoQuery) Normalize() error {
+	if q.Sort == "" {
+		q.Sort = SortCreatedAt
+	}
+	if q.Order == "" {
+		// Manual order reads top to bottom; everything else newest or
+		// highest first
+		q.Order = SortDesc
+		if q.Sort == SortPosition {
+			q.Order = SortAsc
+		}
+	}
+	if q.TagMatch == "" {
+		q.TagMatch = TagMatchAny
+	}
+
+	switch q.Sort {
+	case SortCreatedAt, SortUpdatedAt, SortDueAt, SortTitle, SortPriority, SortPosition, SortArchived:
+	default:
+		return &ValidationError{Field: "sort", Message: fmt.Sprintf("unknown sort %q", q.Sort)}
+	}
+	switch q.Order {
+	case SortAsc, SortDesc:
+	default:
+		return &ValidationError{Field: "order", Message: "must be asc or desc"}
+	}
+	switch q.TagMatch {
+	case TagMatchAny, TagMatchAll:
+	default:
+		return &ValidationError{Field: "tag_match", Message: "must be any or all"}
+	}
+	if q.Limit < 0 || q.Limit > MaxTodoPageSize {
+		return &ValidationError{Field: "limit", Message: fmt.Sprintf("must be between 0 and %d", MaxTodoPageSize)}
+	}
+
+	return nil
+}
+
+// TodoPage is one page of a TodoQuery. NextCursor is empty on the last page.
+type TodoPage struct {
+	Todos      []Todo
+	NextCursor string
+}
+
+// TodoCursor is the position after the last todo of a page: its sort value
+// and ID. Clients only ever see it encoded.
+type TodoCursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+var ErrInvalidCursor = &ValidationError{Field: "cursor", Message: "is invalid"}
+
+// Encode turns the cursor into an opaque URL-safe string.
+func (c TodoCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeTodoCursor parses a cursor produced by Encode and checks that it was
+// issued for the same sort field and direction as query.
+func DecodeTodoCursor(s string, query TodoQuery) (*TodoCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c TodoCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != query.Sort || c.Order != query.Order {
+		return nil, &ValidationError{Field: "cursor", Message: "was issued for a different sort order"}
+	}
+
+	return &c, nil
+}
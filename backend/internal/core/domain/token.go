@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// TokenPair is what a client authenticates with: a short-lived access token
+// for requests and a refresh token to get the next pair with.
+type TokenPair struct {
+	// Token is the access token, a JWT sent as the bearer token.
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	// ExpiresIn is how many seconds Token stays valid.
+	ExpiresIn int `json:"expires_in"`
+}
+
+// AccessClaims are what a valid access token says about a request.
+type AccessClaims struct {
+	UserID int
+	// TokenID is the token's jti claim, which the revocation list is
+	// keyed by.
+	TokenID string
+	// SessionID is the sid claim, the session the token was issued to.
+	SessionID string
+	ExpiresAt time.Time
+}
+
+// RefreshToken is a stored refresh token. Only a hash of the token itself
+// is kept. Each refresh uses up the token and issues the next one in the
+// same family, which is the Session a login started; presenting a used
+// token again means it leaked, and revokes the whole family.
+type RefreshToken struct {
+	ID        int
+	UserID    int
+	FamilyID  string
+	TokenHash string
+	// AccessTokenID and AccessExpiresAt identify the access token issued
+	// with this refresh token, so revoking the family can revoke it too.
+	AccessTokenID   string
+	AccessExpiresAt time.Time
+	ExpiresAt       time.Time
+	UsedAt          *time.Time
+	RevokedAt       *time.Time
+	CreatedAt       time.Time
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// LogoutRequest names the refresh token whose family to log out. The
+// access token of the request, if any, is revoked as well.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
@@ -4,22 +4,34 @@ package domain
 import "time"
 
 type User struct {
-    ID              int       `json:"id"`
-    Username        string    `json:"username"`
-    Email           string    `json:"email"`
-    PasswordHash    string    `json:"-"`
-    OAuthProvider   string    `json:"oauth_provider,omitempty"`
-    OAuthProviderID string    `json:"oauth_provider_id,omitempty"`
-    CreatedAt       time.Time `json:"created_at"`
+    ID              int        `json:"id"`
+    Username        string     `json:"username"`
+    Email           string     `json:"email"`
+    PasswordHash    string     `json:"-"`
+    OAuthProvider   string     `json:"oauth_provider,omitempty"`
+    OAuthProviderID string     `json:"oauth_provider_id,omitempty"`
+    Timezone        string     `json:"timezone,omitempty"` // IANA name, e.g. "Asia/Bangkok"
+    // EmailVerifiedAt is when the user proved they can read Email; nil
+    // until then.
+    EmailVerifiedAt *time.Time `json:"email_verified_at"`
+    CreatedAt       time.Time  `json:"created_at"`
 }
 
 // Add OAuthUser struct
 type OAuthUser struct {
-    Provider    string `json:"provider"`
-    Email       string `json:"email"`
-    Name        string `json:"name"`
-    AvatarURL   string `json:"avatar_url,omitempty"`
-    ProviderID  string `json:"provider_id"`
+    Provider      string `json:"provider"`
+    Email         string `json:"email"`
+    Name          string `json:"name"`
+    AvatarURL     string `json:"avatar_url,omitempty"`
+    ProviderID    string `json:"provider_id"`
+    // EmailVerified is whether the provider has verified Email.
+    EmailVerified bool   `json:"email_verified"`
+}
+
+// OAuthExchangeRequest finishes an OAuth login with the code the callback
+// sent the browser back to the frontend with.
+type OAuthExchangeRequest struct {
+	Code string `json:"code"`
 }
 
 type LoginRequest struct {
@@ -31,9 +43,16 @@ type SignupRequest struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+type UpdateUserRequest struct {
+	Timezone string `json:"timezone"`
 }
 
+// AuthResponse is the result of a login: the user and their first token
+// pair.
 type AuthResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	TokenPair
+	User User `json:"user"`
 }
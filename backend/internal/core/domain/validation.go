@@ -0,0 +1,327 @@
+package domain
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Codes of field validation errors, stable for clients to switch on.
+const (
+	CodeRequired     = "required"
+	CodeTooLong      = "too_long"
+	CodeInvalid      = "invalid"
+	CodeInvalidEmail = "invalid_email"
+	CodeWeakPassword = "weak_password"
+	CodeInvalidValue = "invalid_value"
+	// CodeInvalidTransition is a status change the workflow does not allow.
+	CodeInvalidTransition = "invalid_transition"
+)
+
+// Length limits, matching the column sizes of the schema.
+const (
+	MaxUsernameLength      = 50
+	MaxEmailLength         = 100
+	MaxTitleLength         = 100
+	MaxListNameLength      = 100
+	MaxTagNameLength       = 50
+	MaxTagColorLength      = 20
+	MaxStatusLength        = 20
+	MaxDescriptionLength   = 10000
+	MaxChecklistTextLength = 500
+
+	MinPasswordLength = 8
+	// MaxPasswordLength is where bcrypt stops reading.
+	MaxPasswordLength = 72
+)
+
+// ValidationErrors collects every invalid field of a request, so a form can
+// point out all of them at once.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Validator accumulates field errors. Checks on a field that already failed
+// are skipped, so each field reports its first problem only.
+type Validator struct {
+	errs ValidationErrors
+}
+
+// Add records a field error.
+func (v *Validator) Add(field, code, message string) {
+	if !v.failed(field) {
+		v.errs = append(v.errs, &ValidationError{Field: field, Code: code, Message: message})
+	}
+}
+
+// Check records a field error unless ok.
+func (v *Validator) Check(ok bool, field, code, message string) {
+	if !ok {
+		v.Add(field, code, message)
+	}
+}
+
+// Required checks that value is not blank.
+func (v *Validator) Required(field, value string) {
+	v.Check(strings.TrimSpace(value) != "", field, CodeRequired, "is required")
+}
+
+// MaxLength checks that value has at most max characters.
+func (v *Validator) MaxLength(field, value string, max int) {
+	v.Check(utf8.RuneCountInString(value) <= max, field, CodeTooLong, fmt.Sprintf("must be at most %d characters", max))
+}
+
+// Email checks that value is a bare email address.
+func (v *Validator) Email(field, value string) {
+	addr, err := mail.ParseAddress(value)
+	v.Check(err == nil && addr.Address == value && strings.Contains(value[strings.LastIndex(value, "@"):], "."),
+		field, CodeInvalidEmail, "must be a valid email address")
+}
+
+// Password checks that value is long enough and mixes letters and digits.
+func (v *Validator) Password(field, value string) {
+	var letter, digit bool
+	for _, r := range value {
+		letter = letter || unicode.IsLetter(r)
+		digit = digit || unicode.IsDigit(r)
+	}
+	switch {
+	case utf8.RuneCountInString(value) < MinPasswordLength:
+		v.Add(field, CodeWeakPassword, fmt.Sprintf("must be at least %d characters", MinPasswordLength))
+	case len(value) > MaxPasswordLength:
+		v.Add(field, CodeTooLong, fmt.Sprintf("must be at most %d bytes", MaxPasswordLength))
+	case !letter || !digit:
+		v.Add(field, CodeWeakPassword, "must contain both letters and digits")
+	}
+}
+
+// Status checks that value is well-formed as a workflow status key. Whether
+// the todo's workflow has the status is up to the service.
+func (v *Validator) Status(field, value string) {
+	v.Check(statusKeyPattern.MatchString(value), field, CodeInvalidValue,
+		fmt.Sprintf("must be up to %d lowercase letters, digits or underscores", MaxStatusLength))
+}
+
+// Priority checks that value names a priority.
+func (v *Validator) Priority(field, value string) {
+	if _, err := PriorityLevel(value); err != nil {
+		v.Add(field, CodeInvalidValue, "must be none, low, medium, high or urgent")
+	}
+}
+
+// Timezone checks that value is an IANA time zone name.
+func (v *Validator) Timezone(field, value string) {
+	_, err := time.LoadLocation(value)
+	v.Check(err == nil, field, CodeInvalid, "is not a valid IANA time zone")
+}
+
+// Err returns the collected errors, or nil if there are none.
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func (v *Validator) failed(field string) bool {
+	for _, err := range v.errs {
+		if err.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (r SignupRequest) Validate() error {
+	var v Validator
+	v.Required("username", r.Username)
+	v.MaxLength("username", r.Username, MaxUsernameLength)
+	v.Required("email", r.Email)
+	v.MaxLength("email", r.Email, MaxEmailLength)
+	v.Email("email", r.Email)
+	v.Required("password", r.Password)
+	v.Password("password", r.Password)
+	if r.Timezone != "" {
+		v.Timezone("timezone", r.Timezone)
+	}
+	return v.Err()
+}
+
+func (r LoginRequest) Validate() error {
+	var v Validator
+	v.Required("email", r.Email)
+	v.Required("password", r.Password)
+	return v.Err()
+}
+
+func (r RefreshRequest) Validate() error {
+	var v Validator
+	v.Required("refresh_token", r.RefreshToken)
+	return v.Err()
+}
+
+func (r ForgotPasswordRequest) Validate() error {
+	var v Validator
+	v.Required("email", r.Email)
+	v.MaxLength("email", r.Email, MaxEmailLength)
+	v.Email("email", r.Email)
+	return v.Err()
+}
+
+func (r ResetPasswordRequest) Validate() error {
+	var v Validator
+	v.Required("token", r.Token)
+	v.Required("password", r.Password)
+	v.Password("password", r.Password)
+	return v.Err()
+}
+
+func (r VerifyEmailRequest) Validate() error {
+	var v Validator
+	v.Required("token", r.Token)
+	return v.Err()
+}
+
+func (r MFACodeRequest) Validate() error {
+	var v Validator
+	validateMFACode(&v, r)
+	return v.Err()
+}
+
+func (r MFAVerifyRequest) Validate() error {
+	var v Validator
+	v.Required("mfa_token", r.MFAToken)
+	validateMFACode(&v, r.MFACodeRequest)
+	return v.Err()
+}
+
+func (r OAuthExchangeRequest) Validate() error {
+	var v Validator
+	v.Required("code", r.Code)
+	return v.Err()
+}
+
+func (r UpdateUserRequest) Validate() error {
+	var v Validator
+	if r.Timezone != "" {
+		v.Timezone("timezone", r.Timezone)
+	}
+	return v.Err()
+}
+
+func (r CreateTodoRequest) Validate() error {
+	var v Validator
+	v.Required("title", r.Title)
+	v.MaxLength("title", r.Title, MaxTitleLength)
+	v.MaxLength("description", r.Description, MaxDescriptionLength)
+	if r.Status != "" {
+		v.Status("status", r.Status)
+	}
+	if r.Priority != "" {
+		v.Priority("priority", r.Priority)
+	}
+	validateSchedule(&v, r.StartAt, r.DueAt)
+	validateTagNames(&v, r.Tags)
+	return v.Err()
+}
+
+func (r UpdateTodoRequest) Validate() error {
+	var v Validator
+	v.Required("title", r.Title)
+	v.MaxLength("title", r.Title, MaxTitleLength)
+	v.MaxLength("description", r.Description, MaxDescriptionLength)
+	v.Required("status", r.Status)
+	v.Status("status", r.Status)
+	if r.Priority != nil {
+		v.Priority("priority", *r.Priority)
+	}
+	switch r.Scope {
+	case "", ScopeThis, ScopeFollowing:
+	default:
+		v.Add("scope", CodeInvalidValue, fmt.Sprintf("must be %s or %s", ScopeThis, ScopeFollowing))
+	}
+	validateSchedule(&v, r.StartAt, r.DueAt)
+	validateTagNames(&v, r.Tags)
+	return v.Err()
+}
+
+func (r CreateListRequest) Validate() error {
+	return validateListName(r.Name)
+}
+
+func (r UpdateListRequest) Validate() error {
+	return validateListName(r.Name)
+}
+
+func (r CreateTagRequest) Validate() error {
+	return validateTag(r.Name, r.Color)
+}
+
+func (r UpdateTagRequest) Validate() error {
+	return validateTag(r.Name, r.Color)
+}
+
+func (r CreateChecklistItemRequest) Validate() error {
+	var v Validator
+	v.Required("text", r.Text)
+	v.MaxLength("text", r.Text, MaxChecklistTextLength)
+	return v.Err()
+}
+
+func (r UpdateChecklistItemRequest) Validate() error {
+	var v Validator
+	if r.Text != nil {
+		v.Required("text", *r.Text)
+		v.MaxLength("text", *r.Text, MaxChecklistTextLength)
+	}
+	return v.Err()
+}
+
+func validateSchedule(v *Validator, startAt, dueAt *time.Time) {
+	v.Check(startAt == nil || dueAt == nil || !startAt.After(*dueAt), "start_at", CodeInvalid, "must not be after due_at")
+}
+
+func validateTagNames(v *Validator, names []string) {
+	for _, name := range names {
+		v.MaxLength("tags", strings.TrimSpace(name), MaxTagNameLength)
+	}
+}
+
+// validateMFACode checks that exactly one of a TOTP code and a recovery
+// code was sent.
+func validateMFACode(v *Validator, r MFACodeRequest) {
+	v.Check(r.Code != "" || r.RecoveryCode != "", "code", CodeRequired, "is required without a recovery_code")
+	v.Check(r.Code == "" || r.RecoveryCode == "", "recovery_code", CodeInvalid, "cannot be sent with a code")
+}
+
+func validateListName(name string) error {
+	var v Validator
+	v.Required("name", name)
+	v.MaxLength("name", strings.TrimSpace(name), MaxListNameLength)
+	return v.Err()
+}
+
+func validateTag(name, color string) error {
+	var v Validator
+	v.Required("name", name)
+	v.MaxLength("name", strings.TrimSpace(name), MaxTagNameLength)
+	v.MaxLength("color", color, MaxTagColorLength)
+	return v.Err()
+}
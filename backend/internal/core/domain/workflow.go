@@ -0,0 +1,165 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// DefaultWorkflowName names the workflow every user starts with.
+const DefaultWorkflowName = "Default"
+
+// WorkflowStatus is one status a todo can be in. Statuses are shown in
+// Position order and the first one is where new todos start.
+type WorkflowStatus struct {
+	Key      string `json:"key"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+	Complete bool   `json:"complete"`
+}
+
+// WorkflowTransition allows moving a todo from one status to another.
+type WorkflowTransition struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Workflow defines the statuses of a user's todos, or of one list when
+// ListID is set. A workflow without transitions lets a todo move between
+// any two of its statuses.
+type Workflow struct {
+	ID          int                  `json:"id"`
+	UserID      int                  `json:"user_id"`
+	ListID      int                  `json:"list_id,omitempty"`
+	Name        string               `json:"name"`
+	Statuses    []WorkflowStatus     `json:"statuses"`
+	Transitions []WorkflowTransition `json:"transitions"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+// DefaultWorkflow is the pending / in progress / done workflow the frontend
+// was built around. It is used for users who have not stored one.
+func DefaultWorkflow(userID int) *Workflow {
+	return &Workflow{
+		UserID: userID,
+		Name:   DefaultWorkflowName,
+		Statuses: []WorkflowStatus{
+			{Key: StatusPending, Name: "Pending", Position: 0},
+			{Key: StatusInProgress, Name: "In Progress", Position: 1},
+			{Key: StatusDone, Name: "Done", Position: 2, Complete: true},
+		},
+		Transitions: []WorkflowTransition{},
+	}
+}
+
+// Status returns the status with the given key, or nil.
+func (w *Workflow) Status(key string) *WorkflowStatus {
+	for i := range w.Statuses {
+		if w.Statuses[i].Key == key {
+			return &w.Statuses[i]
+		}
+	}
+	return nil
+}
+
+// InitialStatus is the status new todos start in.
+func (w *Workflow) InitialStatus() string {
+	initial := w.Statuses[0]
+	for _, status := range w.Statuses[1:] {
+		if status.Position < initial.Position {
+			initial = status
+		}
+	}
+	return initial.Key
+}
+
+// IsComplete reports whether a todo in the given status counts as finished.
+func (w *Workflow) IsComplete(key string) bool {
+	status := w.Status(key)
+	return status != nil && status.Complete
+}
+
+// CompleteStatus returns the first complete status in position order.
+func (w *Workflow) CompleteStatus() string {
+	key, position := "", 0
+	for _, status := range w.Statuses {
+		if status.Complete && (key == "" || status.Position < position) {
+			key, position = status.Key, status.Position
+		}
+	}
+	return key
+}
+
+// CanTransition reports whether a todo may move from one status to another.
+// Staying in the same status is always allowed.
+func (w *Workflow) CanTransition(from, to string) bool {
+	if from == to || len(w.Transitions) == 0 {
+		return true
+	}
+	for _, t := range w.Transitions {
+		if t.From == from && t.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+var statusKeyPattern = regexp.MustCompile(`^[a-z0-9_]{1,20}$`)
+
+// Validate checks that the workflow is usable: at least one status, unique
+// well-formed keys, at least one complete status and transitions that only
+// mention known statuses.
+func (w *Workflow) Validate() error {
+	if w.Name == "" {
+		return &ValidationError{Field: "name", Code: CodeRequired, Message: "is required"}
+	}
+	if len(w.Statuses) == 0 {
+		return &ValidationError{Field: "statuses", Message: "must not be empty"}
+	}
+
+	seen := make(map[string]bool, len(w.Statuses))
+	hasComplete := false
+	for _, status := range w.Statuses {
+		if !statusKeyPattern.MatchString(status.Key) {
+			return &ValidationError{Field: "statuses", Message: fmt.Sprintf("invalid key %q, use up to 20 lowercase letters, digits or underscores", status.Key)}
+		}
+		if seen[status.Key] {
+			return &ValidationError{Field: "statuses", Message: fmt.Sprintf("duplicate key %q", status.Key)}
+		}
+		seen[status.Key] = true
+		hasComplete = hasComplete || status.Complete
+	}
+	if !hasComplete {
+		return &ValidationError{Field: "statuses", Message: "at least one status must be complete"}
+	}
+
+	for _, t := range w.Transitions {
+		if !seen[t.From] || !seen[t.To] {
+			return &ValidationError{Field: "transitions", Message: fmt.Sprintf("unknown status in %s -> %s", t.From, t.To)}
+		}
+	}
+
+	return nil
+}
+
+// CheckTransition returns a ValidationError unless a todo may move from one
+// status to another.
+func (w *Workflow) CheckTransition(from, to string) error {
+	if w.Status(to) == nil {
+		return &ValidationError{Field: "status", Code: CodeInvalidValue, Message: fmt.Sprintf("unknown status %q in workflow %q", to, w.Name)}
+	}
+	if !w.CanTransition(from, to) {
+		return &ValidationError{Field: "status", Code: CodeInvalidTransition, Message: fmt.Sprintf("cannot move from %q to %q", from, to)}
+	}
+	return nil
+}
+
+// WorkflowRequest creates or replaces a workflow. ListID is only read on
+// create; 0 makes the workflow the user's default.
+type WorkflowRequest struct {
+	ListID      int                  `json:"list_id"`
+	Name        string               `json:"name"`
+	Statuses    []WorkflowStatus     `json:"statuses"`
+	Transitions []WorkflowTransition `json:"transitions"`
+}
@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+// Mailer sends email to users.
+type Mailer interface {
+	Send(ctx context.Context, email domain.Email) error
+}
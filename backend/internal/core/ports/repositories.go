@@ -4,23 +4,212 @@ import (
 
 	"context"
 	"mime/multipart"
+	"time"
 
 	"github.com/ChaiyawutTar/MyList/internal/core/domain"
 )
 
+// Transactor runs work in one database transaction. Repository calls made
+// with the context passed to fn take part in it; a nested WithinTx only
+// undoes its own changes when it fails.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type UserRepository interface {
     Create(ctx context.Context, user *domain.User, password string) error
     FindByEmail(ctx context.Context, email string) (*domain.User, error)
     FindByID(ctx context.Context, id int) (*domain.User, error)
     FindByOAuthID(ctx context.Context, provider, providerID string) (*domain.User, error)
     UpdateOAuthInfo(ctx context.Context, user *domain.User) error
+    UpdateTimezone(ctx context.Context, userID int, timezone string) error
+    // UpdatePassword hashes and stores a new password for the user.
+    UpdatePassword(ctx context.Context, userID int, password string) error
+    // MarkEmailVerified records that the user verified their email at the
+    // given time, unless they already had.
+    MarkEmailVerified(ctx context.Context, userID int, at time.Time) error
+}
+
+// RefreshTokenRepository stores refresh tokens by the hash of the token.
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *domain.RefreshToken) error
+	// FindByHash returns domain.ErrInvalidRefreshToken for an unknown hash.
+	FindByHash(ctx context.Context, hash string) (*domain.RefreshToken, error)
+	FindByFamily(ctx context.Context, familyID string) ([]domain.RefreshToken, error)
+	// MarkUsed uses up a live token. It reports false, and changes
+	// nothing, if the token was already used or revoked, so of two
+	// concurrent refreshes with one token only the first wins.
+	MarkUsed(ctx context.Context, id int, at time.Time) (bool, error)
+	// RevokeFamily revokes every live token of the family.
+	RevokeFamily(ctx context.Context, familyID string, at time.Time) error
+	// DeleteExpired deletes tokens that expired before the given time and
+	// returns how many.
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
+
+// RevokedTokenRepository is the revocation list of access tokens, keyed by
+// their jti. Entries only need to outlive the token they revoke.
+type RevokedTokenRepository interface {
+	// Revoke adds the token to the list; revoking it again is not an error.
+	Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+	// DeleteExpired drops entries for tokens that expired before the given
+	// time and returns how many.
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
+
+// SessionRepository stores the sessions logins start.
+type SessionRepository interface {
+	Create(ctx context.Context, session *domain.Session) error
+	// FindByID finds revoked and expired sessions too.
+	FindByID(ctx context.Context, id string) (*domain.Session, error)
+	// FindActive returns the user's sessions that are neither revoked nor
+	// expired at the given time, most recently seen first.
+	FindActive(ctx context.Context, userID int, now time.Time) ([]domain.Session, error)
+	// Extend records a refresh of the session: it was seen at seenAt and
+	// now expires at expiresAt. It returns domain.ErrSessionNotFound for an
+	// unknown session.
+	Extend(ctx context.Context, id string, seenAt, expiresAt time.Time) error
+	// Revoke marks the session revoked; revoking it again is not an error.
+	Revoke(ctx context.Context, id string, at time.Time) error
+	// Touch sets when each of the sessions was last seen, in one batch.
+	// Unknown sessions are skipped and a time never moves backwards.
+	Touch(ctx context.Context, lastSeen map[string]time.Time) error
+	// DeleteExpired deletes sessions that expired before the given time
+	// and returns how many.
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
+
+// OneTimeTokenRepository stores one-time tokens by the hash of the token.
+type OneTimeTokenRepository interface {
+	Create(ctx context.Context, token *domain.OneTimeToken) error
+	// FindByHash finds used and expired tokens too. It returns
+	// domain.ErrOneTimeTokenNotFound for an unknown hash or a token of
+	// another purpose.
+	FindByHash(ctx context.Context, purpose, hash string) (*domain.OneTimeToken, error)
+	// Consume uses up an unused token. It reports false, and changes
+	// nothing, if the token was already used, so a token works only once
+	// even when presented twice at the same time.
+	Consume(ctx context.Context, id int, at time.Time) (bool, error)
+	// FindLatest returns the token of the user for the purpose that was
+	// created last, or domain.ErrOneTimeTokenNotFound if there is none.
+	FindLatest(ctx context.Context, userID int, purpose string) (*domain.OneTimeToken, error)
+	// ConsumeAll uses up every unused token of the user for the purpose.
+	ConsumeAll(ctx context.Context, userID int, purpose string, at time.Time) error
+	// DeleteExpired deletes tokens that expired before the given time and
+	// returns how many.
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
+
+// MFARepository stores the TOTP settings of users, at most one per user.
+type MFARepository interface {
+	// Save stores the user's settings, replacing any earlier ones.
+	Save(ctx context.Context, mfa *domain.MFA) error
+	// FindByUserID returns domain.ErrMFANotFound if the user has none.
+	FindByUserID(ctx context.Context, userID int) (*domain.MFA, error)
+	// Enable marks the settings confirmed.
+	Enable(ctx context.Context, userID int, at time.Time) error
+	// UseStep records that a code of the time step was accepted. It
+	// reports false, and changes nothing, if that step or a later one was
+	// accepted already, so of two uses of one code only the first wins.
+	UseStep(ctx context.Context, userID int, step int64) (bool, error)
+	// AddFailure counts a wrong code and returns how many there have been
+	// since the last ClearFailures or Lock.
+	AddFailure(ctx context.Context, userID int) (int, error)
+	// Lock refuses codes until the given time and clears the failures.
+	Lock(ctx context.Context, userID int, until time.Time) error
+	ClearFailures(ctx context.Context, userID int) error
+	// Delete removes the user's settings; deleting none is not an error.
+	Delete(ctx context.Context, userID int) error
+}
+
+// RecoveryCodeRepository stores MFA recovery codes by their hash.
+type RecoveryCodeRepository interface {
+	// Replace swaps every recovery code of the user for the given ones.
+	Replace(ctx context.Context, userID int, hashes []string) error
+	// Use uses up an unused code of the user. It reports false, and
+	// changes nothing, if the user has no such unused code.
+	Use(ctx context.Context, userID int, hash string, at time.Time) (bool, error)
+	CountUnused(ctx context.Context, userID int) (int, error)
+	DeleteAll(ctx context.Context, userID int) error
 }
 
 type TodoRepository interface {
-	FindAll(ctx context.Context, userID int) ([]domain.Todo, error)
+	FindAll(ctx context.Context, query domain.TodoQuery) (*domain.TodoPage, error)
 	FindByID(ctx context.Context, id int) (*domain.Todo, error)
+	FindSeries(ctx context.Context, seriesID int) ([]domain.Todo, error)
+	// FindTrashedBefore returns up to limit todos of any user that were
+	// moved to the trash before the given time, oldest first.
+	FindTrashedBefore(ctx context.Context, before time.Time, limit int) ([]domain.Todo, error)
+	// ArchiveCompleted archives, in one transaction, every live todo that
+	// was completed before the given time. A userID or listID of 0 matches
+	// any user or list. It returns the number of archived todos.
+	ArchiveCompleted(ctx context.Context, userID, listID int, completedBefore time.Time) (int, error)
 	Create(ctx context.Context, todo *domain.Todo) error
 	Update(ctx context.Context, todo *domain.Todo) error
+	// Delete removes the todo for good; trashing is an Update of DeletedAt.
+	Delete(ctx context.Context, id int) error
+}
+
+// TodoRevisionRepository reads the history of todos. Revisions are written
+// by the TodoRepository in the same transaction as each change.
+type TodoRevisionRepository interface {
+	// FindByTodoID returns every revision of the todo, oldest first.
+	FindByTodoID(ctx context.Context, todoID int) ([]domain.TodoRevision, error)
+}
+
+// TodoSearcher runs full-text searches over todos. Results are ordered by
+// relevance, best first.
+type TodoSearcher interface {
+	Search(ctx context.Context, query domain.SearchQuery) ([]domain.SearchResult, error)
+}
+
+type ListRepository interface {
+	FindAll(ctx context.Context, userID int) ([]domain.List, error)
+	FindByID(ctx context.Context, id int) (*domain.List, error)
+	FindInbox(ctx context.Context, userID int) (*domain.List, error)
+	Create(ctx context.Context, list *domain.List) error
+	Update(ctx context.Context, list *domain.List) error
+	Delete(ctx context.Context, id int) error
+	// MoveTodos reassigns every todo of one list to another.
+	MoveTodos(ctx context.Context, fromListID, toListID int) error
+}
+
+type TagRepository interface {
+	FindAll(ctx context.Context, userID int) ([]domain.Tag, error)
+	FindByID(ctx context.Context, id int) (*domain.Tag, error)
+	FindByNames(ctx context.Context, userID int, names []string) ([]domain.Tag, error)
+	FindByTodoIDs(ctx context.Context, todoIDs []int) (map[int][]domain.Tag, error)
+	Create(ctx context.Context, tag *domain.Tag) error
+	Update(ctx context.Context, tag *domain.Tag) error
+	Delete(ctx context.Context, id int) error
+	// Merge moves every todo tagged with one of sourceIDs onto targetID and
+	// deletes the source tags.
+	Merge(ctx context.Context, targetID int, sourceIDs []int) error
+	SetTodoTags(ctx context.Context, todoID int, tagIDs []int) error
+}
+
+type ChecklistRepository interface {
+	FindByTodoID(ctx context.Context, todoID int) ([]domain.ChecklistItem, error)
+	FindByID(ctx context.Context, id int) (*domain.ChecklistItem, error)
+	// Progress counts done and total items for each of the given todos.
+	Progress(ctx context.Context, todoIDs []int) (map[int]domain.ChecklistProgress, error)
+	// Create appends the item to the end of its todo's checklist.
+	Create(ctx context.Context, item *domain.ChecklistItem) error
+	Update(ctx context.Context, item *domain.ChecklistItem) error
+	Delete(ctx context.Context, id int) error
+	// Reorder sets the positions of a todo's items to the order of itemIDs.
+	Reorder(ctx context.Context, todoID int, itemIDs []int) error
+}
+
+type WorkflowRepository interface {
+	FindAll(ctx context.Context, userID int) ([]domain.Workflow, error)
+	FindByID(ctx context.Context, id int) (*domain.Workflow, error)
+	// FindEffective returns the workflow of a list, falling back to the
+	// user's default workflow, and nil if neither has been stored.
+	FindEffective(ctx context.Context, userID, listID int) (*domain.Workflow, error)
+	Create(ctx context.Context, workflow *domain.Workflow) error
+	Update(ctx context.Context, workflow *domain.Workflow) error
 	Delete(ctx context.Context, id int) error
 }
 
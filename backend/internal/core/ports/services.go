@@ -4,20 +4,184 @@ import (
 
 	"context"
 	"mime/multipart"
+	"time"
 
 	"github.com/ChaiyawutTar/MyList/internal/core/domain"
 )
 type UserService interface {
 	CreateUser(ctx context.Context, req domain.SignupRequest) (*domain.AuthResponse, error)
-	Login(ctx context.Context, req domain.LoginRequest) (*domain.AuthResponse, error)
-	OAuthLogin(ctx context.Context, oauthUser domain.OAuthUser) (*domain.AuthResponse, error)
+	// Login and ExchangeOAuthCode return tokens, or an MFA challenge for
+	// users with MFA on.
+	Login(ctx context.Context, req domain.LoginRequest) (*domain.LoginResult, error)
+	// OAuthLogin signs in the user a provider vouched for and returns a
+	// short-lived one-time code, which the OAuth callback hands the
+	// frontend in place of tokens. ExchangeOAuthCode uses it up.
+	OAuthLogin(ctx context.Context, oauthUser domain.OAuthUser) (string, error)
+	ExchangeOAuthCode(ctx context.Context, req domain.OAuthExchangeRequest) (*domain.LoginResult, error)
 	GetUserByID(ctx context.Context, id int) (*domain.User, error)
+	UpdateUser(ctx context.Context, id int, req domain.UpdateUserRequest) (*domain.User, error)
+}
+
+// TokenService issues, rotates and revokes the tokens clients authenticate
+// with.
+type TokenService interface {
+	// Issue starts a new token family for a user who just logged in.
+	Issue(ctx context.Context, userID int) (*domain.TokenPair, error)
+	// Refresh uses up a refresh token and returns the next pair of its
+	// family. A token that was already used revokes the family and
+	// returns domain.ErrRefreshTokenReused.
+	Refresh(ctx context.Context, req domain.RefreshRequest) (*domain.TokenPair, error)
+	// Logout revokes the family of the refresh token and the access token,
+	// either of which may be empty.
+	Logout(ctx context.Context, req domain.LogoutRequest, accessToken string) error
+	// Authenticate checks an access token's signature, expiry and
+	// revocation.
+	Authenticate(ctx context.Context, accessToken string) (*domain.AccessClaims, error)
+	// RevokeSession revokes the session and every token issued to it.
+	RevokeSession(ctx context.Context, sessionID string) error
+	// RevokeUserSessions revokes every active session of the user but the
+	// one with exceptID, which may be empty, and returns how many.
+	RevokeUserSessions(ctx context.Context, userID int, exceptID string) (int, error)
+}
+
+// EmailVerificationService confirms that users can read the email address
+// of their account, through a link mailed to them.
+type EmailVerificationService interface {
+	// SendVerification mails the user a new verification link, voiding
+	// earlier ones. It returns a *domain.ThrottledError when the last link
+	// was sent too recently, and domain.ErrEmailAlreadyVerified when there
+	// is nothing to verify.
+	SendVerification(ctx context.Context, userID int) error
+	VerifyEmail(ctx context.Context, req domain.VerifyEmailRequest) error
+}
+
+// MFAService manages TOTP multi-factor authentication and completes the
+// logins it challenges. Codes are accepted one time step either side of
+// the current one, each only once.
+type MFAService interface {
+	GetStatus(ctx context.Context, userID int) (*domain.MFAStatus, error)
+	// EnrollTOTP starts enrollment with a new secret, replacing one that
+	// was never confirmed.
+	EnrollTOTP(ctx context.Context, userID int) (*domain.TOTPEnrollment, error)
+	// ConfirmTOTP turns MFA on with a code from the enrolled app and
+	// returns the first recovery codes.
+	ConfirmTOTP(ctx context.Context, userID int, req domain.MFACodeRequest) (*domain.RecoveryCodes, error)
+	// Disable turns MFA off with a code or a recovery code.
+	Disable(ctx context.Context, userID int, req domain.MFACodeRequest) error
+	// RegenerateRecoveryCodes replaces the recovery codes with new ones.
+	RegenerateRecoveryCodes(ctx context.Context, userID int, req domain.MFACodeRequest) (*domain.RecoveryCodes, error)
+	// Challenge starts an MFA challenge for a login of the user, or
+	// returns nil if the user has MFA off.
+	Challenge(ctx context.Context, userID int) (*domain.MFAChallenge, error)
+	// VerifyLogin completes a challenged login.
+	VerifyLogin(ctx context.Context, req domain.MFAVerifyRequest) (*domain.AuthResponse, error)
+}
+
+// PasswordService lets users who forgot their password set a new one
+// through a link mailed to them.
+type PasswordService interface {
+	// ForgotPassword mails a reset link if an account has the email, at
+	// most one in a while. It reports success either way, and returns
+	// before the link is stored or mailed, so neither its answer nor its
+	// timing tells who has an account or was throttled.
+	ForgotPassword(ctx context.Context, req domain.ForgotPasswordRequest) error
+	// ResetPassword sets the password with the token of a reset link and
+	// logs the user out of every session.
+	ResetPassword(ctx context.Context, req domain.ResetPasswordRequest) error
+}
+
+// SessionService lets users see and end the sessions they are logged in
+// with. currentID is the session of the request, from its access token.
+type SessionService interface {
+	// GetSessions lists the user's active sessions, most recently used
+	// first, marking the current one.
+	GetSessions(ctx context.Context, userID int, currentID string) ([]domain.Session, error)
+	RevokeSession(ctx context.Context, id string, userID int) error
+	// RevokeOtherSessions logs the user out everywhere but the current
+	// session and returns how many sessions it ended.
+	RevokeOtherSessions(ctx context.Context, userID int, currentID string) (int, error)
+}
+
+// SessionActivity records that a session was used. Implementations buffer
+// the times, so recording one does not write to the database.
+type SessionActivity interface {
+	Touch(sessionID string, at time.Time)
 }
 
 type TodoService interface {
-	GetAllTodos(ctx context.Context, userID int) ([]domain.Todo, error)
+	GetAllTodos(ctx context.Context, userID int, query domain.TodoQuery) (*domain.TodoPage, error)
 	GetTodoByID(ctx context.Context, id int, userID int) (*domain.Todo, error)
+	SearchTodos(ctx context.Context, userID int, query domain.SearchQuery) ([]domain.SearchResult, error)
 	CreateTodo(ctx context.Context, req domain.CreateTodoRequest, userID int, image *multipart.FileHeader) (*domain.Todo, error)
 	UpdateTodo(ctx context.Context, id int, req domain.UpdateTodoRequest, userID int, image *multipart.FileHeader) (*domain.Todo, error)
-	DeleteTodo(ctx context.Context, id int, userID int) error
-}
\ No newline at end of file
+	// PatchTodo applies a merge patch or JSON patch to the todo's
+	// domain.TodoDocument and validates the result like UpdateTodo.
+	PatchTodo(ctx context.Context, id int, req domain.PatchTodoRequest, userID int) (*domain.Todo, error)
+	// MoveTodo reorders a todo within its list.
+	MoveTodo(ctx context.Context, id int, req domain.MoveTodoRequest, userID int) (*domain.Todo, error)
+	// DeleteTodo moves the todo to the trash. A version other than 0 must
+	// match the todo's, or domain.ErrVersionConflict is returned.
+	DeleteTodo(ctx context.Context, id int, userID int, version int) error
+	GetTrash(ctx context.Context, userID int, query domain.TodoQuery) (*domain.TodoPage, error)
+	RestoreTodo(ctx context.Context, id int, userID int) (*domain.Todo, error)
+	// PurgeTodo deletes a trashed todo and its image for good.
+	PurgeTodo(ctx context.Context, id int, userID int) error
+	// EmptyTrash purges every trashed todo of the user and returns how many.
+	EmptyTrash(ctx context.Context, userID int) (int, error)
+	GetArchivedTodos(ctx context.Context, userID int, query domain.TodoQuery) (*domain.TodoPage, error)
+	ArchiveTodo(ctx context.Context, id int, userID int) (*domain.Todo, error)
+	UnarchiveTodo(ctx context.Context, id int, userID int) (*domain.Todo, error)
+	// ArchiveCompleted archives every completed todo of the user, or of one
+	// of their lists when listID is not 0, and returns how many.
+	ArchiveCompleted(ctx context.Context, userID int, listID int) (int, error)
+	// BatchTodos applies the operations of the batch in one transaction and
+	// reports the outcome of every item.
+	BatchTodos(ctx context.Context, req domain.BatchRequest, userID int) (*domain.BatchResult, error)
+	// GetTodoHistory returns the revisions of a todo, newest first.
+	GetTodoHistory(ctx context.Context, id int, userID int) ([]domain.TodoRevision, error)
+	// RevertTodo restores the todo's fields to what they were right after
+	// revision rev, recording the revert as a new revision. The status only
+	// goes back if the workflow allows the move; tags are not restored, as
+	// revisions do not record them.
+	RevertTodo(ctx context.Context, id int, rev int, userID int) (*domain.Todo, error)
+}
+
+type TagService interface {
+	GetAllTags(ctx context.Context, userID int) ([]domain.Tag, error)
+	CreateTag(ctx context.Context, req domain.CreateTagRequest, userID int) (*domain.Tag, error)
+	UpdateTag(ctx context.Context, id int, req domain.UpdateTagRequest, userID int) (*domain.Tag, error)
+	DeleteTag(ctx context.Context, id int, userID int) error
+	MergeTags(ctx context.Context, targetID int, req domain.MergeTagsRequest, userID int) (*domain.Tag, error)
+}
+
+type ListService interface {
+	GetAllLists(ctx context.Context, userID int) ([]domain.List, error)
+	GetListByID(ctx context.Context, id int, userID int) (*domain.List, error)
+	CreateList(ctx context.Context, req domain.CreateListRequest, userID int) (*domain.List, error)
+	UpdateList(ctx context.Context, id int, req domain.UpdateListRequest, userID int) (*domain.List, error)
+	// DeleteList takes ListDeleteReassign or ListDeleteCascade as mode.
+	DeleteList(ctx context.Context, id int, userID int, mode string) error
+	MoveTodos(ctx context.Context, listID int, req domain.MoveTodosRequest, userID int) error
+}
+
+// ChecklistService manages the checklist of a todo. Every method returns the
+// parent todo so clients see the new progress and any auto-completion.
+type ChecklistService interface {
+	AddItem(ctx context.Context, todoID int, req domain.CreateChecklistItemRequest, userID int) (*domain.Todo, error)
+	UpdateItem(ctx context.Context, todoID, itemID int, req domain.UpdateChecklistItemRequest, userID int) (*domain.Todo, error)
+	ToggleItem(ctx context.Context, todoID, itemID int, userID int) (*domain.Todo, error)
+	RemoveItem(ctx context.Context, todoID, itemID int, userID int) (*domain.Todo, error)
+	Reorder(ctx context.Context, todoID int, req domain.ReorderChecklistRequest, userID int) (*domain.Todo, error)
+}
+
+// WorkflowService manages the statuses todos can move through. A user
+// without a stored default workflow gets domain.DefaultWorkflow, with ID 0.
+type WorkflowService interface {
+	GetWorkflows(ctx context.Context, userID int) ([]domain.Workflow, error)
+	GetWorkflow(ctx context.Context, id int, userID int) (*domain.Workflow, error)
+	// GetListWorkflow returns the workflow that applies to a list's todos.
+	GetListWorkflow(ctx context.Context, listID int, userID int) (*domain.Workflow, error)
+	CreateWorkflow(ctx context.Context, req domain.WorkflowRequest, userID int) (*domain.Workflow, error)
+	UpdateWorkflow(ctx context.Context, id int, req domain.WorkflowRequest, userID int) (*domain.Workflow, error)
+	DeleteWorkflow(ctx context.Context, id int, userID int) error
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+)
+
+func (s *todoService) GetArchivedTodos(ctx context.Context, userID int, query domain.TodoQuery) (*domain.TodoPage, error) {
+	query.Archived = true
+	query.Trashed = false
+	return s.GetAllTodos(ctx, userID, query)
+}
+
+func (s *todoService) ArchiveTodo(ctx context.Context, id int, userID int) (*domain.Todo, error) {
+	todo, err := s.GetTodoByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if todo.ArchivedAt != nil {
+		return todo, nil
+	}
+
+	now := time.Now()
+	todo.ArchivedAt = &now
+	if err := s.todoRepo.Update(ctx, todo); err != nil {
+		return nil, fmt.Errorf("failed to archive todo: %w", err)
+	}
+
+	return todo, nil
+}
+
+func (s *todoService) UnarchiveTodo(ctx context.Context, id int, userID int) (*domain.Todo, error) {
+	todo, err := s.GetTodoByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if todo.ArchivedAt == nil {
+		return nil, domain.Conflict("todo_not_archived", "todo is not archived")
+	}
+
+	todo.ArchivedAt = nil
+	if err := s.todoRepo.Update(ctx, todo); err != nil {
+		return nil, fmt.Errorf("failed to unarchive todo: %w", err)
+	}
+
+	return todo, nil
+}
+
+func (s *todoService) ArchiveCompleted(ctx context.Context, userID int, listID int) (int, error) {
+	if listID != 0 {
+		if _, err := getOwnedList(ctx, s.listRepo, listID, userID); err != nil {
+			return 0, err
+		}
+	}
+	return s.todoRepo.ArchiveCompleted(ctx, userID, listID, time.Now())
+}
+
+// TodoArchiver archives todos that have been complete for longer than a
+// configured age.
+type TodoArchiver struct {
+	todoRepo ports.TodoRepository
+	after    time.Duration
+	interval time.Duration
+}
+
+func NewTodoArchiver(todoRepo ports.TodoRepository, after, interval time.Duration) *TodoArchiver {
+	return &TodoArchiver{
+		todoRepo: todoRepo,
+		after:    after,
+		interval: interval,
+	}
+}
+
+// Run archives once right away and then every interval until ctx is done.
+func (a *TodoArchiver) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		if archived, err := a.Archive(ctx); err != nil {
+			log.Printf("Error archiving todos: %v", err)
+		} else if archived > 0 {
+			log.Printf("Archived %d completed todos", archived)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Archive archives every todo completed before the cutoff and returns how
+// many it archived.
+func (a *TodoArchiver) Archive(ctx context.Context) (int, error) {
+	return a.todoRepo.ArchiveCompleted(ctx, 0, 0, time.Now().Add(-a.after))
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+// errBatchFailed aborts the transaction of an atomic batch.
+var errBatchFailed = errors.New("batch failed")
+
+func (s *todoService) BatchTodos(ctx context.Context, req domain.BatchRequest, userID int) (*domain.BatchResult, error) {
+	if req.Mode == "" {
+		req.Mode = domain.BatchAtomic
+	}
+	if err := validateBatch(req); err != nil {
+		return nil, err
+	}
+
+	result := &domain.BatchResult{Mode: req.Mode, Results: make([]domain.BatchItemResult, 0)}
+	for _, op := range req.Operations {
+		for _, id := range op.IDs {
+			result.Results = append(result.Results, domain.BatchItemResult{Op: op.Op, ID: id, Status: domain.BatchItemSkipped})
+		}
+	}
+
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		i := 0
+		for _, op := range req.Operations {
+			for _, id := range op.IDs {
+				item := &result.Results[i]
+				i++
+
+				var todo *domain.Todo
+				var err error
+				if req.Mode == domain.BatchBestEffort {
+					// A savepoint undoes just this item when it fails
+					err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+						var itemErr error
+						todo, itemErr = s.runBatchItem(ctx, op, id, userID)
+						return itemErr
+					})
+				} else {
+					todo, err = s.runBatchItem(ctx, op, id, userID)
+				}
+
+				if err != nil {
+					item.Status = domain.BatchItemFailed
+					item.Err = err
+					if req.Mode == domain.BatchAtomic {
+						return errBatchFailed
+					}
+					continue
+				}
+				item.Status = domain.BatchItemOK
+				item.Todo = todo
+			}
+		}
+		return nil
+	})
+
+	if errors.Is(err, errBatchFailed) {
+		for i := range result.Results {
+			if result.Results[i].Status == domain.BatchItemOK {
+				result.Results[i].Status = domain.BatchItemRolledBack
+				result.Results[i].Todo = nil
+			}
+		}
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Committed = true
+	return result, nil
+}
+
+func validateBatch(req domain.BatchRequest) error {
+	switch req.Mode {
+	case domain.BatchAtomic, domain.BatchBestEffort:
+	default:
+		return &domain.ValidationError{Field: "mode", Message: fmt.Sprintf("unknown mode %q", req.Mode)}
+	}
+	if len(req.Operations) == 0 {
+		return &domain.ValidationError{Field: "operations", Message: "at least one operation is required"}
+	}
+
+	items := 0
+	for i, op := range req.Operations {
+		field := fmt.Sprintf("operations[%d]", i)
+		if len(op.IDs) == 0 {
+			return &domain.ValidationError{Field: field + ".ids", Message: "at least one ID is required"}
+		}
+		items += len(op.IDs)
+
+		switch op.Op {
+		case domain.BatchUpdateStatus:
+			if op.Status == "" {
+				return &domain.ValidationError{Field: field + ".status", Code: domain.CodeRequired, Message: "is required"}
+			}
+		case domain.BatchTag:
+			if len(op.Tags) == 0 && len(op.RemoveTags) == 0 {
+				return &domain.ValidationError{Field: field + ".tags", Message: "tags or remove_tags is required"}
+			}
+		case domain.BatchDelete, domain.BatchMove:
+		default:
+			return &domain.ValidationError{Field: field + ".op", Message: fmt.Sprintf("unknown operation %q", op.Op)}
+		}
+	}
+	if items > domain.MaxBatchItems {
+		return &domain.ValidationError{Field: "operations", Message: fmt.Sprintf("at most %d items per batch", domain.MaxBatchItems)}
+	}
+	return nil
+}
+
+// runBatchItem applies op to one todo through the regular service methods,
+// which check ownership and validate the change.
+func (s *todoService) runBatchItem(ctx context.Context, op domain.BatchOperation, id int, userID int) (*domain.Todo, error) {
+	switch op.Op {
+	case domain.BatchUpdateStatus:
+		return s.patchFields(ctx, id, userID, map[string]interface{}{"status": op.Status})
+	case domain.BatchMove:
+		return s.patchFields(ctx, id, userID, map[string]interface{}{"list_id": op.ListID})
+	case domain.BatchDelete:
+		return nil, s.DeleteTodo(ctx, id, userID, 0)
+	case domain.BatchTag:
+		todo, err := s.GetTodoByID(ctx, id, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Tag names compare case-insensitively, as in resolveTags
+		removed := make(map[string]bool, len(op.RemoveTags))
+		for _, name := range op.RemoveTags {
+			removed[strings.ToLower(strings.TrimSpace(name))] = true
+		}
+		names := make([]string, 0, len(todo.Tags)+len(op.Tags))
+		for _, tag := range todo.Tags {
+			if !removed[strings.ToLower(tag.Name)] {
+				names = append(names, tag.Name)
+			}
+		}
+		for _, name := range op.Tags {
+			if !removed[strings.ToLower(strings.TrimSpace(name))] {
+				names = append(names, name)
+			}
+		}
+
+		if err := s.setTags(ctx, todo, names); err != nil {
+			return nil, fmt.Errorf("failed to tag todo: %w", err)
+		}
+		return todo, nil
+	default:
+		return nil, &domain.ValidationError{Field: "op", Message: fmt.Sprintf("unknown operation %q", op.Op)}
+	}
+}
+
+// patchFields merges the given fields into a todo.
+func (s *todoService) patchFields(ctx context.Context, id int, userID int, fields map[string]interface{}) (*domain.Todo, error) {
+	patch, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	return s.PatchTodo(ctx, id, domain.PatchTodoRequest{Format: domain.PatchMerge, Patch: patch}, userID)
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+)
+
+type checklistService struct {
+	checklistRepo ports.ChecklistRepository
+	workflowRepo  ports.WorkflowRepository
+	todoService   ports.TodoService
+}
+
+// NewChecklistService builds on the todo service for ownership checks and so
+// that auto-completing a todo goes through the normal update path (which,
+// for instance, schedules the next occurrence of a recurring todo).
+func NewChecklistService(checklistRepo ports.ChecklistRepository, workflowRepo ports.WorkflowRepository, todoService ports.TodoService) ports.ChecklistService {
+	return &checklistService{
+		checklistRepo: checklistRepo,
+		workflowRepo:  workflowRepo,
+		todoService:   todoService,
+	}
+}
+
+func (s *checklistService) AddItem(ctx context.Context, todoID int, req domain.CreateChecklistItemRequest, userID int) (*domain.Todo, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.todoService.GetTodoByID(ctx, todoID, userID); err != nil {
+		return nil, err
+	}
+
+	item := &domain.ChecklistItem{
+		TodoID: todoID,
+		Text:   strings.TrimSpace(req.Text),
+	}
+	if err := s.checklistRepo.Create(ctx, item); err != nil {
+		return nil, fmt.Errorf("failed to add checklist item: %w", err)
+	}
+
+	// A new unchecked item never completes the todo
+	return s.todoService.GetTodoByID(ctx, todoID, userID)
+}
+
+func (s *checklistService) UpdateItem(ctx context.Context, todoID, itemID int, req domain.UpdateChecklistItemRequest, userID int) (*domain.Todo, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	item, err := s.getItem(ctx, todoID, itemID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Text != nil {
+		item.Text = strings.TrimSpace(*req.Text)
+	}
+	if req.Done != nil {
+		item.Done = *req.Done
+	}
+
+	if err := s.checklistRepo.Update(ctx, item); err != nil {
+		return nil, fmt.Errorf("failed to update checklist item: %w", err)
+	}
+
+	return s.completeIfDone(ctx, todoID, userID)
+}
+
+func (s *checklistService) ToggleItem(ctx context.Context, todoID, itemID int, userID int) (*domain.Todo, error) {
+	item, err := s.getItem(ctx, todoID, itemID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Done = !item.Done
+	if err := s.checklistRepo.Update(ctx, item); err != nil {
+		return nil, fmt.Errorf("failed to update checklist item: %w", err)
+	}
+
+	return s.completeIfDone(ctx, todoID, userID)
+}
+
+func (s *checklistService) RemoveItem(ctx context.Context, todoID, itemID int, userID int) (*domain.Todo, error) {
+	if _, err := s.getItem(ctx, todoID, itemID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.checklistRepo.Delete(ctx, itemID); err != nil {
+		return nil, fmt.Errorf("failed to remove checklist item: %w", err)
+	}
+
+	// Removing the last open item can finish the checklist
+	return s.completeIfDone(ctx, todoID, userID)
+}
+
+func (s *checklistService) Reorder(ctx context.Context, todoID int, req domain.ReorderChecklistRequest, userID int) (*domain.Todo, error) {
+	todo, err := s.todoService.GetTodoByID(ctx, todoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The new order has to mention every item exactly once
+	if len(req.ItemIDs) != len(todo.Checklist) {
+		return nil, &domain.ValidationError{Field: "item_ids", Message: "must list every checklist item"}
+	}
+	known := make(map[int]bool, len(todo.Checklist))
+	for _, item := range todo.Checklist {
+		known[item.ID] = true
+	}
+	for _, id := range req.ItemIDs {
+		if !known[id] {
+			return nil, &domain.ValidationError{Field: "item_ids", Message: "must list every checklist item exactly once"}
+		}
+		delete(known, id)
+	}
+
+	if err := s.checklistRepo.Reorder(ctx, todoID, req.ItemIDs); err != nil {
+		return nil, fmt.Errorf("failed to reorder checklist: %w", err)
+	}
+
+	return s.todoService.GetTodoByID(ctx, todoID, userID)
+}
+
+// getItem loads an item after checking that its todo belongs to the user.
+func (s *checklistService) getItem(ctx context.Context, todoID, itemID int, userID int) (*domain.ChecklistItem, error) {
+	if _, err := s.todoService.GetTodoByID(ctx, todoID, userID); err != nil {
+		return nil, err
+	}
+
+	item, err := s.checklistRepo.FindByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.TodoID != todoID {
+		return nil, domain.ErrChecklistItemNotFound
+	}
+
+	return item, nil
+}
+
+// completeIfDone moves the todo to its workflow's first complete status when
+// it opted into auto-completion, its whole checklist is ticked off and the
+// workflow allows the move. It returns the current todo.
+func (s *checklistService) completeIfDone(ctx context.Context, todoID int, userID int) (*domain.Todo, error) {
+	todo, err := s.todoService.GetTodoByID(ctx, todoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !todo.AutoComplete || !todo.Progress.Complete() || todo.CompletedAt != nil {
+		return todo, nil
+	}
+
+	workflow, err := effectiveWorkflow(ctx, s.workflowRepo, userID, todo.ListID)
+	if err != nil {
+		return nil, err
+	}
+	status := workflow.CompleteStatus()
+	if !workflow.CanTransition(todo.Status, status) {
+		return todo, nil
+	}
+
+	return s.todoService.UpdateTodo(ctx, todoID, domain.UpdateTodoRequest{
+		Title:       todo.Title,
+		Description: todo.Description,
+		Status:      status,
+	}, userID, nil)
+}
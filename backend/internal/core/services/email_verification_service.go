@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+	"github.com/ChaiyawutTar/MyList/pkg/auth"
+)
+
+// errInvalidVerificationToken reports a verification token that is
+// unknown, used or expired, without telling which.
+var errInvalidVerificationToken = &domain.ValidationError{Field: "token", Code: domain.CodeInvalid, Message: "is invalid or has expired"}
+
+type emailVerificationService struct {
+	userRepo       ports.UserRepository
+	oneTimeTokens  ports.OneTimeTokenRepository
+	transactor     ports.Transactor
+	mailer         ports.Mailer
+	frontendURL    string
+	ttl            time.Duration
+	resendInterval time.Duration
+}
+
+// NewEmailVerificationService returns an EmailVerificationService that
+// mails links to the verification page of frontendURL, each valid for
+// ttl, and sends a user at most one every resendInterval.
+func NewEmailVerificationService(userRepo ports.UserRepository, oneTimeTokens ports.OneTimeTokenRepository, transactor ports.Transactor, mailer ports.Mailer, frontendURL string, ttl, resendInterval time.Duration) ports.EmailVerificationService {
+	return &emailVerificationService{
+		userRepo:       userRepo,
+		oneTimeTokens:  oneTimeTokens,
+		transactor:     transactor,
+		mailer:         mailer,
+		frontendURL:    frontendURL,
+		ttl:            ttl,
+		resendInterval: resendInterval,
+	}
+}
+
+func (s *emailVerificationService) SendVerification(ctx context.Context, userID int) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return domain.ErrEmailAlreadyVerified
+	}
+
+	token, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		latest, err := s.oneTimeTokens.FindLatest(ctx, userID, domain.TokenPurposeEmailVerification)
+		if err != nil && !errors.Is(err, domain.ErrOneTimeTokenNotFound) {
+			return err
+		}
+		if latest != nil {
+			if wait := latest.CreatedAt.Add(s.resendInterval).Sub(now); wait > 0 {
+				return &domain.ThrottledError{
+					Code:       "verification_throttled",
+					Message:    "a verification email was sent recently; try again later",
+					RetryAfter: wait,
+				}
+			}
+		}
+
+		if err := s.oneTimeTokens.ConsumeAll(ctx, userID, domain.TokenPurposeEmailVerification, now); err != nil {
+			return err
+		}
+		return s.oneTimeTokens.Create(ctx, &domain.OneTimeToken{
+			UserID:    userID,
+			Purpose:   domain.TokenPurposeEmailVerification,
+			TokenHash: hash,
+			ExpiresAt: now.Add(s.ttl),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.frontendURL, url.QueryEscape(token))
+	return s.mailer.Send(ctx, domain.Email{
+		To:      user.Email,
+		Subject: "Verify your MyList email address",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"To confirm that this is the email address of your MyList account, open this link within %s:\n\n%s\n\n"+
+			"If you did not sign up for MyList, ignore this email.\n",
+			user.Username, humanDuration(s.ttl), link),
+	})
+}
+
+func (s *emailVerificationService) VerifyEmail(ctx context.Context, req domain.VerifyEmailRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		token, err := s.oneTimeTokens.FindByHash(ctx, domain.TokenPurposeEmailVerification, auth.HashToken(req.Token))
+		if errors.Is(err, domain.ErrOneTimeTokenNotFound) {
+			return errInvalidVerificationToken
+		}
+		if err != nil {
+			return err
+		}
+		if token.UsedAt != nil || !now.Before(token.ExpiresAt) {
+			return errInvalidVerificationToken
+		}
+		consumed, err := s.oneTimeTokens.Consume(ctx, token.ID, now)
+		if err != nil {
+			return err
+		}
+		if !consumed {
+			return errInvalidVerificationToken
+		}
+
+		return s.userRepo.MarkEmailVerified(ctx, token.UserID, now)
+	})
+}
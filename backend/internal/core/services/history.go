@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+)
+
+func (s *todoService) GetTodoHistory(ctx context.Context, id int, userID int) ([]domain.TodoRevision, error) {
+	todo, err := s.todoRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify ownership; trashed todos keep their history
+	if todo.UserID != userID {
+		return nil, domain.ErrNotOwner
+	}
+
+	revisions, err := s.revisionRepo.FindByTodoID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Newest first
+	for i, j := 0, len(revisions)-1; i < j; i, j = i+1, j-1 {
+		revisions[i], revisions[j] = revisions[j], revisions[i]
+	}
+	return revisions, nil
+}
+
+func (s *todoService) RevertTodo(ctx context.Context, id int, rev int, userID int) (*domain.Todo, error) {
+	todo, err := s.GetTodoByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.revisionRepo.FindByTodoID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	current := *todo
+	if err := domain.RevertTodo(todo, revisions, rev); err != nil {
+		return nil, err
+	}
+
+	// The old list may be gone by now, in which case the todo stays put
+	if todo.ListID != current.ListID {
+		if _, err := getOwnedList(ctx, s.listRepo, todo.ListID, userID); err != nil {
+			todo.ListID = current.ListID
+		} else if todo.Position, err = endOfList(ctx, s.todoRepo, userID, todo.ListID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Likewise a status the workflow no longer has is not restored
+	workflow, err := effectiveWorkflow(ctx, s.workflowRepo, userID, todo.ListID)
+	if err != nil {
+		return nil, err
+	}
+	if workflow.Status(todo.Status) == nil {
+		todo.Status = current.Status
+		if workflow.Status(todo.Status) == nil {
+			todo.Status = workflow.InitialStatus()
+		}
+	}
+	// Going back to an old status is a move like any other, so the workflow
+	// has to allow it, as for UpdateTodo
+	if workflow.Status(current.Status) != nil {
+		if err := workflow.CheckTransition(current.Status, todo.Status); err != nil {
+			return nil, err
+		}
+	}
+
+	if todo.RecurrenceRule != "" && todo.RecurrenceIndex == 0 {
+		todo.RecurrenceIndex = 1
+	}
+	if err := validateSchedule(todo.StartAt, todo.DueAt); err != nil {
+		return nil, err
+	}
+	applyCompletion(todo, workflow, time.Now())
+
+	err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.todoRepo.Update(ctx, todo); err != nil {
+			return fmt.Errorf("failed to revert todo: %w", err)
+		}
+
+		// Completing a recurring todo schedules the next one
+		if current.CompletedAt == nil && todo.CompletedAt != nil && todo.RecurrenceRule != "" {
+			if err := s.spawnNextOccurrence(ctx, todo, workflow); err != nil {
+				return fmt.Errorf("failed to schedule next occurrence: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	todo.Overdue = isOverdue(todo, time.Now(), s.userLocation(ctx, userID))
+	return todo, nil
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+)
+
+type listService struct {
+	listRepo     ports.ListRepository
+	todoRepo     ports.TodoRepository
+	imageRepo    ports.ImageRepository
+	workflowRepo ports.WorkflowRepository
+}
+
+func NewListService(listRepo ports.ListRepository, todoRepo ports.TodoRepository, imageRepo ports.ImageRepository, workflowRepo ports.WorkflowRepository) ports.ListService {
+	return &listService{
+		listRepo:     listRepo,
+		todoRepo:     todoRepo,
+		imageRepo:    imageRepo,
+		workflowRepo: workflowRepo,
+	}
+}
+
+func (s *listService) GetAllLists(ctx context.Context, userID int) ([]domain.List, error) {
+	// Make sure the inbox shows up even for users who never created a todo
+	if _, err := ensureInbox(ctx, s.listRepo, userID); err != nil {
+		return nil, err
+	}
+	return s.listRepo.FindAll(ctx, userID)
+}
+
+func (s *listService) GetListByID(ctx context.Context, id int, userID int) (*domain.List, error) {
+	return getOwnedList(ctx, s.listRepo, id, userID)
+}
+
+func (s *listService) CreateList(ctx context.Context, req domain.CreateListRequest, userID int) (*domain.List, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	list := &domain.List{
+		UserID: userID,
+		Name:   strings.TrimSpace(req.Name),
+	}
+	if err := s.listRepo.Create(ctx, list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (s *listService) UpdateList(ctx context.Context, id int, req domain.UpdateListRequest, userID int) (*domain.List, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	list, err := getOwnedList(ctx, s.listRepo, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	list.Name = strings.TrimSpace(req.Name)
+	if err := s.listRepo.Update(ctx, list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (s *listService) DeleteList(ctx context.Context, id int, userID int, mode string) error {
+	list, err := getOwnedList(ctx, s.listRepo, id, userID)
+	if err != nil {
+		return err
+	}
+	if list.IsInbox {
+		return domain.Conflict("inbox_protected", "the inbox cannot be deleted")
+	}
+
+	switch mode {
+	case "", domain.ListDeleteReassign:
+		inbox, err := ensureInbox(ctx, s.listRepo, userID)
+		if err != nil {
+			return err
+		}
+		from, err := effectiveWorkflow(ctx, s.workflowRepo, userID, list.ID)
+		if err != nil {
+			return err
+		}
+		to, err := effectiveWorkflow(ctx, s.workflowRepo, userID, inbox.ID)
+		if err != nil {
+			return err
+		}
+
+		// Statuses carry over as they are when both lists share a workflow
+		if from.ID == to.ID {
+			if err := s.listRepo.MoveTodos(ctx, list.ID, inbox.ID); err != nil {
+				return fmt.Errorf("failed to move todos to inbox: %w", err)
+			}
+			break
+		}
+		page, err := s.todoRepo.FindAll(ctx, domain.TodoQuery{
+			UserID:          userID,
+			ListID:          list.ID,
+			IncludeArchived: true,
+			Sort:            domain.SortCreatedAt,
+			Order:           domain.SortDesc,
+		})
+		if err != nil {
+			return err
+		}
+		for i := range page.Todos {
+			if err := s.moveTodo(ctx, &page.Todos[i], inbox.ID, to); err != nil {
+				return err
+			}
+		}
+
+	case domain.ListDeleteCascade:
+		// Trashed todos go too, or their images would be left behind
+		for _, trashed := range []bool{false, true} {
+			page, err := s.todoRepo.FindAll(ctx, domain.TodoQuery{
+				UserID:          userID,
+				ListID:          list.ID,
+				Trashed:         trashed,
+				IncludeArchived: true,
+				Sort:            domain.SortCreatedAt,
+				Order:           domain.SortDesc,
+			})
+			if err != nil {
+				return err
+			}
+			for i := range page.Todos {
+				if err := purgeTodo(ctx, s.todoRepo, s.imageRepo, &page.Todos[i]); err != nil {
+					return fmt.Errorf("failed to delete todo %d: %w", page.Todos[i].ID, err)
+				}
+			}
+		}
+
+	default:
+		return &domain.ValidationError{Field: "mode", Message: fmt.Sprintf("must be %s or %s", domain.ListDeleteReassign, domain.ListDeleteCascade)}
+	}
+
+	return s.listRepo.Delete(ctx, list.ID)
+}
+
+func (s *listService) MoveTodos(ctx context.Context, listID int, req domain.MoveTodosRequest, userID int) error {
+	if _, err := getOwnedList(ctx, s.listRepo, listID, userID); err != nil {
+		return err
+	}
+	workflow, err := effectiveWorkflow(ctx, s.workflowRepo, userID, listID)
+	if err != nil {
+		return err
+	}
+
+	// Check every todo before moving any of them
+	todos := make([]*domain.Todo, 0, len(req.TodoIDs))
+	for _, id := range req.TodoIDs {
+		todo, err := s.todoRepo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if todo.UserID != userID {
+			return domain.ErrNotOwner
+		}
+		todos = append(todos, todo)
+	}
+
+	for _, todo := range todos {
+		if todo.ListID == listID {
+			continue
+		}
+		if err := s.moveTodo(ctx, todo, listID, workflow); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveTodo puts a todo at the end of a list. A status the list's workflow
+// does not know is reset to the workflow's initial status.
+func (s *listService) moveTodo(ctx context.Context, todo *domain.Todo, listID int, workflow *domain.Workflow) error {
+	position, err := endOfList(ctx, s.todoRepo, todo.UserID, listID)
+	if err != nil {
+		return err
+	}
+	todo.ListID = listID
+	todo.Position = position
+	if workflow.Status(todo.Status) == nil {
+		todo.Status = workflow.InitialStatus()
+	}
+	applyCompletion(todo, workflow, time.Now())
+
+	if err := s.todoRepo.Update(ctx, todo); err != nil {
+		return fmt.Errorf("failed to move todo %d: %w", todo.ID, err)
+	}
+	return nil
+}
+
+func getOwnedList(ctx context.Context, listRepo ports.ListRepository, id int, userID int) (*domain.List, error) {
+	list, err := listRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify ownership
+	if list.UserID != userID {
+		return nil, domain.ErrNotOwner
+	}
+
+	return list, nil
+}
+
+// ensureInbox returns the user's inbox, creating it on first use.
+func ensureInbox(ctx context.Context, listRepo ports.ListRepository, userID int) (*domain.List, error) {
+	inbox, err := listRepo.FindInbox(ctx, userID)
+	if err == nil {
+		return inbox, nil
+	}
+
+	inbox = &domain.List{
+		UserID:  userID,
+		Name:    domain.InboxListName,
+		IsInbox: true,
+	}
+	if err := listRepo.Create(ctx, inbox); err != nil {
+		// Another request may have created it in the meantime
+		if existing, findErr := listRepo.FindInbox(ctx, userID); findErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+
+	return inbox, nil
+}
@@ -0,0 +1,333 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+	"github.com/ChaiyawutTar/MyList/pkg/auth"
+)
+
+const (
+	// mfaIssuer names the account in authenticator apps.
+	mfaIssuer = "MyList"
+	// mfaSkew is how many time steps a code may be off, either way, for
+	// clocks that drift.
+	mfaSkew = 1
+	// After mfaMaxFailures wrong codes in a row, codes are refused for
+	// mfaLockout, which keeps guessing the six digits out of reach.
+	mfaMaxFailures = 5
+	mfaLockout     = 15 * time.Minute
+	// mfaChallengeTTL is how long a challenged login may take to finish.
+	mfaChallengeTTL = 5 * time.Minute
+)
+
+type mfaService struct {
+	userRepo      ports.UserRepository
+	mfaRepo       ports.MFARepository
+	recoveryCodes ports.RecoveryCodeRepository
+	oneTimeTokens ports.OneTimeTokenRepository
+	tokenService  ports.TokenService
+	transactor    ports.Transactor
+}
+
+func NewMFAService(userRepo ports.UserRepository, mfaRepo ports.MFARepository, recoveryCodes ports.RecoveryCodeRepository, oneTimeTokens ports.OneTimeTokenRepository, tokenService ports.TokenService, transactor ports.Transactor) ports.MFAService {
+	return &mfaService{
+		userRepo:      userRepo,
+		mfaRepo:       mfaRepo,
+		recoveryCodes: recoveryCodes,
+		oneTimeTokens: oneTimeTokens,
+		tokenService:  tokenService,
+		transactor:    transactor,
+	}
+}
+
+func (s *mfaService) GetStatus(ctx context.Context, userID int) (*domain.MFAStatus, error) {
+	mfa, err := s.enabled(ctx, userID)
+	if errors.Is(err, domain.ErrMFANotEnabled) {
+		return &domain.MFAStatus{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	left, err := s.recoveryCodes.CountUnused(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.MFAStatus{Enabled: true, EnabledAt: mfa.EnabledAt, RecoveryCodesLeft: left}, nil
+}
+
+func (s *mfaService) EnrollTOTP(ctx context.Context, userID int) (*domain.TOTPEnrollment, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.mfaRepo.FindByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, domain.ErrMFANotFound) {
+		return nil, err
+	}
+	if current != nil && current.EnabledAt != nil {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.Save(ctx, &domain.MFA{UserID: userID, Secret: secret}); err != nil {
+		return nil, err
+	}
+	return &domain.TOTPEnrollment{
+		Secret:     secret,
+		OTPAuthURI: auth.TOTPURI(mfaIssuer, user.Email, secret),
+	}, nil
+}
+
+func (s *mfaService) ConfirmTOTP(ctx context.Context, userID int, req domain.MFACodeRequest) (*domain.RecoveryCodes, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	// Only a code shows that the app was set up right
+	if req.Code == "" {
+		return nil, &domain.ValidationError{Field: "code", Code: domain.CodeRequired, Message: "is required to confirm MFA"}
+	}
+
+	mfa, err := s.mfaRepo.FindByUserID(ctx, userID)
+	if errors.Is(err, domain.ErrMFANotFound) {
+		return nil, domain.ErrMFANotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if mfa.EnabledAt != nil {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+	if err := s.verify(ctx, mfa, req); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.mfaRepo.Enable(ctx, userID, time.Now()); err != nil {
+			return err
+		}
+		return s.recoveryCodes.Replace(ctx, userID, hashes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.RecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+func (s *mfaService) Disable(ctx context.Context, userID int, req domain.MFACodeRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	mfa, err := s.enabled(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.verify(ctx, mfa, req); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.mfaRepo.Delete(ctx, userID); err != nil {
+			return err
+		}
+		return s.recoveryCodes.DeleteAll(ctx, userID)
+	})
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userID int, req domain.MFACodeRequest) (*domain.RecoveryCodes, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	mfa, err := s.enabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verify(ctx, mfa, req); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recoveryCodes.Replace(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return &domain.RecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+func (s *mfaService) Challenge(ctx context.Context, userID int) (*domain.MFAChallenge, error) {
+	_, err := s.enabled(ctx, userID)
+	if errors.Is(err, domain.ErrMFANotEnabled) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	token, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	err = s.oneTimeTokens.Create(ctx, &domain.OneTimeToken{
+		UserID:    userID,
+		Purpose:   domain.TokenPurposeMFAChallenge,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(mfaChallengeTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.MFAChallenge{
+		Status:    "mfa_required",
+		MFAToken:  token,
+		ExpiresIn: int(mfaChallengeTTL.Seconds()),
+	}, nil
+}
+
+func (s *mfaService) VerifyLogin(ctx context.Context, req domain.MFAVerifyRequest) (*domain.AuthResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.oneTimeTokens.FindByHash(ctx, domain.TokenPurposeMFAChallenge, auth.HashToken(req.MFAToken))
+	if errors.Is(err, domain.ErrOneTimeTokenNotFound) {
+		return nil, domain.ErrInvalidMFAToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if challenge.UsedAt != nil || !time.Now().Before(challenge.ExpiresAt) {
+		return nil, domain.ErrInvalidMFAToken
+	}
+
+	// MFA turned off since the challenge leaves nothing to verify against
+	mfa, err := s.enabled(ctx, challenge.UserID)
+	if errors.Is(err, domain.ErrMFANotEnabled) {
+		return nil, domain.ErrInvalidMFAToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verify(ctx, mfa, req.MFACodeRequest); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+	var tokens *domain.TokenPair
+	err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		consumed, err := s.oneTimeTokens.Consume(ctx, challenge.ID, time.Now())
+		if err != nil {
+			return err
+		}
+		if !consumed {
+			return domain.ErrInvalidMFAToken
+		}
+		tokens, err = s.tokenService.Issue(ctx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AuthResponse{
+		TokenPair: *tokens,
+		User:      *user,
+	}, nil
+}
+
+// enabled returns the user's MFA settings, or domain.ErrMFANotEnabled if
+// MFA is off or was never confirmed.
+func (s *mfaService) enabled(ctx context.Context, userID int) (*domain.MFA, error) {
+	mfa, err := s.mfaRepo.FindByUserID(ctx, userID)
+	if errors.Is(err, domain.ErrMFANotFound) {
+		return nil, domain.ErrMFANotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if mfa.EnabledAt == nil {
+		return nil, domain.ErrMFANotEnabled
+	}
+	return mfa, nil
+}
+
+// verify checks the TOTP code or recovery code of req. Recovery codes only
+// count once MFA is enabled. A wrong code is counted towards a lock, which
+// is written even though verify fails, so it must not run inside a
+// transaction that the failure would roll back.
+func (s *mfaService) verify(ctx context.Context, mfa *domain.MFA, req domain.MFACodeRequest) error {
+	now := time.Now()
+	if mfa.LockedUntil != nil && now.Before(*mfa.LockedUntil) {
+		return &domain.ThrottledError{
+			Code:       "mfa_locked",
+			Message:    "too many wrong codes; try again later",
+			RetryAfter: mfa.LockedUntil.Sub(now),
+		}
+	}
+
+	ok := false
+	var err error
+	if req.Code != "" {
+		if step, match := auth.MatchTOTP(mfa.Secret, req.Code, now, mfaSkew); match {
+			// A code already used, here or by someone who saw it, fails
+			ok, err = s.mfaRepo.UseStep(ctx, mfa.UserID, step)
+		}
+	} else if mfa.EnabledAt != nil {
+		hash := auth.HashToken(auth.NormalizeRecoveryCode(req.RecoveryCode))
+		ok, err = s.recoveryCodes.Use(ctx, mfa.UserID, hash, now)
+	}
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		if mfa.FailedAttempts == 0 {
+			return nil
+		}
+		return s.mfaRepo.ClearFailures(ctx, mfa.UserID)
+	}
+
+	failures, err := s.mfaRepo.AddFailure(ctx, mfa.UserID)
+	if err != nil {
+		return err
+	}
+	if failures >= mfaMaxFailures {
+		if err := s.mfaRepo.Lock(ctx, mfa.UserID, now.Add(mfaLockout)); err != nil {
+			return err
+		}
+	}
+	field := "code"
+	if req.Code == "" {
+		field = "recovery_code"
+	}
+	return &domain.ValidationError{Field: field, Code: domain.CodeInvalid, Message: "is invalid or was already used"}
+}
+
+// newRecoveryCodes returns a fresh set of recovery codes and the hashes to
+// store in their place.
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	codes = make([]string, domain.RecoveryCodeCount)
+	hashes = make([]string, domain.RecoveryCodeCount)
+	for i := range codes {
+		if codes[i], err = auth.NewRecoveryCode(); err != nil {
+			return nil, nil, err
+		}
+		hashes[i] = auth.HashToken(auth.NormalizeRecoveryCode(codes[i]))
+	}
+	return codes, hashes, nil
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+	"github.com/ChaiyawutTar/MyList/pkg/auth"
+)
+
+// maxPendingResetMails bounds the reset links being stored and mailed at
+// once. Requests beyond it are dropped rather than queued, so a flood of
+// them cannot pile up goroutines and SMTP connections.
+const maxPendingResetMails = 8
+
+// errInvalidResetToken reports a reset token that is unknown, used or
+// expired, without telling which.
+var errInvalidResetToken = &domain.ValidationError{Field: "token", Code: domain.CodeInvalid, Message: "is invalid or has expired"}
+
+type passwordService struct {
+	userRepo      ports.UserRepository
+	oneTimeTokens ports.OneTimeTokenRepository
+	tokenService  ports.TokenService
+	transactor    ports.Transactor
+	mailer        ports.Mailer
+	frontendURL   string
+	resetTTL      time.Duration
+	resetInterval time.Duration
+	// pending holds a slot for each reset link being sent.
+	pending chan struct{}
+}
+
+// NewPasswordService returns a PasswordService that mails links to the
+// reset page of frontendURL, each valid for resetTTL, and sends a user at
+// most one every resetInterval.
+func NewPasswordService(userRepo ports.UserRepository, oneTimeTokens ports.OneTimeTokenRepository, tokenService ports.TokenService, transactor ports.Transactor, mailer ports.Mailer, frontendURL string, resetTTL, resetInterval time.Duration) ports.PasswordService {
+	return &passwordService{
+		userRepo:      userRepo,
+		oneTimeTokens: oneTimeTokens,
+		tokenService:  tokenService,
+		transactor:    transactor,
+		mailer:        mailer,
+		frontendURL:   frontendURL,
+		resetTTL:      resetTTL,
+		resetInterval: resetInterval,
+		pending:       make(chan struct{}, maxPendingResetMails),
+	}
+}
+
+func (s *passwordService) ForgotPassword(ctx context.Context, req domain.ForgotPasswordRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, req.Email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Storing the token and mailing the link would make the answer slower
+	// for emails that have an account, so they happen after it; failures,
+	// and requests that are throttled or dropped, are only logged, as
+	// reporting them would tell that the account exists
+	select {
+	case s.pending <- struct{}{}:
+	default:
+		log.Printf("Dropped password reset for user %d: too many being sent", user.ID)
+		return nil
+	}
+	go func() {
+		defer func() { <-s.pending }()
+		if err := s.sendResetLink(context.WithoutCancel(ctx), user); err != nil {
+			log.Printf("Error mailing password reset to user %d: %v", user.ID, err)
+		}
+	}()
+	return nil
+}
+
+// sendResetLink mails the user a new reset link, unless they were sent one
+// less than resetInterval ago.
+func (s *passwordService) sendResetLink(ctx context.Context, user *domain.User) error {
+	token, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	// Only the latest link works, so an older one lying around in a
+	// mailbox cannot be used after a new one was asked for
+	now := time.Now()
+	throttled := false
+	err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		latest, err := s.oneTimeTokens.FindLatest(ctx, user.ID, domain.TokenPurposePasswordReset)
+		if err != nil && !errors.Is(err, domain.ErrOneTimeTokenNotFound) {
+			return err
+		}
+		if latest != nil && now.Before(latest.CreatedAt.Add(s.resetInterval)) {
+			throttled = true
+			return nil
+		}
+
+		if err := s.oneTimeTokens.ConsumeAll(ctx, user.ID, domain.TokenPurposePasswordReset, now); err != nil {
+			return err
+		}
+		return s.oneTimeTokens.Create(ctx, &domain.OneTimeToken{
+			UserID:    user.ID,
+			Purpose:   domain.TokenPurposePasswordReset,
+			TokenHash: hash,
+			ExpiresAt: now.Add(s.resetTTL),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if throttled {
+		log.Printf("Throttled password reset for user %d: one was sent recently", user.ID)
+		return nil
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.frontendURL, url.QueryEscape(token))
+	return s.mailer.Send(ctx, domain.Email{
+		To:      user.Email,
+		Subject: "Reset your MyList password",
+		Body: fmt.Sprintf("Someone asked to reset the password of your MyList account.\n\n"+
+			"To choose a new password, open this link within %s:\n\n%s\n\n"+
+			"If it was not you, ignore this email and your password stays the same.\n",
+			humanDuration(s.resetTTL), link),
+	})
+}
+
+func (s *passwordService) ResetPassword(ctx context.Context, req domain.ResetPasswordRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		token, err := s.oneTimeTokens.FindByHash(ctx, domain.TokenPurposePasswordReset, auth.HashToken(req.Token))
+		if errors.Is(err, domain.ErrOneTimeTokenNotFound) {
+			return errInvalidResetToken
+		}
+		if err != nil {
+			return err
+		}
+		if token.UsedAt != nil || !now.Before(token.ExpiresAt) {
+			return errInvalidResetToken
+		}
+		consumed, err := s.oneTimeTokens.Consume(ctx, token.ID, now)
+		if err != nil {
+			return err
+		}
+		if !consumed {
+			return errInvalidResetToken
+		}
+
+		if err := s.userRepo.UpdatePassword(ctx, token.UserID, req.Password); err != nil {
+			return err
+		}
+		// Whoever knew the old password is logged out
+		_, err = s.tokenService.RevokeUserSessions(ctx, token.UserID, "")
+		return err
+	})
+}
+
+// humanDuration spells out d for the text of an email, such as "1 hour"
+// or "30 minutes".
+func humanDuration(d time.Duration) string {
+	n, unit := int(d/time.Minute), "minute"
+	if d%time.Hour == 0 {
+		n, unit = int(d/time.Hour), "hour"
+	}
+	if n == 0 || d%time.Minute != 0 {
+		return d.String()
+	}
+	if n != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s", n, unit)
+}
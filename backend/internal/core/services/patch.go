@@ -0,0 +1,90 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/pkg/jsonpatch"
+)
+
+// PatchTodo applies a patch to the todo's document and saves the result
+// through UpdateTodo, so the merged todo is validated like any update.
+func (s *todoService) PatchTodo(ctx context.Context, id int, req domain.PatchTodoRequest, userID int) (*domain.Todo, error) {
+	todo, err := s.GetTodoByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Version != 0 && req.Version != todo.Version {
+		return nil, domain.ErrVersionConflict
+	}
+
+	current := todo.Document()
+	encoded, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+
+	var patched []byte
+	switch req.Format {
+	case domain.PatchMerge:
+		patched, err = jsonpatch.MergePatch(encoded, req.Patch)
+	case domain.PatchJSON:
+		patched, err = jsonpatch.Apply(encoded, req.Patch)
+	default:
+		return nil, &domain.ValidationError{Field: "format", Message: fmt.Sprintf("unknown patch format %q", req.Format)}
+	}
+	if err != nil {
+		return nil, &domain.ValidationError{Field: "patch", Message: err.Error()}
+	}
+
+	var doc domain.TodoDocument
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, &domain.ValidationError{Field: "patch", Message: err.Error()}
+	}
+
+	if doc.ImageID != nil && *doc.ImageID != todo.ImageID {
+		return nil, &domain.ValidationError{Field: "image_id", Message: "can only be removed; upload a new image with PUT"}
+	}
+
+	update := domain.UpdateTodoRequest{
+		Title:          doc.Title,
+		Description:    doc.Description,
+		Status:         doc.Status,
+		StartAt:        doc.StartAt,
+		DueAt:          doc.DueAt,
+		ClearStartAt:   doc.StartAt == nil,
+		ClearDueAt:     doc.DueAt == nil,
+		RemoveImage:    doc.ImageID == nil,
+		RecurrenceRule: &doc.RecurrenceRule,
+		ListID:         &doc.ListID,
+		AutoComplete:   &doc.AutoComplete,
+		Priority:       &doc.Priority,
+		// The patch was applied to this version
+		Version: todo.Version,
+	}
+	if !sameStrings(doc.Tags, current.Tags) {
+		update.Tags = doc.Tags
+		if update.Tags == nil {
+			update.Tags = []string{}
+		}
+	}
+
+	return s.UpdateTodo(ctx, id, update, userID, nil)
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
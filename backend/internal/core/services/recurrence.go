@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/pkg/rrule"
+)
+
+// validateRecurrence checks that a recurrence rule parses. An empty rule is
+// valid and means the todo does not repeat.
+func validateRecurrence(rule string) error {
+	if rule == "" {
+		return nil
+	}
+	if _, err := rrule.Parse(rule); err != nil {
+		return &domain.ValidationError{Field: "recurrence_rule", Message: err.Error()}
+	}
+	return nil
+}
+
+// nextOccurrence builds the todo that follows a completed recurring todo, or
+// returns nil when the series has ended. The caller picks its status. The rule is evaluated in the user's
+// time zone so that "every Monday 9:00" survives DST changes.
+func nextOccurrence(todo *domain.Todo, loc *time.Location) (*domain.Todo, error) {
+	rule, err := rrule.Parse(todo.RecurrenceRule)
+	if err != nil {
+		return nil, err
+	}
+
+	// Schedule from the due date, falling back to the start or creation time
+	anchor := todo.CreatedAt
+	if todo.DueAt != nil {
+		anchor = *todo.DueAt
+	} else if todo.StartAt != nil {
+		anchor = *todo.StartAt
+	}
+
+	index := todo.RecurrenceIndex
+	if index == 0 {
+		index = 1
+	}
+
+	due, ok := rule.Next(anchor.In(loc), index)
+	if !ok {
+		return nil, nil
+	}
+
+	next := &domain.Todo{
+		UserID:          todo.UserID,
+		ListID:          todo.ListID,
+		Title:           todo.Title,
+		Description:     todo.Description,
+		DueAt:           &due,
+		RecurrenceRule:  todo.RecurrenceRule,
+		SeriesID:        todo.SeriesRootID(),
+		RecurrenceIndex: index + 1,
+		AutoComplete:    todo.AutoComplete,
+		Priority:        todo.Priority,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+
+	// Keep the same lead time between start and due
+	if todo.StartAt != nil {
+		startAt := due.Add(todo.StartAt.Sub(anchor))
+		next.StartAt = &startAt
+	}
+
+	return next, nil
+}
+
+// spawnNextOccurrence creates the next todo of a series once the current one
+// is complete, unless a later occurrence already exists (e.g. the todo was
+// reopened and completed again). The new todo starts in the initial status
+// of the workflow.
+func (s *todoService) spawnNextOccurrence(ctx context.Context, todo *domain.Todo, workflow *domain.Workflow) error {
+	series, err := s.todoRepo.FindSeries(ctx, todo.SeriesRootID())
+	if err != nil {
+		return err
+	}
+	for _, other := range series {
+		if other.RecurrenceIndex > todo.RecurrenceIndex {
+			return nil
+		}
+	}
+
+	next, err := nextOccurrence(todo, s.userLocation(ctx, todo.UserID))
+	if err != nil || next == nil {
+		return err
+	}
+	next.Status = workflow.InitialStatus()
+	if next.Position, err = endOfList(ctx, s.todoRepo, todo.UserID, todo.ListID); err != nil {
+		return err
+	}
+	applyCompletion(next, workflow, next.CreatedAt)
+
+	if err := s.todoRepo.Create(ctx, next); err != nil {
+		return err
+	}
+
+	// The new occurrence carries the same tags and a fresh checklist
+	if err := s.setTags(ctx, next, tagNames(todo.Tags)); err != nil {
+		return err
+	}
+	for _, item := range todo.Checklist {
+		copied := &domain.ChecklistItem{TodoID: next.ID, Text: item.Text}
+		if err := s.checklistRepo.Create(ctx, copied); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateFollowing copies the series-wide fields of todo onto every later
+// occurrence in its series.
+func (s *todoService) updateFollowing(ctx context.Context, todo *domain.Todo) error {
+	series, err := s.todoRepo.FindSeries(ctx, todo.SeriesRootID())
+	if err != nil {
+		return err
+	}
+
+	for i := range series {
+		other := &series[i]
+		if other.ID == todo.ID || other.RecurrenceIndex < todo.RecurrenceIndex {
+			continue
+		}
+
+		other.Title = todo.Title
+		other.Description = todo.Description
+		other.RecurrenceRule = todo.RecurrenceRule
+		if err := s.todoRepo.Update(ctx, other); err != nil {
+			return fmt.Errorf("failed to update occurrence %d: %w", other.ID, err)
+		}
+		if err := s.setTags(ctx, other, tagNames(todo.Tags)); err != nil {
+			return fmt.Errorf("failed to tag occurrence %d: %w", other.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+)
+
+type sessionService struct {
+	sessionRepo  ports.SessionRepository
+	tokenService ports.TokenService
+	tracker      *SessionTracker
+}
+
+// NewSessionService returns a SessionService that shows last-seen times
+// tracker has not written yet.
+func NewSessionService(sessionRepo ports.SessionRepository, tokenService ports.TokenService, tracker *SessionTracker) ports.SessionService {
+	return &sessionService{
+		sessionRepo:  sessionRepo,
+		tokenService: tokenService,
+		tracker:      tracker,
+	}
+}
+
+func (s *sessionService) GetSessions(ctx context.Context, userID int, currentID string) ([]domain.Session, error) {
+	sessions, err := s.sessionRepo.FindActive(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		if seen, ok := s.tracker.lastSeen(sessions[i].ID); ok && seen.After(sessions[i].LastSeenAt) {
+			sessions[i].LastSeenAt = seen
+		}
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+	return sessions, nil
+}
+
+func (s *sessionService) RevokeSession(ctx context.Context, id string, userID int) error {
+	session, err := s.sessionRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Sessions of other users are not found rather than forbidden, so
+	// their IDs cannot be probed
+	if session.UserID != userID || session.RevokedAt != nil {
+		return domain.ErrSessionNotFound
+	}
+	return s.tokenService.RevokeSession(ctx, id)
+}
+
+func (s *sessionService) RevokeOtherSessions(ctx context.Context, userID int, currentID string) (int, error) {
+	return s.tokenService.RevokeUserSessions(ctx, userID, currentID)
+}
+
+// SessionTracker buffers when sessions were last seen and writes them in
+// one batch every interval, so authenticating a request costs no database
+// write. A crash loses at most one interval of last-seen times.
+type SessionTracker struct {
+	sessionRepo ports.SessionRepository
+	interval    time.Duration
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+func NewSessionTracker(sessionRepo ports.SessionRepository, interval time.Duration) *SessionTracker {
+	return &SessionTracker{
+		sessionRepo: sessionRepo,
+		interval:    interval,
+		seen:        make(map[string]time.Time),
+	}
+}
+
+// Touch records that the session was used at the given time.
+func (t *SessionTracker) Touch(sessionID string, at time.Time) {
+	if sessionID == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if at.After(t.seen[sessionID]) {
+		t.seen[sessionID] = at
+	}
+}
+
+// lastSeen returns the buffered last-seen time of a session.
+func (t *SessionTracker) lastSeen(sessionID string) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	seen, ok := t.seen[sessionID]
+	return seen, ok
+}
+
+// Flush writes the buffered times. Times that fail to write are kept for
+// the next flush, unless a newer one came in meanwhile.
+func (t *SessionTracker) Flush(ctx context.Context) error {
+	t.mu.Lock()
+	batch := t.seen
+	t.seen = make(map[string]time.Time)
+	t.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := t.sessionRepo.Touch(ctx, batch); err != nil {
+		for id, at := range batch {
+			t.Touch(id, at)
+		}
+		return err
+	}
+	return nil
+}
+
+// Run flushes every interval until ctx is done, and once more then.
+func (t *SessionTracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := t.Flush(context.Background()); err != nil {
+				log.Printf("Error recording session activity: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := t.Flush(ctx); err != nil {
+				log.Printf("Error recording session activity: %v", err)
+			}
+		}
+	}
+}
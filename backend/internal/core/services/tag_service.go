@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+)
+
+type tagService struct {
+	tagRepo ports.TagRepository
+}
+
+func NewTagService(tagRepo ports.TagRepository) ports.TagService {
+	return &tagService{
+		tagRepo: tagRepo,
+	}
+}
+
+func (s *tagService) GetAllTags(ctx context.Context, userID int) ([]domain.Tag, error) {
+	return s.tagRepo.FindAll(ctx, userID)
+}
+
+func (s *tagService) CreateTag(ctx context.Context, req domain.CreateTagRequest, userID int) (*domain.Tag, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	tag := &domain.Tag{
+		UserID: userID,
+		Name:   strings.TrimSpace(req.Name),
+		Color:  req.Color,
+	}
+	if err := s.tagRepo.Create(ctx, tag); err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (s *tagService) UpdateTag(ctx context.Context, id int, req domain.UpdateTagRequest, userID int) (*domain.Tag, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	tag, err := s.getOwnedTag(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tag.Name = strings.TrimSpace(req.Name)
+	tag.Color = req.Color
+	if err := s.tagRepo.Update(ctx, tag); err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (s *tagService) DeleteTag(ctx context.Context, id int, userID int) error {
+	if _, err := s.getOwnedTag(ctx, id, userID); err != nil {
+		return err
+	}
+
+	return s.tagRepo.Delete(ctx, id)
+}
+
+func (s *tagService) MergeTags(ctx context.Context, targetID int, req domain.MergeTagsRequest, userID int) (*domain.Tag, error) {
+	target, err := s.getOwnedTag(ctx, targetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceIDs := make([]int, 0, len(req.SourceIDs))
+	for _, id := range req.SourceIDs {
+		if id == targetID {
+			continue
+		}
+		if _, err := s.getOwnedTag(ctx, id, userID); err != nil {
+			return nil, err
+		}
+		sourceIDs = append(sourceIDs, id)
+	}
+	if len(sourceIDs) == 0 {
+		return nil, &domain.ValidationError{Field: "source_ids", Message: "must name at least one other tag"}
+	}
+
+	if err := s.tagRepo.Merge(ctx, targetID, sourceIDs); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+func (s *tagService) getOwnedTag(ctx context.Context, id int, userID int) (*domain.Tag, error) {
+	tag, err := s.tagRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify ownership
+	if tag.UserID != userID {
+		return nil, domain.ErrNotOwner
+	}
+
+	return tag, nil
+}
+
+// resolveTags maps tag names to the user's tags, creating any that do not
+// exist yet. Duplicate names (ignoring case) collapse into one tag.
+func resolveTags(ctx context.Context, tagRepo ports.TagRepository, userID int, names []string) ([]domain.Tag, error) {
+	wanted := make([]string, 0, len(names))
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		wanted = append(wanted, name)
+	}
+	if len(wanted) == 0 {
+		return []domain.Tag{}, nil
+	}
+
+	existing, err := tagRepo.FindByNames(ctx, userID, wanted)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]domain.Tag, len(existing))
+	for _, tag := range existing {
+		byName[strings.ToLower(tag.Name)] = tag
+	}
+
+	tags := make([]domain.Tag, 0, len(wanted))
+	for _, name := range wanted {
+		tag, ok := byName[strings.ToLower(name)]
+		if !ok {
+			tag = domain.Tag{UserID: userID, Name: name}
+			if err := tagRepo.Create(ctx, &tag); err != nil {
+				return nil, err
+			}
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+func tagNames(tags []domain.Tag) []string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names
+}
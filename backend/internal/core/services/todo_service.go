@@ -2,35 +2,237 @@ package services
 
 import (
 "context"
-	"errors"
 	"fmt"
 	// "io"
 	"mime/multipart"
+	"strings"
 	// "path/filepath"
 	"time"
 	"github.com/ChaiyawutTar/MyList/internal/core/domain"
 	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+	"github.com/ChaiyawutTar/MyList/pkg/rank"
 )
 
 type todoService struct {
-	todoRepo  ports.TodoRepository
-	imageRepo ports.ImageRepository
+	todoRepo      ports.TodoRepository
+	imageRepo     ports.ImageRepository
+	userRepo      ports.UserRepository
+	tagRepo       ports.TagRepository
+	listRepo      ports.ListRepository
+	checklistRepo ports.ChecklistRepository
+	searcher      ports.TodoSearcher
+	workflowRepo  ports.WorkflowRepository
+	revisionRepo  ports.TodoRevisionRepository
+	transactor    ports.Transactor
 }
 
-func NewTodoService(todoRepo ports.TodoRepository, imageRepo ports.ImageRepository) ports.TodoService {
+func NewTodoService(todoRepo ports.TodoRepository, imageRepo ports.ImageRepository, userRepo ports.UserRepository, tagRepo ports.TagRepository, listRepo ports.ListRepository, checklistRepo ports.ChecklistRepository, searcher ports.TodoSearcher, workflowRepo ports.WorkflowRepository, revisionRepo ports.TodoRevisionRepository, transactor ports.Transactor) ports.TodoService {
 	return &todoService{
-		todoRepo:  todoRepo,
-		imageRepo: imageRepo,
+		todoRepo:      todoRepo,
+		imageRepo:     imageRepo,
+		userRepo:      userRepo,
+		tagRepo:       tagRepo,
+		listRepo:      listRepo,
+		checklistRepo: checklistRepo,
+		searcher:      searcher,
+		workflowRepo:  workflowRepo,
+		revisionRepo:  revisionRepo,
+		transactor:    transactor,
 	}
 }
 
-func (s *todoService) GetAllTodos(ctx context.Context, userID int) ([]domain.Todo, error) {
-    todos, err := s.todoRepo.FindAll(ctx, userID)
+// resolveList returns the list a todo should be stored in, defaulting to
+// the user's inbox.
+func (s *todoService) resolveList(ctx context.Context, listID int, userID int) (*domain.List, error) {
+	if listID == 0 {
+		return ensureInbox(ctx, s.listRepo, userID)
+	}
+	return getOwnedList(ctx, s.listRepo, listID, userID)
+}
+
+// attachRelations loads the tags and checklist progress of each todo, with
+// one query per relation.
+func (s *todoService) attachRelations(ctx context.Context, todos []domain.Todo) error {
+	ids := make([]int, len(todos))
+	for i := range todos {
+		ids[i] = todos[i].ID
+	}
+
+	tagsByTodo, err := s.tagRepo.FindByTodoIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load tags: %w", err)
+	}
+	progress, err := s.checklistRepo.Progress(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load checklist progress: %w", err)
+	}
+
+	for i := range todos {
+		todos[i].Tags = tagsByTodo[todos[i].ID]
+		if todos[i].Tags == nil {
+			todos[i].Tags = []domain.Tag{}
+		}
+		todos[i].Progress = progress[todos[i].ID]
+	}
+	return nil
+}
+
+// loadRelations fills in the tags and full checklist of a single todo.
+func (s *todoService) loadRelations(ctx context.Context, todo *domain.Todo) error {
+	todos := []domain.Todo{*todo}
+	if err := s.attachRelations(ctx, todos); err != nil {
+		return err
+	}
+	todo.Tags = todos[0].Tags
+	todo.Progress = todos[0].Progress
+
+	items, err := s.checklistRepo.FindByTodoID(ctx, todo.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load checklist: %w", err)
+	}
+	todo.Checklist = items
+	return nil
+}
+
+// setTags replaces the tags of a todo by name.
+func (s *todoService) setTags(ctx context.Context, todo *domain.Todo, names []string) error {
+	tags, err := resolveTags(ctx, s.tagRepo, todo.UserID, names)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int, len(tags))
+	for i, tag := range tags {
+		ids[i] = tag.ID
+	}
+	if err := s.tagRepo.SetTodoTags(ctx, todo.ID, ids); err != nil {
+		return err
+	}
+
+	todo.Tags = tags
+	return nil
+}
+
+// userLocation returns the time zone configured for the user, or UTC if
+// none is set or it can no longer be loaded.
+func (s *todoService) userLocation(ctx context.Context, userID int) *time.Location {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil || user.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// isOverdue reports whether an unfinished todo has passed its due date.
+// A due date exactly at midnight in the user's time zone means "some time
+// that day", so it only becomes overdue once the day is over.
+func isOverdue(todo *domain.Todo, now time.Time, loc *time.Location) bool {
+	if todo.DueAt == nil || todo.CompletedAt != nil {
+		return false
+	}
+
+	deadline := todo.DueAt.In(loc)
+	if deadline.Equal(time.Date(deadline.Year(), deadline.Month(), deadline.Day(), 0, 0, 0, 0, loc)) {
+		deadline = deadline.AddDate(0, 0, 1)
+	}
+	return !now.Before(deadline)
+}
+
+// endOfList returns a rank that places a todo after every other todo in the
+// list. Todos added to a list at the same time can get the same rank; the
+// list orders them by ID and MoveTodo spreads them out when it needs room
+// between them.
+func endOfList(ctx context.Context, todoRepo ports.TodoRepository, userID, listID int) (string, error) {
+	page, err := todoRepo.FindAll(ctx, domain.TodoQuery{
+		UserID:          userID,
+		ListID:          listID,
+		IncludeArchived: true,
+		Sort:            domain.SortPosition,
+		Order:           domain.SortDesc,
+		Limit:           1,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	last := ""
+	if len(page.Todos) > 0 {
+		last = page.Todos[0].Position
+	}
+	return rank.Between(last, "")
+}
+
+// validateSchedule checks that a todo does not start after it is due.
+func validateSchedule(startAt, dueAt *time.Time) error {
+	if startAt != nil && dueAt != nil && startAt.After(*dueAt) {
+		return &domain.ValidationError{Field: "start_at", Message: "must not be after due_at"}
+	}
+	return nil
+}
+
+func (s *todoService) GetAllTodos(ctx context.Context, userID int, query domain.TodoQuery) (*domain.TodoPage, error) {
+    loc := s.userLocation(ctx, userID)
+    now := time.Now()
+    query.UserID = userID
+    query.Now = now
+    query.Location = loc
+
+    if err := query.Normalize(); err != nil {
+        return nil, err
+    }
+
+    page, err := s.todoRepo.FindAll(ctx, query)
     if err != nil {
         fmt.Printf("Error in todoService.GetAllTodos: %v\n", err)
         return nil, err
     }
-    return todos, nil
+
+    for i := range page.Todos {
+        page.Todos[i].Overdue = isOverdue(&page.Todos[i], now, loc)
+    }
+    if err := s.attachRelations(ctx, page.Todos); err != nil {
+        return nil, err
+    }
+    return page, nil
+}
+
+func (s *todoService) SearchTodos(ctx context.Context, userID int, query domain.SearchQuery) ([]domain.SearchResult, error) {
+	query.Text = strings.TrimSpace(query.Text)
+	if query.Text == "" {
+		return nil, &domain.ValidationError{Field: "q", Code: domain.CodeRequired, Message: "is required"}
+	}
+	if query.Limit == 0 {
+		query.Limit = domain.DefaultSearchLimit
+	}
+	if query.Limit < 0 || query.Limit > domain.MaxSearchLimit {
+		return nil, &domain.ValidationError{Field: "limit", Message: fmt.Sprintf("must be between 1 and %d", domain.MaxSearchLimit)}
+	}
+	query.UserID = userID
+
+	results, err := s.searcher.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load relations for all hits at once, then copy them back
+	todos := make([]domain.Todo, len(results))
+	for i := range results {
+		todos[i] = results[i].Todo
+	}
+	if err := s.attachRelations(ctx, todos); err != nil {
+		return nil, err
+	}
+
+	now, loc := time.Now(), s.userLocation(ctx, userID)
+	for i := range results {
+		results[i].Todo = todos[i]
+		results[i].Todo.Overdue = isOverdue(&todos[i], now, loc)
+	}
+	return results, nil
 }
 
 func (s *todoService) GetTodoByID(ctx context.Context, id int, userID int) (*domain.Todo, error) {
@@ -41,21 +243,71 @@ func (s *todoService) GetTodoByID(ctx context.Context, id int, userID int) (*dom
 
 	// Verify ownership
 	if todo.UserID != userID {
-		return nil, errors.New("unauthorized")
+		return nil, domain.ErrNotOwner
+	}
+	// Trashed todos are only reachable through the trash
+	if todo.DeletedAt != nil {
+		return nil, domain.ErrTodoNotFound
 	}
 
+	todo.Overdue = isOverdue(todo, time.Now(), s.userLocation(ctx, userID))
+	if err := s.loadRelations(ctx, todo); err != nil {
+		return nil, err
+	}
 	return todo, nil
 }
 func (s *todoService) CreateTodo(ctx context.Context, req domain.CreateTodoRequest, userID int, imageFile *multipart.FileHeader) (*domain.Todo, error) {
+    if err := req.Validate(); err != nil {
+        return nil, err
+    }
+    if err := validateRecurrence(req.RecurrenceRule); err != nil {
+        return nil, err
+    }
+    list, err := s.resolveList(ctx, req.ListID, userID)
+    if err != nil {
+        return nil, err
+    }
+    workflow, err := effectiveWorkflow(ctx, s.workflowRepo, userID, list.ID)
+    if err != nil {
+        return nil, err
+    }
+    if req.Status == "" {
+        req.Status = workflow.InitialStatus()
+    } else if workflow.Status(req.Status) == nil {
+        return nil, &domain.ValidationError{Field: "status", Code: domain.CodeInvalidValue, Message: fmt.Sprintf("unknown status %q in workflow %q", req.Status, workflow.Name)}
+    }
+
+    if req.Priority == "" {
+        req.Priority = domain.PriorityNone
+    }
+    if _, err := domain.PriorityLevel(req.Priority); err != nil {
+        return nil, err
+    }
+    position, err := endOfList(ctx, s.todoRepo, userID, list.ID)
+    if err != nil {
+        return nil, err
+    }
+
     // Create a new todo without the image first
     todo := &domain.Todo{
         UserID:      userID,
+        ListID:      list.ID,
         Title:       req.Title,
         Description: req.Description,
         Status:      req.Status,
+        StartAt:     req.StartAt,
+        DueAt:       req.DueAt,
+        AutoComplete: req.AutoComplete,
+        Priority:    req.Priority,
+        Position:    position,
         CreatedAt:   time.Now(),
         UpdatedAt:   time.Now(),
     }
+    if req.RecurrenceRule != "" {
+        todo.RecurrenceRule = req.RecurrenceRule
+        todo.RecurrenceIndex = 1
+    }
+    applyCompletion(todo, workflow, todo.CreatedAt)
     
     // If there's an image file, process it first before saving the todo
     if imageFile != nil {
@@ -76,7 +328,7 @@ func (s *todoService) CreateTodo(ctx context.Context, req domain.CreateTodoReque
     }
     
     // Now save the todo with the image ID
-    err := s.todoRepo.Create(ctx, todo)  // Changed from createdTodo, err := to err :=
+    err = s.todoRepo.Create(ctx, todo)  // Changed from createdTodo, err := to err :=
     if err != nil {
         // If we saved an image but failed to create the todo, clean up the image
         if todo.ImageID != "" {
@@ -84,11 +336,20 @@ func (s *todoService) CreateTodo(ctx context.Context, req domain.CreateTodoReque
         }
         return nil, fmt.Errorf("failed to create todo: %w", err)
     }
+
+    if err := s.setTags(ctx, todo, req.Tags); err != nil {
+        return nil, fmt.Errorf("failed to tag todo: %w", err)
+    }
     
+    todo.Overdue = isOverdue(todo, time.Now(), s.userLocation(ctx, userID))
     return todo, nil  // Return the todo we created, not createdTodo
 }
 
 func (s *todoService) UpdateTodo(ctx context.Context, todoID int, req domain.UpdateTodoRequest, userID int, imageFile *multipart.FileHeader) (*domain.Todo, error) {
+    if err := req.Validate(); err != nil {
+        return nil, err
+    }
+
     // First, get the existing todo
     existingTodo, err := s.todoRepo.FindByID(ctx, todoID)
     if err != nil {
@@ -97,15 +358,93 @@ func (s *todoService) UpdateTodo(ctx context.Context, todoID int, req domain.Upd
     
     // Check if the todo belongs to the user
     if existingTodo.UserID != userID {
-        return nil, errors.New("unauthorized")
+        return nil, domain.ErrNotOwner
     }
+    if existingTodo.DeletedAt != nil {
+        return nil, domain.ErrTodoNotFound
+    }
+    // Refuse changes based on an outdated copy
+    if req.Version != 0 && req.Version != existingTodo.Version {
+        return nil, domain.ErrVersionConflict
+    }
+
+    switch req.Scope {
+    case "", domain.ScopeThis, domain.ScopeFollowing:
+    default:
+        return nil, &domain.ValidationError{Field: "scope", Message: fmt.Sprintf("unknown scope %q", req.Scope)}
+    }
+    wasComplete := existingTodo.CompletedAt != nil
+    previousStatus := existingTodo.Status
     
     // Update the todo fields
     existingTodo.Title = req.Title
     existingTodo.Description = req.Description
+    if req.StartAt != nil {
+        existingTodo.StartAt = req.StartAt
+    } else if req.ClearStartAt {
+        existingTodo.StartAt = nil
+    }
+    if req.DueAt != nil {
+        existingTodo.DueAt = req.DueAt
+    } else if req.ClearDueAt {
+        existingTodo.DueAt = nil
+    }
+    if req.ListID != nil {
+        list, err := s.resolveList(ctx, *req.ListID, userID)
+        if err != nil {
+            return nil, err
+        }
+        if list.ID != existingTodo.ListID {
+            // A todo moved to another list goes to the end of it
+            if existingTodo.Position, err = endOfList(ctx, s.todoRepo, userID, list.ID); err != nil {
+                return nil, err
+            }
+        }
+        existingTodo.ListID = list.ID
+    }
+
+    // The status has to follow the workflow of the todo's (new) list. A todo
+    // that came from a list with another workflow may enter any status.
+    workflow, err := effectiveWorkflow(ctx, s.workflowRepo, userID, existingTodo.ListID)
+    if err != nil {
+        return nil, err
+    }
+    if workflow.Status(previousStatus) == nil {
+        previousStatus = req.Status
+    }
+    if err := workflow.CheckTransition(previousStatus, req.Status); err != nil {
+        return nil, err
+    }
     existingTodo.Status = req.Status
+    if req.AutoComplete != nil {
+        existingTodo.AutoComplete = *req.AutoComplete
+    }
+    if req.Priority != nil {
+        if _, err := domain.PriorityLevel(*req.Priority); err != nil {
+            return nil, err
+        }
+        existingTodo.Priority = *req.Priority
+    }
+    if req.RecurrenceRule != nil {
+        if err := validateRecurrence(*req.RecurrenceRule); err != nil {
+            return nil, err
+        }
+        existingTodo.RecurrenceRule = *req.RecurrenceRule
+        if existingTodo.RecurrenceRule != "" && existingTodo.RecurrenceIndex == 0 {
+            existingTodo.RecurrenceIndex = 1
+        }
+    }
     existingTodo.UpdatedAt = time.Now()
+    applyCompletion(existingTodo, workflow, existingTodo.UpdatedAt)
+
+    if err := validateSchedule(existingTodo.StartAt, existingTodo.DueAt); err != nil {
+        return nil, err
+    }
     
+    // Images replaced or removed are deleted once the todo no longer points
+    // at them
+    removedImageID := ""
+
     // If there's a new image file, process it
     if imageFile != nil {
         file, err := imageFile.Open()
@@ -120,47 +459,233 @@ func (s *todoService) UpdateTodo(ctx context.Context, todoID int, req domain.Upd
             return nil, fmt.Errorf("failed to save image: %w", err)
         }
         
-        // If there was an existing image, delete it
-        if existingTodo.ImageID != "" {
-            _ = s.imageRepo.Delete(ctx, existingTodo.ImageID) // Best effort cleanup
-        }
+        removedImageID = existingTodo.ImageID
         
         // Set the new image ID
         existingTodo.ImageID = imageID
     }
+
+    if imageFile == nil && req.RemoveImage {
+        removedImageID = existingTodo.ImageID
+        existingTodo.ImageID = ""
+    }
     
-    // Update the todo in the database
-    err = s.todoRepo.Update(ctx, existingTodo)  // Changed from updatedTodo, err := to err :=
+    // The todo, its tags, the rest of its series and the next occurrence
+    // change together or not at all
+    err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+        if err := s.todoRepo.Update(ctx, existingTodo); err != nil {
+            return fmt.Errorf("failed to update todo: %w", err)
+        }
+
+        if req.Tags != nil {
+            if err := s.setTags(ctx, existingTodo, req.Tags); err != nil {
+                return fmt.Errorf("failed to tag todo: %w", err)
+            }
+        }
+        if err := s.loadRelations(ctx, existingTodo); err != nil {
+            return err
+        }
+
+        if req.Scope == domain.ScopeFollowing && existingTodo.RecurrenceIndex > 0 {
+            if err := s.updateFollowing(ctx, existingTodo); err != nil {
+                return fmt.Errorf("failed to update following occurrences: %w", err)
+            }
+        }
+
+        // Completing a recurring todo schedules the next one
+        if !wasComplete && existingTodo.CompletedAt != nil && existingTodo.RecurrenceRule != "" {
+            if err := s.spawnNextOccurrence(ctx, existingTodo, workflow); err != nil {
+                return fmt.Errorf("failed to schedule next occurrence: %w", err)
+            }
+        }
+        return nil
+    })
     if err != nil {
         // If we saved a new image but failed to update the todo, clean up the new image
         if imageFile != nil && existingTodo.ImageID != "" {
             _ = s.imageRepo.Delete(ctx, existingTodo.ImageID) // Best effort cleanup
         }
-        return nil, fmt.Errorf("failed to update todo: %w", err)
+        return nil, err
+    }
+    if removedImageID != "" {
+        _ = s.imageRepo.Delete(ctx, removedImageID) // Best effort cleanup
     }
     
+    existingTodo.Overdue = isOverdue(existingTodo, time.Now(), s.userLocation(ctx, userID))
     return existingTodo, nil  // Return the todo we updated, not updatedTodo
 }
-func (s *todoService) DeleteTodo(ctx context.Context, id int, userID int) error {
-	// Get existing todo
+// MoveTodo gives a todo a position between two neighbours in its list.
+func (s *todoService) MoveTodo(ctx context.Context, id int, req domain.MoveTodoRequest, userID int) (*domain.Todo, error) {
 	todo, err := s.todoRepo.FindByID(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Verify ownership
 	if todo.UserID != userID {
-		return errors.New("unauthorized")
+		return nil, domain.ErrNotOwner
+	}
+	if todo.DeletedAt != nil {
+		return nil, domain.ErrTodoNotFound
 	}
 
-	// Delete image if exists
-	if todo.ImageID != "" {
-		if err := s.imageRepo.Delete(ctx, todo.ImageID); err != nil {
-			// Log error but continue
-			fmt.Printf("Error deleting image: %v\n", err)
+	after, err := s.neighbour(ctx, todo, req.AfterID, "after_id")
+	if err != nil {
+		return nil, err
+	}
+	before, err := s.neighbour(ctx, todo, req.BeforeID, "before_id")
+	if err != nil {
+		return nil, err
+	}
+	if after != nil && before != nil && !listsBefore(after, before) {
+		return nil, &domain.ValidationError{Field: "before_id", Message: "must come after after_id in the list"}
+	}
+
+	err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		switch {
+		case after == nil && before == nil:
+			todo.Position, err = endOfList(ctx, s.todoRepo, userID, todo.ListID)
+		case after != nil && before != nil && after.Position == before.Position:
+			err = s.placeInTie(ctx, todo, after)
+		default:
+			todo.Position, err = rank.Between(position(after), position(before))
+		}
+		if err != nil {
+			return err
+		}
+		return s.todoRepo.Update(ctx, todo)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to move todo: %w", err)
+	}
+
+	return s.GetTodoByID(ctx, todo.ID, userID)
+}
+
+// listsBefore reports whether a comes before b in the manual order, which
+// breaks ties between equal ranks by ID.
+func listsBefore(a, b *domain.Todo) bool {
+	if a.Position != b.Position {
+		return a.Position < b.Position
+	}
+	return a.ID < b.ID
+}
+
+func position(todo *domain.Todo) string {
+	if todo == nil {
+		return ""
+	}
+	return todo.Position
+}
+
+// placeInTie places todo right after the todo after, which shares its rank
+// with the todos that follow it. There is no rank between equal ones, so
+// those todos get new ranks after todo's, in the order they had, up to the
+// next rank of the list.
+func (s *todoService) placeInTie(ctx context.Context, todo, after *domain.Todo) error {
+	query := domain.TodoQuery{
+		UserID:          todo.UserID,
+		ListID:          todo.ListID,
+		IncludeArchived: true,
+		Sort:            domain.SortPosition,
+		Order:           domain.SortAsc,
+		Limit:           domain.MaxTodoPageSize,
+		Cursor: domain.TodoCursor{
+			Sort:  domain.SortPosition,
+			Order: domain.SortAsc,
+			Value: after.Position,
+			ID:    after.ID,
+		}.Encode(),
+	}
+
+	var tied []domain.Todo
+	next := ""
+	for next == "" {
+		page, err := s.todoRepo.FindAll(ctx, query)
+		if err != nil {
+			return err
+		}
+		for _, other := range page.Todos {
+			if other.ID == todo.ID {
+				continue
+			}
+			if other.Position != after.Position {
+				next = other.Position
+				break
+			}
+			tied = append(tied, other)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	var err error
+	if todo.Position, err = rank.Between(after.Position, next); err != nil {
+		return err
+	}
+	last := todo.Position
+	for i := range tied {
+		if tied[i].Position, err = rank.Between(last, next); err != nil {
+			return err
+		}
+		if err := s.todoRepo.Update(ctx, &tied[i]); err != nil {
+			return err
 		}
+		last = tied[i].Position
+	}
+	return nil
+}
+
+// neighbour returns a neighbour named in a move, or nil when none was
+// given.
+func (s *todoService) neighbour(ctx context.Context, todo *domain.Todo, neighbourID int, field string) (*domain.Todo, error) {
+	if neighbourID == 0 {
+		return nil, nil
+	}
+	if neighbourID == todo.ID {
+		return nil, &domain.ValidationError{Field: field, Message: "a todo cannot be its own neighbour"}
+	}
+
+	neighbour, err := s.todoRepo.FindByID(ctx, neighbourID)
+	if err != nil {
+		return nil, err
+	}
+	if neighbour.UserID != todo.UserID {
+		return nil, domain.ErrNotOwner
+	}
+	if neighbour.DeletedAt != nil {
+		return nil, domain.ErrTodoNotFound
+	}
+	if neighbour.ListID != todo.ListID {
+		return nil, &domain.ValidationError{Field: field, Message: "must be in the same list"}
+	}
+
+	return neighbour, nil
+}
+
+func (s *todoService) DeleteTodo(ctx context.Context, id int, userID int, version int) error {
+	// Get existing todo
+	todo, err := s.todoRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Verify ownership
+	if todo.UserID != userID {
+		return domain.ErrNotOwner
+	}
+	if todo.DeletedAt != nil {
+		return domain.ErrTodoNotFound
+	}
+	if version != 0 && version != todo.Version {
+		return domain.ErrVersionConflict
 	}
 
-	// Delete todo
-	return s.todoRepo.Delete(ctx, id)
+	// Move the todo to the trash; the image stays until it is purged
+	now := time.Now()
+	todo.DeletedAt = &now
+	return s.todoRepo.Update(ctx, todo)
 }
\ No newline at end of file
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+	"github.com/ChaiyawutTar/MyList/pkg/auth"
+)
+
+type tokenService struct {
+	refreshTokens ports.RefreshTokenRepository
+	revokedTokens ports.RevokedTokenRepository
+	sessions      ports.SessionRepository
+	transactor    ports.Transactor
+	jwtAuth       *auth.JWTAuth
+	refreshTTL    time.Duration
+}
+
+// NewTokenService returns a TokenService that signs access tokens with
+// jwtAuth and keeps each refresh token, and so its session, valid for
+// refreshTTL after it is issued.
+func NewTokenService(refreshTokens ports.RefreshTokenRepository, revokedTokens ports.RevokedTokenRepository, sessions ports.SessionRepository, transactor ports.Transactor, jwtAuth *auth.JWTAuth, refreshTTL time.Duration) ports.TokenService {
+	return &tokenService{
+		refreshTokens: refreshTokens,
+		revokedTokens: revokedTokens,
+		sessions:      sessions,
+		transactor:    transactor,
+		jwtAuth:       jwtAuth,
+		refreshTTL:    refreshTTL,
+	}
+}
+
+// Issue starts a session for the client of ctx, whose ID is the family of
+// the tokens.
+func (s *tokenService) Issue(ctx context.Context, userID int) (*domain.TokenPair, error) {
+	sessionID, err := auth.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	client := domain.ClientFromContext(ctx)
+	session := &domain.Session{
+		ID:         sessionID,
+		UserID:     userID,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(s.refreshTTL),
+	}
+
+	var pair *domain.TokenPair
+	err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.sessions.Create(ctx, session); err != nil {
+			return err
+		}
+		pair, err = s.issue(ctx, userID, sessionID, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+func (s *tokenService) Refresh(ctx context.Context, req domain.RefreshRequest) (*domain.TokenPair, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var pair *domain.TokenPair
+	reused := false
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		token, err := s.refreshTokens.FindByHash(ctx, auth.HashToken(req.RefreshToken))
+		if err != nil {
+			return err
+		}
+		if token.RevokedAt != nil || !now.Before(token.ExpiresAt) {
+			return domain.ErrInvalidRefreshToken
+		}
+
+		// A token that is used up, or that a concurrent refresh just used
+		// up, has been presented twice: someone else holds a copy
+		used := token.UsedAt != nil
+		if !used {
+			marked, err := s.refreshTokens.MarkUsed(ctx, token.ID, now)
+			if err != nil {
+				return err
+			}
+			used = !marked
+		}
+		if used {
+			// The revocation has to commit, so it is reported afterwards
+			reused = true
+			return s.revokeFamily(ctx, token.FamilyID, now)
+		}
+
+		pair, err = s.issue(ctx, token.UserID, token.FamilyID, now)
+		if err != nil {
+			return err
+		}
+		return s.sessions.Extend(ctx, token.FamilyID, now, now.Add(s.refreshTTL))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, domain.ErrRefreshTokenReused
+	}
+	return pair, nil
+}
+
+func (s *tokenService) Logout(ctx context.Context, req domain.LogoutRequest, accessToken string) error {
+	if req.RefreshToken == "" && accessToken == "" {
+		return &domain.ValidationError{Field: "refresh_token", Code: domain.CodeRequired, Message: "is required without a bearer token"}
+	}
+
+	// Logging out is idempotent: tokens that are already invalid are
+	// skipped rather than reported
+	now := time.Now()
+	return s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if accessToken != "" {
+			if claims, err := s.jwtAuth.ParseToken(accessToken); err == nil {
+				if err := s.revokedTokens.Revoke(ctx, claims.TokenID, claims.ExpiresAt); err != nil {
+					return err
+				}
+			}
+		}
+
+		if req.RefreshToken == "" {
+			return nil
+		}
+		token, err := s.refreshTokens.FindByHash(ctx, auth.HashToken(req.RefreshToken))
+		if errors.Is(err, domain.ErrInvalidRefreshToken) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return s.revokeFamily(ctx, token.FamilyID, now)
+	})
+}
+
+func (s *tokenService) Authenticate(ctx context.Context, accessToken string) (*domain.AccessClaims, error) {
+	claims, err := s.jwtAuth.ParseToken(accessToken)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	revoked, err := s.revokedTokens.IsRevoked(ctx, claims.TokenID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, domain.ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+func (s *tokenService) RevokeSession(ctx context.Context, sessionID string) error {
+	return s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		return s.revokeFamily(ctx, sessionID, time.Now())
+	})
+}
+
+func (s *tokenService) RevokeUserSessions(ctx context.Context, userID int, exceptID string) (int, error) {
+	revoked := 0
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		sessions, err := s.sessions.FindActive(ctx, userID, now)
+		if err != nil {
+			return err
+		}
+		for _, session := range sessions {
+			if session.ID == exceptID {
+				continue
+			}
+			if err := s.revokeFamily(ctx, session.ID, now); err != nil {
+				return err
+			}
+			revoked++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return revoked, nil
+}
+
+// issue creates the next token pair of a family.
+func (s *tokenService) issue(ctx context.Context, userID int, familyID string, now time.Time) (*domain.TokenPair, error) {
+	accessToken, claims, err := s.jwtAuth.GenerateToken(userID, familyID)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.refreshTokens.Create(ctx, &domain.RefreshToken{
+		UserID:          userID,
+		FamilyID:        familyID,
+		TokenHash:       hash,
+		AccessTokenID:   claims.TokenID,
+		AccessExpiresAt: claims.ExpiresAt,
+		ExpiresAt:       now.Add(s.refreshTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TokenPair{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(s.jwtAuth.Expiry().Seconds()),
+	}, nil
+}
+
+// revokeFamily revokes the session of a family, every refresh token of it
+// and the access tokens issued with them that are still valid.
+func (s *tokenService) revokeFamily(ctx context.Context, familyID string, now time.Time) error {
+	tokens, err := s.refreshTokens.FindByFamily(ctx, familyID)
+	if err != nil {
+		return err
+	}
+	if err := s.refreshTokens.RevokeFamily(ctx, familyID, now); err != nil {
+		return err
+	}
+	if err := s.sessions.Revoke(ctx, familyID, now); err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		if token.AccessExpiresAt.After(now) {
+			if err := s.revokedTokens.Revoke(ctx, token.AccessTokenID, token.AccessExpiresAt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// TokenSweeper deletes expired refresh tokens, sessions and one-time
+// tokens, and revocation list entries that outlived the tokens they
+// revoke.
+type TokenSweeper struct {
+	refreshTokens ports.RefreshTokenRepository
+	revokedTokens ports.RevokedTokenRepository
+	sessions      ports.SessionRepository
+	oneTimeTokens ports.OneTimeTokenRepository
+	interval      time.Duration
+}
+
+func NewTokenSweeper(refreshTokens ports.RefreshTokenRepository, revokedTokens ports.RevokedTokenRepository, sessions ports.SessionRepository, oneTimeTokens ports.OneTimeTokenRepository, interval time.Duration) *TokenSweeper {
+	return &TokenSweeper{
+		refreshTokens: refreshTokens,
+		revokedTokens: revokedTokens,
+		sessions:      sessions,
+		oneTimeTokens: oneTimeTokens,
+		interval:      interval,
+	}
+}
+
+// Run sweeps once right away and then every interval until ctx is done.
+func (s *TokenSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if deleted, err := s.Sweep(ctx); err != nil {
+			log.Printf("Error sweeping tokens: %v", err)
+		} else if deleted > 0 {
+			log.Printf("Deleted %d expired tokens", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep deletes everything that expired by now and returns how many rows
+// it removed.
+func (s *TokenSweeper) Sweep(ctx context.Context) (int, error) {
+	now := time.Now()
+	refreshed, err := s.refreshTokens.DeleteExpired(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	revoked, err := s.revokedTokens.DeleteExpired(ctx, now)
+	if err != nil {
+		return refreshed, err
+	}
+	sessions, err := s.sessions.DeleteExpired(ctx, now)
+	if err != nil {
+		return refreshed + revoked, err
+	}
+	oneTime, err := s.oneTimeTokens.DeleteExpired(ctx, now)
+	return refreshed + revoked + sessions + oneTime, err
+}
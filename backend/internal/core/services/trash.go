@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+)
+
+func (s *todoService) GetTrash(ctx context.Context, userID int, query domain.TodoQuery) (*domain.TodoPage, error) {
+	query.Trashed = true
+	query.IncludeArchived = true
+	return s.GetAllTodos(ctx, userID, query)
+}
+
+func (s *todoService) RestoreTodo(ctx context.Context, id int, userID int) (*domain.Todo, error) {
+	todo, err := s.getTrashedTodo(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The workflow may have lost the todo's status while it was trashed
+	workflow, err := effectiveWorkflow(ctx, s.workflowRepo, userID, todo.ListID)
+	if err != nil {
+		return nil, err
+	}
+	if workflow.Status(todo.Status) == nil {
+		todo.Status = workflow.InitialStatus()
+	}
+	applyCompletion(todo, workflow, time.Now())
+
+	todo.DeletedAt = nil
+	if err := s.todoRepo.Update(ctx, todo); err != nil {
+		return nil, fmt.Errorf("failed to restore todo: %w", err)
+	}
+
+	return s.GetTodoByID(ctx, todo.ID, userID)
+}
+
+func (s *todoService) PurgeTodo(ctx context.Context, id int, userID int) error {
+	todo, err := s.getTrashedTodo(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	return purgeTodo(ctx, s.todoRepo, s.imageRepo, todo)
+}
+
+func (s *todoService) EmptyTrash(ctx context.Context, userID int) (int, error) {
+	page, err := s.todoRepo.FindAll(ctx, domain.TodoQuery{
+		UserID:          userID,
+		Trashed:         true,
+		IncludeArchived: true,
+		Sort:            domain.SortCreatedAt,
+		Order:           domain.SortDesc,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range page.Todos {
+		if err := purgeTodo(ctx, s.todoRepo, s.imageRepo, &page.Todos[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(page.Todos), nil
+}
+
+// getTrashedTodo loads a todo of the user that is in the trash.
+func (s *todoService) getTrashedTodo(ctx context.Context, id int, userID int) (*domain.Todo, error) {
+	todo, err := s.todoRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify ownership
+	if todo.UserID != userID {
+		return nil, domain.ErrNotOwner
+	}
+	if todo.DeletedAt == nil {
+		return nil, domain.Conflict("todo_not_trashed", "todo is not in the trash")
+	}
+
+	return todo, nil
+}
+
+// purgeTodo deletes a todo and its image for good.
+func purgeTodo(ctx context.Context, todoRepo ports.TodoRepository, imageRepo ports.ImageRepository, todo *domain.Todo) error {
+	if todo.ImageID != "" {
+		if err := imageRepo.Delete(ctx, todo.ImageID); err != nil {
+			// Log error but continue
+			fmt.Printf("Error deleting image: %v\n", err)
+		}
+	}
+	return todoRepo.Delete(ctx, todo.ID)
+}
+
+// sweepBatchSize bounds how many todos one sweep pass loads at a time.
+const sweepBatchSize = 100
+
+// TrashSweeper purges todos that have been in the trash for longer than
+// the retention period.
+type TrashSweeper struct {
+	todoRepo  ports.TodoRepository
+	imageRepo ports.ImageRepository
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewTrashSweeper(todoRepo ports.TodoRepository, imageRepo ports.ImageRepository, retention, interval time.Duration) *TrashSweeper {
+	return &TrashSweeper{
+		todoRepo:  todoRepo,
+		imageRepo: imageRepo,
+		retention: retention,
+		interval:  interval,
+	}
+}
+
+// Run sweeps once right away and then every interval until ctx is done.
+func (s *TrashSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := s.Sweep(ctx); err != nil {
+			log.Printf("Error sweeping trash: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d todos from the trash", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep purges every todo trashed before the retention cutoff and returns
+// how many it removed.
+func (s *TrashSweeper) Sweep(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-s.retention)
+	purged := 0
+	for {
+		todos, err := s.todoRepo.FindTrashedBefore(ctx, cutoff, sweepBatchSize)
+		if err != nil {
+			return purged, err
+		}
+
+		for i := range todos {
+			if err := purgeTodo(ctx, s.todoRepo, s.imageRepo, &todos[i]); err != nil {
+				return purged, fmt.Errorf("failed to purge todo %d: %w", todos[i].ID, err)
+			}
+			purged++
+		}
+
+		if len(todos) < sweepBatchSize {
+			return purged, nil
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	// "fmt"
+	"log"
+	"strings"
 	"time"
 	"github.com/ChaiyawutTar/MyList/internal/core/domain"
 	"github.com/ChaiyawutTar/MyList/internal/core/ports"
@@ -11,81 +13,105 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// oauthCodeTTL is how long the frontend has to exchange the code of an
+// OAuth login.
+const oauthCodeTTL = time.Minute
+
 type userService struct {
-	userRepo ports.UserRepository
-	jwtAuth  *auth.JWTAuth
+	userRepo      ports.UserRepository
+	oneTimeTokens ports.OneTimeTokenRepository
+	tokenService  ports.TokenService
+	verification  ports.EmailVerificationService
+	mfa           ports.MFAService
+	transactor    ports.Transactor
 }
 
-func NewUserService(userRepo ports.UserRepository, jwtAuth *auth.JWTAuth) ports.UserService {
+func NewUserService(userRepo ports.UserRepository, oneTimeTokens ports.OneTimeTokenRepository, tokenService ports.TokenService, verification ports.EmailVerificationService, mfa ports.MFAService, transactor ports.Transactor) ports.UserService {
 	return &userService{
-		userRepo: userRepo,
-		jwtAuth:  jwtAuth,
+		userRepo:      userRepo,
+		oneTimeTokens: oneTimeTokens,
+		tokenService:  tokenService,
+		verification:  verification,
+		mfa:           mfa,
+		transactor:    transactor,
 	}
 }
 
 func (s *userService) CreateUser(ctx context.Context, req domain.SignupRequest) (*domain.AuthResponse, error) {
 	// Validate input
-	if req.Username == "" || req.Email == "" || req.Password == "" {
-		return nil, errors.New("username, email and password are required")
+	if err := req.Validate(); err != nil {
+		return nil, err
 	}
 
 	// Create user
 	user := &domain.User{
 		Username:  req.Username,
 		Email:     req.Email,
+		Timezone:  req.Timezone,
 		CreatedAt: time.Now(),
 	}
 
 	if err := s.userRepo.Create(ctx, user, req.Password); err != nil {
 		return nil, err
 	}
+	s.sendVerification(ctx, user.ID)
 
-	// Generate token
-	token, err := s.jwtAuth.GenerateToken(user.ID)
+	// Generate tokens
+	tokens, err := s.tokenService.Issue(ctx, user.ID)
 	if err != nil {
 		return nil, err
 	}
 
 	return &domain.AuthResponse{
-		Token: token,
-		User:  *user,
+		TokenPair: *tokens,
+		User:      *user,
 	}, nil
 }
 
-func (s *userService) Login(ctx context.Context, req domain.LoginRequest) (*domain.AuthResponse, error) {
+func (s *userService) Login(ctx context.Context, req domain.LoginRequest) (*domain.LoginResult, error) {
 	// Validate input
-	if req.Email == "" || req.Password == "" {
-		return nil, errors.New("email and password are required")
+	if err := req.Validate(); err != nil {
+		return nil, err
 	}
 
 	// Find user
 	user, err := s.userRepo.FindByEmail(ctx, req.Email)
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		return nil, domain.ErrInvalidCredentials
 	}
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		return nil, errors.New("invalid credentials")
+		return nil, domain.ErrInvalidCredentials
 	}
 
-	// Generate token
-	token, err := s.jwtAuth.GenerateToken(user.ID)
-	if err != nil {
+	return s.login(ctx, user)
+}
+
+func (s *userService) GetUserByID(ctx context.Context, id int) (*domain.User, error) {
+	return s.userRepo.FindByID(ctx, id)
+}
+
+func (s *userService) UpdateUser(ctx context.Context, id int, req domain.UpdateUserRequest) (*domain.User, error) {
+	// An empty timezone resets the user back to UTC
+	if err := req.Validate(); err != nil {
 		return nil, err
 	}
 
-	return &domain.AuthResponse{
-		Token: token,
-		User:  *user,
-	}, nil
-}
+	if err := s.userRepo.UpdateTimezone(ctx, id, req.Timezone); err != nil {
+		return nil, err
+	}
 
-func (s *userService) GetUserByID(ctx context.Context, id int) (*domain.User, error) {
 	return s.userRepo.FindByID(ctx, id)
 }
 
-func (s *userService) OAuthLogin(ctx context.Context, oauthUser domain.OAuthUser) (*domain.AuthResponse, error) {
+func (s *userService) OAuthLogin(ctx context.Context, oauthUser domain.OAuthUser) (string, error) {
+    now := time.Now()
+    var verifiedAt *time.Time
+    if oauthUser.EmailVerified {
+        verifiedAt = &now
+    }
+
     // Try to find user by OAuth provider ID
     user, err := s.userRepo.FindByOAuthID(ctx, oauthUser.Provider, oauthUser.ProviderID)
     
@@ -100,12 +126,16 @@ func (s *userService) OAuthLogin(ctx context.Context, oauthUser domain.OAuthUser
                 Email:           oauthUser.Email,
                 OAuthProvider:   oauthUser.Provider,
                 OAuthProviderID: oauthUser.ProviderID,
-                CreatedAt:       time.Now(),
+                EmailVerifiedAt: verifiedAt,
+                CreatedAt:       now,
             }
             
             // Create user without password
             if err := s.userRepo.Create(ctx, user, ""); err != nil {
-                return nil, err
+                return "", err
+            }
+            if verifiedAt == nil {
+                s.sendVerification(ctx, user.ID)
             }
         } else {
             // Update existing user with OAuth info
@@ -114,19 +144,103 @@ func (s *userService) OAuthLogin(ctx context.Context, oauthUser domain.OAuthUser
             
             // Update user in database
             if err := s.userRepo.UpdateOAuthInfo(ctx, user); err != nil {
-                return nil, err
+                return "", err
             }
         }
     }
+
+    // The provider vouches for the address only if it is the account's
+    if verifiedAt != nil && user.EmailVerifiedAt == nil && strings.EqualFold(user.Email, oauthUser.Email) {
+        if err := s.userRepo.MarkEmailVerified(ctx, user.ID, now); err != nil {
+            return "", err
+        }
+        user.EmailVerifiedAt = verifiedAt
+    }
     
-    // Generate token
-    token, err := s.jwtAuth.GenerateToken(user.ID)
+    // Tokens in the redirect to the frontend would be left in its history
+    // and in server logs, so it gets a code to exchange for them instead
+    code, hash, err := auth.NewOpaqueToken()
     if err != nil {
-        return nil, err
+        return "", err
     }
-    
-    return &domain.AuthResponse{
-        Token: token,
-        User:  *user,
-    }, nil
-}
\ No newline at end of file
+    err = s.oneTimeTokens.Create(ctx, &domain.OneTimeToken{
+        UserID:    user.ID,
+        Purpose:   domain.TokenPurposeOAuthLogin,
+        TokenHash: hash,
+        ExpiresAt: now.Add(oauthCodeTTL),
+    })
+    if err != nil {
+        return "", err
+    }
+    return code, nil
+}
+
+func (s *userService) ExchangeOAuthCode(ctx context.Context, req domain.OAuthExchangeRequest) (*domain.LoginResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	token, err := s.oneTimeTokens.FindByHash(ctx, domain.TokenPurposeOAuthLogin, auth.HashToken(req.Code))
+	if errors.Is(err, domain.ErrOneTimeTokenNotFound) {
+		return nil, domain.ErrInvalidOAuthCode
+	}
+	if err != nil {
+		return nil, err
+	}
+	if token.UsedAt != nil || !time.Now().Before(token.ExpiresAt) {
+		return nil, domain.ErrInvalidOAuthCode
+	}
+
+	user, err := s.userRepo.FindByID(ctx, token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	var result *domain.LoginResult
+	err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		consumed, err := s.oneTimeTokens.Consume(ctx, token.ID, time.Now())
+		if err != nil {
+			return err
+		}
+		if !consumed {
+			return domain.ErrInvalidOAuthCode
+		}
+		result, err = s.login(ctx, user)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// login finishes logging in a user whose password or provider checked out:
+// with tokens, or with a challenge for the second factor if MFA is on.
+func (s *userService) login(ctx context.Context, user *domain.User) (*domain.LoginResult, error) {
+	challenge, err := s.mfa.Challenge(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &domain.LoginResult{Challenge: challenge}, nil
+	}
+
+	// Generate tokens
+	tokens, err := s.tokenService.Issue(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.LoginResult{Auth: &domain.AuthResponse{
+		TokenPair: *tokens,
+		User:      *user,
+	}}, nil
+}
+
+// sendVerification mails a new user their verification link. The account
+// works without it, and the user can ask for another, so a failure is only
+// logged.
+func (s *userService) sendVerification(ctx context.Context, userID int) {
+	if err := s.verification.SendVerification(ctx, userID); err != nil {
+		log.Printf("Error mailing email verification to user %d: %v", userID, err)
+	}
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+)
+
+type workflowService struct {
+	workflowRepo ports.WorkflowRepository
+	listRepo     ports.ListRepository
+	todoRepo     ports.TodoRepository
+}
+
+func NewWorkflowService(workflowRepo ports.WorkflowRepository, listRepo ports.ListRepository, todoRepo ports.TodoRepository) ports.WorkflowService {
+	return &workflowService{
+		workflowRepo: workflowRepo,
+		listRepo:     listRepo,
+		todoRepo:     todoRepo,
+	}
+}
+
+func (s *workflowService) GetWorkflows(ctx context.Context, userID int) ([]domain.Workflow, error) {
+	workflows, err := s.workflowRepo.FindAll(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Show the built-in default until the user stores their own
+	if len(workflows) == 0 || workflows[0].ListID != 0 {
+		workflows = append([]domain.Workflow{*domain.DefaultWorkflow(userID)}, workflows...)
+	}
+	return workflows, nil
+}
+
+func (s *workflowService) GetWorkflow(ctx context.Context, id int, userID int) (*domain.Workflow, error) {
+	return s.getOwnedWorkflow(ctx, id, userID)
+}
+
+func (s *workflowService) GetListWorkflow(ctx context.Context, listID int, userID int) (*domain.Workflow, error) {
+	if _, err := getOwnedList(ctx, s.listRepo, listID, userID); err != nil {
+		return nil, err
+	}
+	return effectiveWorkflow(ctx, s.workflowRepo, userID, listID)
+}
+
+func (s *workflowService) CreateWorkflow(ctx context.Context, req domain.WorkflowRequest, userID int) (*domain.Workflow, error) {
+	if req.ListID != 0 {
+		if _, err := getOwnedList(ctx, s.listRepo, req.ListID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	workflow := &domain.Workflow{
+		UserID:      userID,
+		ListID:      req.ListID,
+		Name:        strings.TrimSpace(req.Name),
+		Statuses:    req.Statuses,
+		Transitions: req.Transitions,
+	}
+	if err := workflow.Validate(); err != nil {
+		return nil, err
+	}
+
+	// The todos that will follow the new workflow must fit into it
+	todos, err := s.todosUnder(ctx, userID, req.ListID, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatusesInUse(todos, workflow); err != nil {
+		return nil, err
+	}
+
+	if err := s.workflowRepo.Create(ctx, workflow); err != nil {
+		return nil, err
+	}
+
+	if err := s.syncCompletion(ctx, todos, workflow); err != nil {
+		return nil, err
+	}
+	return workflow, nil
+}
+
+func (s *workflowService) UpdateWorkflow(ctx context.Context, id int, req domain.WorkflowRequest, userID int) (*domain.Workflow, error) {
+	workflow, err := s.getOwnedWorkflow(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow.Name = strings.TrimSpace(req.Name)
+	workflow.Statuses = req.Statuses
+	workflow.Transitions = req.Transitions
+	if err := workflow.Validate(); err != nil {
+		return nil, err
+	}
+
+	todos, err := s.todosUnder(ctx, userID, workflow.ListID, workflow.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatusesInUse(todos, workflow); err != nil {
+		return nil, err
+	}
+
+	if err := s.workflowRepo.Update(ctx, workflow); err != nil {
+		return nil, err
+	}
+
+	// A status may have started or stopped counting as complete
+	if err := s.syncCompletion(ctx, todos, workflow); err != nil {
+		return nil, err
+	}
+	return workflow, nil
+}
+
+func (s *workflowService) DeleteWorkflow(ctx context.Context, id int, userID int) error {
+	workflow, err := s.getOwnedWorkflow(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	// The todos fall back to the user's default, or the built-in one when
+	// the default itself goes away
+	fallback := domain.DefaultWorkflow(userID)
+	if workflow.ListID != 0 {
+		if fallback, err = effectiveWorkflow(ctx, s.workflowRepo, userID, 0); err != nil {
+			return err
+		}
+	}
+
+	todos, err := s.todosUnder(ctx, userID, workflow.ListID, workflow.ID)
+	if err != nil {
+		return err
+	}
+	if err := checkStatusesInUse(todos, fallback); err != nil {
+		return err
+	}
+
+	if err := s.workflowRepo.Delete(ctx, workflow.ID); err != nil {
+		return err
+	}
+
+	return s.syncCompletion(ctx, todos, fallback)
+}
+
+func (s *workflowService) getOwnedWorkflow(ctx context.Context, id int, userID int) (*domain.Workflow, error) {
+	workflow, err := s.workflowRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify ownership
+	if workflow.UserID != userID {
+		return nil, domain.ErrNotOwner
+	}
+
+	return workflow, nil
+}
+
+// todosUnder returns the todos governed by the workflow of a list, or by the
+// user's default workflow when listID is 0. Lists with a workflow of their
+// own (other than skipID) are left out of the default's todos.
+func (s *workflowService) todosUnder(ctx context.Context, userID, listID, skipID int) ([]domain.Todo, error) {
+	page, err := s.todoRepo.FindAll(ctx, domain.TodoQuery{
+		UserID:          userID,
+		ListID:          listID,
+		IncludeArchived: true,
+		Sort:            domain.SortCreatedAt,
+		Order:           domain.SortDesc,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if listID != 0 {
+		return page.Todos, nil
+	}
+
+	workflows, err := s.workflowRepo.FindAll(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	ownWorkflow := make(map[int]bool)
+	for _, workflow := range workflows {
+		if workflow.ListID != 0 && workflow.ID != skipID {
+			ownWorkflow[workflow.ListID] = true
+		}
+	}
+
+	todos := make([]domain.Todo, 0, len(page.Todos))
+	for _, todo := range page.Todos {
+		if !ownWorkflow[todo.ListID] {
+			todos = append(todos, todo)
+		}
+	}
+	return todos, nil
+}
+
+// syncCompletion updates completed_at on todos whose status changed meaning
+// under the workflow.
+func (s *workflowService) syncCompletion(ctx context.Context, todos []domain.Todo, workflow *domain.Workflow) error {
+	now := time.Now()
+	for i := range todos {
+		todo := &todos[i]
+		if !applyCompletion(todo, workflow, now) {
+			continue
+		}
+		if err := s.todoRepo.Update(ctx, todo); err != nil {
+			return fmt.Errorf("failed to update todo %d: %w", todo.ID, err)
+		}
+	}
+	return nil
+}
+
+// checkStatusesInUse rejects a workflow that lacks a status some of its
+// todos are in.
+func checkStatusesInUse(todos []domain.Todo, workflow *domain.Workflow) error {
+	for _, todo := range todos {
+		if workflow.Status(todo.Status) == nil {
+			return &domain.ValidationError{
+				Field:   "statuses",
+				Message: fmt.Sprintf("status %q is still used by todo %d", todo.Status, todo.ID),
+			}
+		}
+	}
+	return nil
+}
+
+// effectiveWorkflow returns the workflow that applies to a list's todos:
+// the list's own, else the user's default, else the built-in default.
+func effectiveWorkflow(ctx context.Context, workflowRepo ports.WorkflowRepository, userID, listID int) (*domain.Workflow, error) {
+	workflow, err := workflowRepo.FindEffective(ctx, userID, listID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workflow: %w", err)
+	}
+	if workflow == nil {
+		workflow = domain.DefaultWorkflow(userID)
+	}
+	return workflow, nil
+}
+
+// applyCompletion sets or clears CompletedAt to match the todo's status and
+// reports whether it changed.
+func applyCompletion(todo *domain.Todo, workflow *domain.Workflow, now time.Time) bool {
+	complete := workflow.IsComplete(todo.Status)
+	switch {
+	case complete && todo.CompletedAt == nil:
+		todo.CompletedAt = &now
+		return true
+	case !complete && todo.CompletedAt != nil:
+		todo.CompletedAt = nil
+		return true
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ChaiyawutTar/MyList/internal/adapters/mailer"
+	"github.com/ChaiyawutTar/MyList/internal/config"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+)
+
+// newMailer returns the mailer that MAILER selects. The log file of the
+// log mailer stays open for as long as the server runs.
+func newMailer(cfg *config.Config) (ports.Mailer, error) {
+	switch cfg.Mailer {
+	case config.MailerLog:
+		if cfg.MailLogFile == "" {
+			return mailer.NewLogMailer(os.Stderr, cfg.MailFrom), nil
+		}
+		f, err := os.OpenFile(cfg.MailLogFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+		if err != nil {
+			return nil, err
+		}
+		return mailer.NewLogMailer(f, cfg.MailFrom), nil
+	case config.MailerSMTP:
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("MAILER=%s needs SMTP_HOST", config.MailerSMTP)
+		}
+		return mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom), nil
+	default:
+		return nil, fmt.Errorf("unknown MAILER %q, expected %s or %s", cfg.Mailer, config.MailerLog, config.MailerSMTP)
+	}
+}
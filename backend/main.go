@@ -1,12 +1,13 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"strings"
+	_ "time/tzdata" // user time zones must resolve even without system zoneinfo
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -20,8 +21,6 @@ import (
 
 	// Remove or comment out the file repository import
 	// "github.com/ChaiyawutTar/MyList/internal/adapters/repositories/file"
-	"github.com/ChaiyawutTar/MyList/internal/adapters/repositories/postgres"
-	// "github.com/ChaiyawutTar/MyList/internal/adapters/repositories/file"
 
 	"github.com/ChaiyawutTar/MyList/internal/config"
 	"github.com/ChaiyawutTar/MyList/internal/core/services"
@@ -34,46 +33,128 @@ func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
 
-	// Connect to database
-	db, err := sql.Open("postgres", cfg.DatabaseURL)
-	if err != nil {
-		log.Fatal(err)
+	// "conformance" checks the repository adapters instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "conformance" {
+		if err := runConformance(cfg, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
-	defer db.Close()
 
-	// Test database connection
-	if err := db.Ping(); err != nil {
-		log.Fatal(err)
+	var repos *storage
+	switch cfg.Storage {
+	case config.StorageMemory:
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			log.Fatal("migrations only apply to STORAGE=database")
+		}
+		log.Printf("Using in-memory storage; all data is lost on restart")
+		repos = memoryStorage()
+	case config.StorageDatabase:
+		db, err := openDatabase(cfg.DatabaseURL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer db.Close()
+
+		// "migrate" manages the schema instead of serving
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			if err := runMigrate(db, os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+		if cfg.MigrateOnStart {
+			if err := migrateOnStart(db); err != nil {
+				log.Fatal(err)
+			}
+		}
+		repos = db.storage()
+	default:
+		log.Fatalf("Unknown STORAGE %q, expected %s or %s", cfg.Storage, config.StorageDatabase, config.StorageMemory)
 	}
 
 	// Initialize JWT auth
-	jwtAuth := auth.NewJWTAuth(cfg.JWTSecret, cfg.JWTExpiry)
+	jwtAuth := auth.NewJWTAuth(cfg.JWTSecret, cfg.AccessTokenTTL)
 
 	// Initialize repositories
-	userRepo := postgres.NewUserRepository(db)
-	todoRepo := postgres.NewTodoRepository(db)
-	
-	// Replace file-based image repository with database-based one
-	imageRepo := postgres.NewImageRepository(db)
+	userRepo := repos.users
+	refreshTokenRepo := repos.refreshTokens
+	revokedTokenRepo := repos.revokedTokens
+	sessionRepo := repos.sessions
+	oneTimeTokenRepo := repos.oneTimeTokens
+	mfaRepo := repos.mfa
+	recoveryCodeRepo := repos.recoveryCodes
+	todoRepo := repos.todos
+	tagRepo := repos.tags
+	listRepo := repos.lists
+	checklistRepo := repos.checklist
+	todoSearcher := repos.searcher
+	workflowRepo := repos.workflows
+	revisionRepo := repos.revisions
+	transactor := repos.transactor
+	imageRepo := repos.images
+
+	// Initialize the mailer
+	mailer, err := newMailer(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// Initialize services
-	userService := services.NewUserService(userRepo, jwtAuth)
-	todoService := services.NewTodoService(todoRepo, imageRepo)
+	tokenService := services.NewTokenService(refreshTokenRepo, revokedTokenRepo, sessionRepo, transactor, jwtAuth, cfg.RefreshTokenTTL)
+	verificationService := services.NewEmailVerificationService(userRepo, oneTimeTokenRepo, transactor, mailer, cfg.FrontendURL, cfg.EmailVerificationTTL, cfg.VerificationResendInterval)
+	mfaService := services.NewMFAService(userRepo, mfaRepo, recoveryCodeRepo, oneTimeTokenRepo, tokenService, transactor)
+	userService := services.NewUserService(userRepo, oneTimeTokenRepo, tokenService, verificationService, mfaService, transactor)
+	passwordService := services.NewPasswordService(userRepo, oneTimeTokenRepo, tokenService, transactor, mailer, cfg.FrontendURL, cfg.PasswordResetTTL, cfg.PasswordResetInterval)
+	todoService := services.NewTodoService(todoRepo, imageRepo, userRepo, tagRepo, listRepo, checklistRepo, todoSearcher, workflowRepo, revisionRepo, transactor)
+	checklistService := services.NewChecklistService(checklistRepo, workflowRepo, todoService)
+	tagService := services.NewTagService(tagRepo)
+	listService := services.NewListService(listRepo, todoRepo, imageRepo, workflowRepo)
+	workflowService := services.NewWorkflowService(workflowRepo, listRepo, todoRepo)
+
+	// Record when sessions were last seen in batches
+	if cfg.LastSeenInterval <= 0 {
+		log.Fatal("LAST_SEEN_INTERVAL must be positive")
+	}
+	sessionTracker := services.NewSessionTracker(sessionRepo, cfg.LastSeenInterval)
+	go sessionTracker.Run(context.Background())
+	sessionService := services.NewSessionService(sessionRepo, tokenService, sessionTracker)
+
+	// Purge old todos from the trash in the background
+	if cfg.TrashRetention > 0 && cfg.TrashSweepInterval > 0 {
+		sweeper := services.NewTrashSweeper(todoRepo, imageRepo, cfg.TrashRetention, cfg.TrashSweepInterval)
+		go sweeper.Run(context.Background())
+	}
+
+	// Drop expired tokens, sessions and revocations in the background
+	if cfg.TokenSweepInterval > 0 {
+		tokenSweeper := services.NewTokenSweeper(refreshTokenRepo, revokedTokenRepo, sessionRepo, oneTimeTokenRepo, cfg.TokenSweepInterval)
+		go tokenSweeper.Run(context.Background())
+	}
+
+	// Archive long-completed todos in the background
+	if cfg.ArchiveAfter > 0 && cfg.ArchiveInterval > 0 {
+		archiver := services.NewTodoArchiver(todoRepo, cfg.ArchiveAfter, cfg.ArchiveInterval)
+		go archiver.Run(context.Background())
+	}
 
 	// Initialize handlers
 	todoHandler := httphandlers.NewTodoHandler(todoService)
+	userHandler := httphandlers.NewUserHandler(userService)
+	sessionHandler := httphandlers.NewSessionHandler(sessionService)
+	passwordHandler := httphandlers.NewPasswordHandler(passwordService)
+	verificationHandler := httphandlers.NewEmailVerificationHandler(verificationService)
+	mfaHandler := httphandlers.NewMFAHandler(mfaService)
+	tagHandler := httphandlers.NewTagHandler(tagService)
+	listHandler := httphandlers.NewListHandler(listService, todoService)
+	checklistHandler := httphandlers.NewChecklistHandler(checklistService)
+	workflowHandler := httphandlers.NewWorkflowHandler(workflowService)
 	
 	// Add image handler for serving images from database
 	imageHandler := httphandlers.NewImageHandler(imageRepo)
 	
-	oauthManager := auth.NewOAuthManager(
-		userRepo,
-		jwtAuth,
-		cfg.GoogleClientID,
-		cfg.GoogleClientSecret,
-		cfg.OAuthCallbackURL,
-	)
-	authHandler := httphandlers.NewAuthHandler(userService, oauthManager, cfg.FrontendURL)
+	auth.UseGoogle(cfg.GoogleClientID, cfg.GoogleClientSecret, cfg.OAuthCallbackURL)
+	authHandler := httphandlers.NewAuthHandler(userService, tokenService, cfg.FrontendURL)
 
 	sessionStore := sessions.NewCookieStore([]byte(cfg.SessionSecret))
 	sessionStore.MaxAge(86400 * 30) // 30 days
@@ -87,13 +168,17 @@ func main() {
 	r := chi.NewRouter()
 
 	// Middleware
+	if cfg.TrustProxy {
+		r.Use(middleware.RealIP)
+	}
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
+	r.Use(custommiddleware.ClientMiddleware)
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   cfg.AllowedOrigins,
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "If-Match"},
+		ExposedHeaders:   []string{"Link", "X-Next-Cursor", "ETag"},
 		AllowCredentials: cfg.AllowCredentials,
 		MaxAge:           300,
 	}))
@@ -102,6 +187,13 @@ func main() {
 	r.Group(func(r chi.Router) {
 		r.Post("/signup", authHandler.Signup)
 		r.Post("/login", authHandler.Login)
+		r.Post("/auth/refresh", authHandler.Refresh)
+		r.Post("/auth/logout", authHandler.Logout)
+		r.Post("/auth/password/forgot", passwordHandler.ForgotPassword)
+		r.Post("/auth/password/reset", passwordHandler.ResetPassword)
+		r.Post("/auth/verify-email", verificationHandler.VerifyEmail)
+		r.Post("/auth/mfa/verify", mfaHandler.VerifyLogin)
+		r.Post("/auth/oauth/exchange", authHandler.ExchangeOAuthCode)
 
 		r.Get("/auth/{provider}", authHandler.BeginOAuth)
 		r.Get("/auth/{provider}/callback", authHandler.OAuthCallback)
@@ -113,15 +205,78 @@ func main() {
 	// Remove or comment out the static file server since images are now in the database
 	// r.Handle("/uploads/*", http.StripPrefix("/uploads/", http.FileServer(http.Dir(cfg.UploadDir))))
 
-	// Protected routes
+	// Protected routes for the account itself
+	r.Group(func(r chi.Router) {
+		r.Use(custommiddleware.AuthMiddleware(tokenService, sessionTracker))
+
+		r.Get("/me", userHandler.GetMe)
+		r.Put("/me", userHandler.UpdateMe)
+		r.Get("/me/sessions", sessionHandler.GetSessions)
+		r.Post("/me/sessions/logout-others", sessionHandler.RevokeOtherSessions)
+		r.Delete("/me/sessions/{id}", sessionHandler.RevokeSession)
+		r.Post("/auth/verify-email/resend", verificationHandler.ResendVerification)
+		r.Get("/me/mfa", mfaHandler.GetStatus)
+		r.Post("/me/mfa/totp", mfaHandler.EnrollTOTP)
+		r.Post("/me/mfa/totp/confirm", mfaHandler.ConfirmTOTP)
+		r.Post("/me/mfa/disable", mfaHandler.Disable)
+		r.Post("/me/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+	})
+
+	// Protected routes that change data, which REQUIRE_VERIFIED_EMAIL
+	// closes to users who have not verified their email yet
 	r.Group(func(r chi.Router) {
-		r.Use(custommiddleware.AuthMiddleware(jwtAuth))
+		r.Use(custommiddleware.AuthMiddleware(tokenService, sessionTracker))
+		if cfg.RequireVerifiedEmail {
+			r.Use(custommiddleware.VerifiedEmailMiddleware(userService))
+		}
 
 		r.Get("/todos", todoHandler.GetAllTodos)
+		r.Get("/todos/search", todoHandler.SearchTodos)
+		r.Get("/todos/archived", todoHandler.GetArchivedTodos)
+		r.Post("/todos/archive-done", todoHandler.ArchiveCompleted)
+		r.Post("/todos/batch", todoHandler.BatchTodos)
 		r.Post("/todos", todoHandler.CreateTodo)
 		r.Get("/todos/{id}", todoHandler.GetTodoByID)
 		r.Put("/todos/{id}", todoHandler.UpdateTodo)
+		r.Patch("/todos/{id}", todoHandler.PatchTodo)
 		r.Delete("/todos/{id}", todoHandler.DeleteTodo)
+		r.Post("/todos/{id}/move", todoHandler.MoveTodo)
+		r.Post("/todos/{id}/restore", todoHandler.RestoreTodo)
+		r.Post("/todos/{id}/archive", todoHandler.ArchiveTodo)
+		r.Post("/todos/{id}/unarchive", todoHandler.UnarchiveTodo)
+		r.Get("/todos/{id}/history", todoHandler.GetTodoHistory)
+		r.Post("/todos/{id}/revert/{rev}", todoHandler.RevertTodo)
+
+		r.Get("/trash", todoHandler.GetTrash)
+		r.Delete("/trash", todoHandler.EmptyTrash)
+		r.Delete("/trash/{id}", todoHandler.PurgeTodo)
+
+		r.Post("/todos/{id}/checklist", checklistHandler.AddItem)
+		r.Put("/todos/{id}/checklist/order", checklistHandler.Reorder)
+		r.Put("/todos/{id}/checklist/{itemID}", checklistHandler.UpdateItem)
+		r.Post("/todos/{id}/checklist/{itemID}/toggle", checklistHandler.ToggleItem)
+		r.Delete("/todos/{id}/checklist/{itemID}", checklistHandler.RemoveItem)
+
+		r.Get("/tags", tagHandler.GetAllTags)
+		r.Post("/tags", tagHandler.CreateTag)
+		r.Put("/tags/{id}", tagHandler.UpdateTag)
+		r.Delete("/tags/{id}", tagHandler.DeleteTag)
+		r.Post("/tags/{id}/merge", tagHandler.MergeTags)
+
+		r.Get("/lists", listHandler.GetAllLists)
+		r.Post("/lists", listHandler.CreateList)
+		r.Get("/lists/{id}", listHandler.GetListByID)
+		r.Put("/lists/{id}", listHandler.UpdateList)
+		r.Delete("/lists/{id}", listHandler.DeleteList)
+		r.Get("/lists/{id}/todos", listHandler.GetListTodos)
+		r.Post("/lists/{id}/todos", listHandler.MoveTodos)
+		r.Get("/lists/{id}/workflow", workflowHandler.GetListWorkflow)
+
+		r.Get("/workflows", workflowHandler.GetAllWorkflows)
+		r.Post("/workflows", workflowHandler.CreateWorkflow)
+		r.Get("/workflows/{id}", workflowHandler.GetWorkflowByID)
+		r.Put("/workflows/{id}", workflowHandler.UpdateWorkflow)
+		r.Delete("/workflows/{id}", workflowHandler.DeleteWorkflow)
 	})
 
 
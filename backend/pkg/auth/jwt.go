@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"time"
+	"github.com/ChaiyawutTar/MyList/internal/core/domain"
 	"github.com/golang-jwt/jwt/v4"
 )
 
@@ -20,18 +21,76 @@ func NewJWTAuth(secretKey string, expiry time.Duration) *JWTAuth {
 	}
 }
 
-func (a *JWTAuth) GenerateToken(userID int) (string, error) {
+// Expiry is how long the tokens GenerateToken issues stay valid.
+func (a *JWTAuth) Expiry() time.Duration {
+	return a.expiry
+}
+
+// GenerateToken issues an access token for the user's session, with a
+// random jti so it can be revoked on its own.
+func (a *JWTAuth) GenerateToken(userID int, sessionID string) (string, *domain.AccessClaims, error) {
+	tokenID, err := NewTokenID()
+	if err != nil {
+		return "", nil, err
+	}
+	now := time.Now()
+	access := &domain.AccessClaims{
+		UserID:    userID,
+		TokenID:   tokenID,
+		SessionID: sessionID,
+		ExpiresAt: now.Add(a.expiry).Truncate(time.Second),
+	}
+
 	// Create claims
 	claims := jwt.MapClaims{
 		"user_id": strconv.Itoa(userID),
-		"exp":     time.Now().Add(a.expiry).Unix(),
+		"jti":     access.TokenID,
+		"sid":     access.SessionID,
+		"iat":     now.Unix(),
+		"exp":     access.ExpiresAt.Unix(),
 	}
 
 	// Create token
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	// Sign token
-	return token.SignedString(a.secretKey)
+	signed, err := token.SignedString(a.secretKey)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, access, nil
+}
+
+// ParseToken validates an access token and returns its claims. It does not
+// consult the revocation list.
+func (a *JWTAuth) ParseToken(tokenString string) (*domain.AccessClaims, error) {
+	claims, err := a.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDStr, _ := claims["user_id"].(string)
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		return nil, errors.New("invalid user_id claim")
+	}
+	tokenID, _ := claims["jti"].(string)
+	if tokenID == "" {
+		return nil, errors.New("missing jti claim")
+	}
+	// Tokens from before sessions were tracked have no sid
+	sessionID, _ := claims["sid"].(string)
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, errors.New("missing exp claim")
+	}
+
+	return &domain.AccessClaims{
+		UserID:    userID,
+		TokenID:   tokenID,
+		SessionID: sessionID,
+		ExpiresAt: time.Unix(int64(exp), 0),
+	}, nil
 }
 
 func (a *JWTAuth) ValidateToken(tokenString string) (jwt.MapClaims, error) {
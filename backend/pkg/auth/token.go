@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// NewOpaqueToken returns a random token to hand to a client, such as a
+// refresh token, and the hash to store in its place.
+func NewOpaqueToken() (token, hash string, err error) {
+	token, err = randomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	return token, HashToken(token), nil
+}
+
+// NewTokenID returns a random identifier for a token or a family of
+// tokens, such as a jti.
+func NewTokenID() (string, error) {
+	return randomToken(16)
+}
+
+// HashToken returns the hash an opaque token is stored and looked up by.
+// The tokens are random enough that a fast, unsalted hash is safe.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomToken returns n random bytes, URL-safe base64 encoded.
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters. They are the defaults of RFC 6238, the only ones every
+// authenticator app supports.
+const (
+	TOTPPeriod = 30 * time.Second
+	TOTPDigits = 6
+)
+
+// totpSecretSize is the size of a TOTP secret in bytes, the length of an
+// HMAC-SHA1 key that RFC 4226 recommends.
+const totpSecretSize = 20
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewTOTPSecret returns a random TOTP secret, base32 encoded as
+// authenticator apps expect it.
+func NewTOTPSecret() (string, error) {
+	b := make([]byte, totpSecretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPURI returns the otpauth:// URI that enrolls the secret in an
+// authenticator app, which is also what a QR code for it encodes.
+func TOTPURI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(TOTPDigits))
+	params.Set("period", fmt.Sprint(int(TOTPPeriod.Seconds())))
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// TOTPStep returns the time step t falls in.
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / int64(TOTPPeriod.Seconds())
+}
+
+// TOTPCode returns the code of the secret for a time step.
+func TOTPCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation, RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	modulus := uint32(1)
+	for i := 0; i < TOTPDigits; i++ {
+		modulus *= 10
+	}
+	return fmt.Sprintf("%0*d", TOTPDigits, value%modulus), nil
+}
+
+// MatchTOTP checks a code against the time step of t and skew steps on
+// either side of it, for clocks that are a little off. It returns the step
+// the code belongs to, which callers record so the code cannot be used
+// again.
+func MatchTOTP(secret, code string, t time.Time, skew int) (int64, bool) {
+	code = strings.Join(strings.Fields(code), "")
+	if len(code) != TOTPDigits {
+		return 0, false
+	}
+
+	now := TOTPStep(t)
+	for i := -skew; i <= skew; i++ {
+		step := now + int64(i)
+		want, err := TOTPCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// recoveryAlphabet leaves out letters and digits that are easily mistaken
+// for each other.
+const recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// NewRecoveryCode returns a random MFA recovery code such as
+// "k3xq7-mw2pa", about 50 bits strong.
+func NewRecoveryCode() (string, error) {
+	code := make([]byte, 0, 11)
+	b := make([]byte, 1)
+	for len(code) < 11 {
+		if len(code) == 5 {
+			code = append(code, '-')
+			continue
+		}
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		// Bytes past the last whole multiple of the alphabet would favour
+		// its first letters
+		if int(b[0]) >= 256-256%len(recoveryAlphabet) {
+			continue
+		}
+		code = append(code, recoveryAlphabet[int(b[0])%len(recoveryAlphabet)])
+	}
+	return string(code), nil
+}
+
+// NormalizeRecoveryCode drops the case, spaces and dashes a user may type
+// a recovery code with, leaving what its hash is taken of.
+func NormalizeRecoveryCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+}
@@ -0,0 +1,166 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// canonical re-encodes a JSON document so that documents can be compared
+// regardless of member order and spacing.
+func canonical(t *testing.T, doc string) string {
+	t.Helper()
+	value, err := decode([]byte(doc))
+	if err != nil {
+		t.Fatalf("invalid JSON %s: %v", doc, err)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(encoded)
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name       string
+		doc, patch string
+		want       string // empty when the patch must fail
+	}{
+		{"add member", `{"a":1}`, `[{"op":"add","path":"/b","value":2}]`, `{"a":1,"b":2}`},
+		{"add replaces member", `{"a":1}`, `[{"op":"add","path":"/a","value":2}]`, `{"a":2}`},
+		{"add into array", `{"a":[1,3]}`, `[{"op":"add","path":"/a/1","value":2}]`, `{"a":[1,2,3]}`},
+		{"add to array end", `{"a":[1]}`, `[{"op":"add","path":"/a/-","value":2}]`, `{"a":[1,2]}`},
+		{"add past array end", `{"a":[1]}`, `[{"op":"add","path":"/a/2","value":2}]`, ""},
+		{"add under missing parent", `{}`, `[{"op":"add","path":"/a/b","value":1}]`, ""},
+		{"add whole document", `{"a":1}`, `[{"op":"add","path":"","value":[1]}]`, `[1]`},
+		{"add null", `{"a":1}`, `[{"op":"add","path":"/b","value":null}]`, `{"a":1,"b":null}`},
+		{"add without value", `{}`, `[{"op":"add","path":"/a"}]`, ""},
+
+		{"remove member", `{"a":1,"b":2}`, `[{"op":"remove","path":"/a"}]`, `{"b":2}`},
+		{"remove array element", `{"a":[1,2,3]}`, `[{"op":"remove","path":"/a/1"}]`, `{"a":[1,3]}`},
+		{"remove missing member", `{}`, `[{"op":"remove","path":"/a"}]`, ""},
+		{"remove array end", `{"a":[1]}`, `[{"op":"remove","path":"/a/-"}]`, ""},
+		{"remove whole document", `{}`, `[{"op":"remove","path":""}]`, ""},
+
+		{"replace member", `{"a":1}`, `[{"op":"replace","path":"/a","value":"x"}]`, `{"a":"x"}`},
+		{"replace with null", `{"image_id":7}`, `[{"op":"replace","path":"/image_id","value":null}]`, `{"image_id":null}`},
+		{"replace missing member", `{}`, `[{"op":"replace","path":"/a","value":1}]`, ""},
+		{"replace without value", `{"a":1}`, `[{"op":"replace","path":"/a"}]`, ""},
+		{"replace whole document", `{"a":1}`, `[{"op":"replace","path":"","value":{"b":2}}]`, `{"b":2}`},
+
+		{"move member", `{"a":1}`, `[{"op":"move","from":"/a","path":"/b"}]`, `{"b":1}`},
+		{"move array element", `{"a":[1,2,3]}`, `[{"op":"move","from":"/a/0","path":"/a/-"}]`, `{"a":[2,3,1]}`},
+		{"move into itself", `{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a/b/c"}]`, ""},
+		{"move missing member", `{}`, `[{"op":"move","from":"/a","path":"/b"}]`, ""},
+
+		{"copy member", `{"a":{"x":1}}`, `[{"op":"copy","from":"/a","path":"/b"}]`, `{"a":{"x":1},"b":{"x":1}}`},
+		{"copy is not shared", `{"a":{"x":1}}`, `[{"op":"copy","from":"/a","path":"/b"},{"op":"replace","path":"/b/x","value":2}]`, `{"a":{"x":1},"b":{"x":2}}`},
+
+		{"test equal", `{"a":[1,{"b":"c"}]}`, `[{"op":"test","path":"/a","value":[1,{"b":"c"}]}]`, `{"a":[1,{"b":"c"}]}`},
+		{"test numbers by value", `{"a":1}`, `[{"op":"test","path":"/a","value":1.0}]`, `{"a":1}`},
+		{"test exponent", `{"a":100}`, `[{"op":"test","path":"/a","value":1e2}]`, `{"a":100}`},
+		{"test null", `{"a":null}`, `[{"op":"test","path":"/a","value":null}]`, `{"a":null}`},
+		{"test different", `{"a":1}`, `[{"op":"test","path":"/a","value":2}]`, ""},
+		{"test number against string", `{"a":1}`, `[{"op":"test","path":"/a","value":"1"}]`, ""},
+		{"test missing member", `{}`, `[{"op":"test","path":"/a","value":null}]`, ""},
+
+		{"escaped slash", `{"a/b":1}`, `[{"op":"replace","path":"/a~1b","value":2}]`, `{"a/b":2}`},
+		{"escaped tilde", `{"a~b":1}`, `[{"op":"remove","path":"/a~0b"}]`, `{}`},
+		{"escapes decode once", `{"~1":1}`, `[{"op":"test","path":"/~01","value":1}]`, `{"~1":1}`},
+		{"pointer without slash", `{"a":1}`, `[{"op":"remove","path":"a"}]`, ""},
+		{"leading zero index", `{"a":[1,2]}`, `[{"op":"remove","path":"/a/01"}]`, ""},
+
+		{"failure undoes earlier operations", `{"a":1}`, `[{"op":"add","path":"/b","value":2},{"op":"test","path":"/a","value":3}]`, ""},
+		{"unknown operation", `{}`, `[{"op":"frobnicate","path":"/a"}]`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.doc), []byte(tt.patch))
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("Apply = %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if string(got) != canonical(t, tt.want) {
+				t.Errorf("Apply = %s, want %s", got, canonical(t, tt.want))
+			}
+		})
+	}
+}
+
+func TestApplyTestFailed(t *testing.T) {
+	_, err := Apply([]byte(`{"a":1}`), []byte(`[{"op":"test","path":"/a","value":2}]`))
+	if !errors.Is(err, ErrTestFailed) {
+		t.Errorf("Apply = %v, want ErrTestFailed", err)
+	}
+}
+
+func TestOperationHasValue(t *testing.T) {
+	tests := []struct {
+		op        string
+		hasValue  bool
+		wantValue string
+	}{
+		{`{"op":"remove","path":"/a"}`, false, ""},
+		{`{"op":"replace","path":"/a","value":null}`, true, "null"},
+		{`{"op":"replace","path":"/a","value":0}`, true, "0"},
+	}
+	for _, tt := range tests {
+		var op Operation
+		if err := json.Unmarshal([]byte(tt.op), &op); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", tt.op, err)
+		}
+		if op.HasValue != tt.hasValue || string(op.Value) != tt.wantValue {
+			t.Errorf("Unmarshal(%s) = HasValue %v, Value %q; want %v, %q", tt.op, op.HasValue, op.Value, tt.hasValue, tt.wantValue)
+		}
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// The examples of RFC 7396, appendix A
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		// Numbers keep their exact text
+		{`{"due":12345678901234567890}`, `{"title":"x"}`, `{"due":12345678901234567890,"title":"x"}`},
+	}
+	for _, tt := range tests {
+		got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+		if err != nil {
+			t.Errorf("MergePatch(%s, %s): %v", tt.doc, tt.patch, err)
+			continue
+		}
+		if string(got) != canonical(t, tt.want) {
+			t.Errorf("MergePatch(%s, %s) = %s, want %s", tt.doc, tt.patch, got, canonical(t, tt.want))
+		}
+	}
+}
+
+func TestMergePatchInvalid(t *testing.T) {
+	if _, err := MergePatch([]byte(`{}`), []byte(`{"a":`)); err == nil {
+		t.Error("MergePatch accepted an invalid patch")
+	}
+	if _, err := MergePatch([]byte(`{} {}`), []byte(`{}`)); err == nil {
+		t.Error("MergePatch accepted a document with trailing data")
+	}
+}
@@ -0,0 +1,87 @@
+// Package rank generates lexicographic ranks for manually ordered items.
+//
+// A rank is a string of base-62 digits read as a fraction between 0 and 1,
+// so a new rank can always be found between two others and moving one item
+// only rewrites that item. Ranks compare with plain byte order, which in
+// Postgres means a column with COLLATE "C".
+package rank
+
+import "fmt"
+
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+// Between returns a rank that sorts after a and before b. An empty a means
+// "the start" and an empty b means "the end", so Between("", "") gives the
+// rank of the first item in an empty list.
+func Between(a, b string) (string, error) {
+	if err := Validate(a); err != nil {
+		return "", err
+	}
+	if err := Validate(b); err != nil {
+		return "", err
+	}
+	if b != "" && a >= b {
+		return "", fmt.Errorf("rank %q is not before %q", a, b)
+	}
+
+	out := make([]byte, 0, len(a)+1)
+	upperFree := b == ""
+	for i := 0; ; i++ {
+		lo := 0
+		if i < len(a) {
+			lo = index(a[i])
+		}
+		hi := base
+		if !upperFree {
+			if i >= len(b) {
+				// b is a followed by zeros: equal as fractions
+				return "", fmt.Errorf("no rank between %q and %q", a, b)
+			}
+			hi = index(b[i])
+		}
+
+		if hi-lo > 1 {
+			d := (lo + hi) / 2
+			// Step by one at an open end so that repeatedly appending or
+			// prepending grows ranks slowly
+			switch {
+			case b == "" && i < len(a):
+				d = lo + 1
+			case a == "" && b != "":
+				d = hi - 1
+			}
+			return string(append(out, digits[d])), nil
+		}
+
+		// No room at this digit: keep a's digit and look further. Once we
+		// are below b's digit any longer suffix stays below b.
+		out = append(out, digits[lo])
+		if hi > lo {
+			upperFree = true
+		}
+	}
+}
+
+// Validate checks that s only contains rank digits.
+func Validate(s string) error {
+	for i := 0; i < len(s); i++ {
+		if index(s[i]) < 0 {
+			return fmt.Errorf("invalid rank %q", s)
+		}
+	}
+	return nil
+}
+
+func index(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'A' && c <= 'Z':
+		return int(c-'A') + 10
+	case c >= 'a' && c <= 'z':
+		return int(c-'a') + 36
+	}
+	return -1
+}
@@ -0,0 +1,113 @@
+package rank
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{"empty list", "", "", "V"},
+		{"append", "V", "", "W"},
+		{"append at the last digit", "z", "", "zV"},
+		{"prepend", "", "V", "U"},
+		{"prepend at the first digit", "", "1", "0z"},
+		{"middle", "A", "C", "B"},
+		{"adjacent digits", "A", "B", "AV"},
+		{"a is a prefix of b", "A", "AB", "A5"},
+		{"b is longer", "A", "B5", "AV"},
+		{"a is longer", "AzZ", "B", "Azm"},
+		{"long keys", "0000000001", "0000000002", "0000000001V"},
+		{"long adjacent keys", "zzzzzzzzzy", "zzzzzzzzzz", "zzzzzzzzzyV"},
+		{"append to a long key", "zzzzzzzzzz", "", "zzzzzzzzzzV"},
+	}
+	for _, tt := range tests {
+		got, err := Between(tt.a, tt.b)
+		if err != nil {
+			t.Errorf("%s: Between(%q, %q): %v", tt.name, tt.a, tt.b, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: Between(%q, %q) = %q, want %q", tt.name, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestBetweenErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{"equal", "V", "V"},
+		{"reversed", "W", "V"},
+		{"equal long keys", "zzzzzzzzzz", "zzzzzzzzzz"},
+		{"equal as fractions", "V", "V0"},
+		{"invalid a", "V-", ""},
+		{"invalid b", "", "é"},
+	}
+	for _, tt := range tests {
+		if got, err := Between(tt.a, tt.b); err == nil {
+			t.Errorf("%s: Between(%q, %q) = %q, want an error", tt.name, tt.a, tt.b, got)
+		}
+	}
+}
+
+// TestBetweenOrders checks that ranks generated by repeatedly inserting at
+// random places stay strictly ordered, which is what the manual order of a
+// list relies on.
+func TestBetweenOrders(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	ranks := []string{}
+	for i := 0; i < 2000; i++ {
+		at := random.Intn(len(ranks) + 1)
+		a, b := "", ""
+		if at > 0 {
+			a = ranks[at-1]
+		}
+		if at < len(ranks) {
+			b = ranks[at]
+		}
+		r, err := Between(a, b)
+		if err != nil {
+			t.Fatalf("Between(%q, %q): %v", a, b, err)
+		}
+		if r <= a || (b != "" && r >= b) {
+			t.Fatalf("Between(%q, %q) = %q, not between them", a, b, r)
+		}
+		if strings.HasSuffix(r, "0") {
+			t.Fatalf("Between(%q, %q) = %q, which ends in a zero", a, b, r)
+		}
+		ranks = append(ranks[:at], append([]string{r}, ranks[at:]...)...)
+	}
+}
+
+// TestBetweenGrowth checks that appending or prepending many items keeps
+// ranks short.
+func TestBetweenGrowth(t *testing.T) {
+	last := ""
+	for i := 0; i < 1000; i++ {
+		var err error
+		if last, err = Between(last, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(last) > 40 {
+		t.Errorf("1000 appends grew the rank to %d digits", len(last))
+	}
+
+	first := ""
+	for i := 0; i < 1000; i++ {
+		var err error
+		if first, err = Between("", first); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(first) > 40 {
+		t.Errorf("1000 prepends grew the rank to %d digits", len(first))
+	}
+}
@@ -0,0 +1,408 @@
+// Package rrule implements the subset of RFC 5545 recurrence rules used by
+// recurring todos: FREQ, INTERVAL, BYDAY, BYMONTHDAY, COUNT and UNTIL.
+//
+// The package has no dependencies outside the standard library so the
+// expansion logic can be exercised without a database.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// maxPeriods bounds how many empty periods Next will scan before deciding a
+// rule has no further occurrences (e.g. BYMONTHDAY=31;BYDAY=MO can take a
+// long time to line up, but never this long).
+const maxPeriods = 5000
+
+// maxInterval bounds INTERVAL. Larger steps are not useful for todos and
+// would run dates far past maxYear within a few periods.
+const maxInterval = 1000
+
+// maxYear is the last year an occurrence may fall in; series end there, as
+// four-digit years are all that RRULE dates can express.
+const maxYear = 9999
+
+// WeekdayNum is a BYDAY entry such as "MO", "2TU" or "-1FR". Ordinal 0 means
+// every such weekday in the period.
+type WeekdayNum struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// Rule is a parsed RRULE.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	Count      int
+	Until      *time.Time
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Parse parses an RRULE value such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE".
+// A leading "RRULE:" is accepted and ignored.
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, errors.New("rrule: empty rule")
+	}
+
+	rule := &Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("rrule: malformed part %q", part)
+		}
+		key = strings.ToUpper(key)
+		if seen[key] {
+			return nil, fmt.Errorf("rrule: %s given more than once", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			rule.Freq = Frequency(strings.ToUpper(value))
+			switch rule.Freq {
+			case Daily, Weekly, Monthly, Yearly:
+			default:
+				err = fmt.Errorf("unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(value)
+			if err == nil && (rule.Interval < 1 || rule.Interval > maxInterval) {
+				err = fmt.Errorf("INTERVAL must be between 1 and %d", maxInterval)
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(value)
+			if err == nil && rule.Count < 1 {
+				err = errors.New("COUNT must be positive")
+			}
+		case "UNTIL":
+			var until time.Time
+			until, err = parseUntil(value)
+			rule.Until = &until
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseByMonthDay(value)
+		case "WKST":
+			// Weeks always start on Monday here, which is the RFC default.
+			if strings.ToUpper(value) != "MO" {
+				err = errors.New("only WKST=MO is supported")
+			}
+		default:
+			err = errors.New("unsupported part")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("rrule: %s: %w", key, err)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, errors.New("rrule: FREQ is required")
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, errors.New("rrule: COUNT and UNTIL are mutually exclusive")
+	}
+	if rule.Freq == Weekly && len(rule.ByMonthDay) > 0 {
+		return nil, errors.New("rrule: BYMONTHDAY cannot be used with FREQ=WEEKLY")
+	}
+	for _, wd := range rule.ByDay {
+		if wd.Ordinal != 0 && rule.Freq != Monthly && rule.Freq != Yearly {
+			return nil, errors.New("rrule: BYDAY ordinals require FREQ=MONTHLY or FREQ=YEARLY")
+		}
+	}
+
+	return rule, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// A date-only UNTIL includes the whole day.
+				t = t.Add(24*time.Hour - time.Nanosecond)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, item := range strings.Split(strings.ToUpper(value), ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid weekday %q", item)
+		}
+		weekday, ok := weekdayCodes[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", item)
+		}
+		var ordinal int
+		if prefix := item[:len(item)-2]; prefix != "" {
+			n, err := strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("invalid weekday ordinal %q", item)
+			}
+			ordinal = n
+		}
+		days = append(days, WeekdayNum{Ordinal: ordinal, Weekday: weekday})
+	}
+	return days, nil
+}
+
+func parseByMonthDay(value string) ([]int, error) {
+	var days []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil || n == 0 || n < -31 || n > 31 {
+			return nil, fmt.Errorf("invalid month day %q", item)
+		}
+		days = append(days, n)
+	}
+	return days, nil
+}
+
+// String formats the rule back into RRULE syntax.
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			code := strings.ToUpper(wd.Weekday.String()[:2])
+			if wd.Ordinal != 0 {
+				code = strconv.Itoa(wd.Ordinal) + code
+			}
+			codes[i] = code
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence strictly after from, treating from as
+// the DTSTART of the series. seen is the number of occurrences the series has
+// already produced (including from) and is checked against COUNT. The second
+// result is false once the series is exhausted.
+func (r *Rule) Next(from time.Time, seen int) (time.Time, bool) {
+	if r.Count > 0 && seen >= r.Count {
+		return time.Time{}, false
+	}
+
+	for i := 0; i < maxPeriods; i++ {
+		for _, occurrence := range r.candidates(from, i) {
+			if !occurrence.After(from) {
+				continue
+			}
+			if occurrence.Year() > maxYear || (r.Until != nil && occurrence.After(*r.Until)) {
+				return time.Time{}, false
+			}
+			return occurrence, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Expand lists up to limit occurrences of the series starting at dtstart.
+// dtstart itself is always the first occurrence, as in RFC 5545.
+func (r *Rule) Expand(dtstart time.Time, limit int) []time.Time {
+	var occurrences []time.Time
+	current := dtstart
+	for len(occurrences) < limit {
+		occurrences = append(occurrences, current)
+		next, ok := r.Next(current, len(occurrences))
+		if !ok {
+			break
+		}
+		current = next
+	}
+	return occurrences
+}
+
+// candidates returns the sorted occurrences in the period'th period after
+// the one containing dtstart, carrying dtstart's time of day and location.
+func (r *Rule) candidates(dtstart time.Time, period int) []time.Time {
+	loc := dtstart.Location()
+	hour, min, sec := dtstart.Clock()
+	nsec := dtstart.Nanosecond()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, hour, min, sec, nsec, loc)
+	}
+
+	var days []time.Time
+	step := period * r.Interval
+	switch r.Freq {
+	case Daily:
+		day := dtstart.AddDate(0, 0, step)
+		days = []time.Time{at(day.Year(), day.Month(), day.Day())}
+		days = r.limitByDay(r.limitByMonthDay(days))
+
+	case Weekly:
+		offset := (int(dtstart.Weekday()) + 6) % 7 // days since Monday
+		monday := dtstart.AddDate(0, 0, step*7-offset)
+		if len(r.ByDay) == 0 {
+			day := monday.AddDate(0, 0, offset)
+			days = []time.Time{at(day.Year(), day.Month(), day.Day())}
+			break
+		}
+		for i := 0; i < 7; i++ {
+			day := monday.AddDate(0, 0, i)
+			for _, wd := range r.ByDay {
+				if wd.Weekday == day.Weekday() {
+					days = append(days, at(day.Year(), day.Month(), day.Day()))
+					break
+				}
+			}
+		}
+
+	case Monthly:
+		first := time.Date(dtstart.Year(), dtstart.Month()+time.Month(step), 1, 0, 0, 0, 0, loc)
+		days = r.expandMonth(first.Year(), first.Month(), dtstart.Day(), at)
+
+	case Yearly:
+		year := dtstart.Year() + step
+		switch {
+		case len(r.ByMonthDay) > 0:
+			for m := time.January; m <= time.December; m++ {
+				days = append(days, r.expandMonth(year, m, 0, at)...)
+			}
+		case len(r.ByDay) > 0:
+			days = r.weekdaysIn(at(year, time.January, 1), at(year+1, time.January, 1), at)
+		default:
+			// Feb 29 only occurs in leap years.
+			if day := at(year, dtstart.Month(), dtstart.Day()); day.Day() == dtstart.Day() {
+				days = []time.Time{day}
+			}
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+// expandMonth returns the matching days of one month. defaultDay is used
+// when neither BYMONTHDAY nor BYDAY is set.
+func (r *Rule) expandMonth(year int, month time.Month, defaultDay int, at func(int, time.Month, int) time.Time) []time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	switch {
+	case len(r.ByMonthDay) > 0:
+		var days []time.Time
+		for _, d := range r.ByMonthDay {
+			if d < 0 {
+				d = last + d + 1
+			}
+			if d >= 1 && d <= last {
+				days = append(days, at(year, month, d))
+			}
+		}
+		// BYDAY limits BYMONTHDAY when both are present.
+		return r.limitByDay(days)
+	case len(r.ByDay) > 0:
+		return r.weekdaysIn(at(year, month, 1), at(year, month+1, 1), at)
+	default:
+		if defaultDay > last {
+			return nil
+		}
+		return []time.Time{at(year, month, defaultDay)}
+	}
+}
+
+// weekdaysIn expands BYDAY within [start, end), honouring ordinals relative
+// to that range.
+func (r *Rule) weekdaysIn(start, end time.Time, at func(int, time.Month, int) time.Time) []time.Time {
+	var days []time.Time
+	for _, wd := range r.ByDay {
+		var matches []time.Time
+		for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+			if day.Weekday() == wd.Weekday {
+				matches = append(matches, at(day.Year(), day.Month(), day.Day()))
+			}
+		}
+		switch {
+		case wd.Ordinal == 0:
+			days = append(days, matches...)
+		case wd.Ordinal > 0 && wd.Ordinal <= len(matches):
+			days = append(days, matches[wd.Ordinal-1])
+		case wd.Ordinal < 0 && -wd.Ordinal <= len(matches):
+			days = append(days, matches[len(matches)+wd.Ordinal])
+		}
+	}
+	return days
+}
+
+func (r *Rule) limitByDay(days []time.Time) []time.Time {
+	if len(r.ByDay) == 0 {
+		return days
+	}
+	var kept []time.Time
+	for _, day := range days {
+		for _, wd := range r.ByDay {
+			if wd.Ordinal == 0 && wd.Weekday == day.Weekday() {
+				kept = append(kept, day)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+func (r *Rule) limitByMonthDay(days []time.Time) []time.Time {
+	if len(r.ByMonthDay) == 0 {
+		return days
+	}
+	var kept []time.Time
+	for _, day := range days {
+		last := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		for _, d := range r.ByMonthDay {
+			if d == day.Day() || (d < 0 && last+d+1 == day.Day()) {
+				kept = append(kept, day)
+				break
+			}
+		}
+	}
+	return kept
+}
@@ -0,0 +1,215 @@
+package rrule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{"", "empty rule"},
+		{"INTERVAL=2", "FREQ is required"},
+		{"FREQ=HOURLY", "unsupported FREQ"},
+		{"FREQ=DAILY;INTERVAL=0", "INTERVAL must be between"},
+		{"FREQ=DAILY;INTERVAL=1000000000", "INTERVAL must be between"},
+		{"FREQ=DAILY;COUNT=0", "COUNT must be positive"},
+		{"FREQ=DAILY;COUNT=2;UNTIL=20240101", "mutually exclusive"},
+		{"FREQ=WEEKLY;BYMONTHDAY=1", "cannot be used with FREQ=WEEKLY"},
+		{"FREQ=WEEKLY;BYDAY=2MO", "ordinals require"},
+		{"FREQ=MONTHLY;BYMONTHDAY=32", "invalid month day"},
+		{"FREQ=DAILY;FREQ=WEEKLY", "more than once"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.rule)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %v, want it to contain %q", tt.rule, err, tt.want)
+		}
+	}
+}
+
+func TestParseString(t *testing.T) {
+	for _, s := range []string{
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+		"FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+		"FREQ=YEARLY;BYMONTHDAY=1,-1;UNTIL=20301231T000000Z",
+		"FREQ=DAILY;INTERVAL=1000",
+	} {
+		rule, err := Parse("RRULE:" + s)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", s, err)
+		}
+		if got := rule.String(); got != s {
+			t.Errorf("Parse(%q).String() = %q", s, got)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		limit   int
+		want    []string
+	}{
+		{
+			name:    "weekly by day",
+			rule:    "FREQ=WEEKLY;BYDAY=MO,WE,FR",
+			dtstart: time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC), // a Wednesday
+			limit:   5,
+			want:    []string{"2024-01-03", "2024-01-05", "2024-01-08", "2024-01-10", "2024-01-12"},
+		},
+		{
+			name:    "every other week",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+			dtstart: time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC),
+			limit:   3,
+			want:    []string{"2024-01-02", "2024-01-16", "2024-01-30"},
+		},
+		{
+			name:    "last friday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=-1FR",
+			dtstart: time.Date(2024, 1, 26, 9, 0, 0, 0, time.UTC),
+			limit:   3,
+			want:    []string{"2024-01-26", "2024-02-23", "2024-03-29"},
+		},
+		{
+			name:    "monthday 31 skips short months",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=31",
+			dtstart: time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC),
+			limit:   4,
+			want:    []string{"2024-01-31", "2024-03-31", "2024-05-31", "2024-07-31"},
+		},
+		{
+			name:    "monthly on the 31st without BYMONTHDAY",
+			rule:    "FREQ=MONTHLY",
+			dtstart: time.Date(2024, 8, 31, 9, 0, 0, 0, time.UTC),
+			limit:   3,
+			want:    []string{"2024-08-31", "2024-10-31", "2024-12-31"},
+		},
+		{
+			name:    "last day of the month",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=-1",
+			dtstart: time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC),
+			limit:   3,
+			want:    []string{"2024-01-31", "2024-02-29", "2024-03-31"},
+		},
+		{
+			name:    "leap day",
+			rule:    "FREQ=YEARLY",
+			dtstart: time.Date(2024, 2, 29, 9, 0, 0, 0, time.UTC),
+			limit:   3,
+			want:    []string{"2024-02-29", "2028-02-29", "2032-02-29"},
+		},
+		{
+			name:    "count includes dtstart",
+			rule:    "FREQ=DAILY;COUNT=3",
+			dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+			limit:   10,
+			want:    []string{"2024-01-01", "2024-01-02", "2024-01-03"},
+		},
+		{
+			name:    "date-only until includes the day",
+			rule:    "FREQ=DAILY;INTERVAL=2;UNTIL=20240105",
+			dtstart: time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC),
+			limit:   10,
+			want:    []string{"2024-01-01", "2024-01-03", "2024-01-05"},
+		},
+		{
+			name:    "until before the next occurrence",
+			rule:    "FREQ=WEEKLY;UNTIL=20240114T000000Z",
+			dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+			limit:   10,
+			want:    []string{"2024-01-01", "2024-01-08"},
+		},
+		{
+			name:    "ends at the last year",
+			rule:    "FREQ=YEARLY;INTERVAL=1000",
+			dtstart: time.Date(7024, 1, 1, 9, 0, 0, 0, time.UTC),
+			limit:   10,
+			want:    []string{"7024-01-01", "8024-01-01", "9024-01-01"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.rule, err)
+			}
+			got := rule.Expand(tt.dtstart, tt.limit)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expand = %v, want %v", dates(got), tt.want)
+			}
+			for i, occurrence := range got {
+				if occurrence.Format("2006-01-02") != tt.want[i] {
+					t.Fatalf("Expand = %v, want %v", dates(got), tt.want)
+				}
+				if h, m, _ := occurrence.Clock(); h != tt.dtstart.Hour() || m != tt.dtstart.Minute() {
+					t.Errorf("occurrence %d is at %02d:%02d, want the time of day of dtstart", i, h, m)
+				}
+			}
+		})
+	}
+
+	// Occurrences keep their wall-clock time across DST changes
+	for _, tt := range []struct {
+		name    string
+		dtstart time.Time
+	}{
+		{"spring forward", time.Date(2024, 3, 9, 9, 0, 0, 0, newYork)},
+		{"fall back", time.Date(2024, 11, 2, 9, 0, 0, 0, newYork)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse("FREQ=DAILY")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := rule.Expand(tt.dtstart, 3)
+			for i, occurrence := range got {
+				if occurrence.Hour() != 9 || occurrence.Location() != newYork {
+					t.Errorf("occurrence %d = %v, want 09:00 in New York", i, occurrence)
+				}
+			}
+			if gap := got[2].Sub(got[0]); gap == 48*time.Hour {
+				t.Errorf("occurrences across DST are %v apart, want an hour more or less", gap)
+			}
+		})
+	}
+}
+
+func TestNextExhausted(t *testing.T) {
+	rule, err := Parse("FREQ=DAILY;COUNT=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rule.Next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 2); ok {
+		t.Error("Next after COUNT occurrences reported another one")
+	}
+
+	// BYDAY limits BYMONTHDAY, so this waits for a Monday the 30th
+	rule, err = Parse("FREQ=MONTHLY;BYMONTHDAY=30;BYDAY=MO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, ok := rule.Next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 1)
+	if !ok || next.Format("2006-01-02") != "2024-09-30" {
+		t.Errorf("Next = %v, %v, want 2024-09-30", next, ok)
+	}
+}
+
+func dates(times []time.Time) []string {
+	out := make([]string, len(times))
+	for i, t := range times {
+		out[i] = t.Format("2006-01-02")
+	}
+	return out
+}
@@ -0,0 +1,148 @@
+// Package textmatch matches free-text queries against a title and a
+// description without a full-text index. It approximates the Postgres
+// full-text search, without stemming, for the adapters that have none.
+//
+// Every word of the query must be the prefix of a word of the title or
+// description; a title that is similar enough to the query also matches,
+// so a misspelt word still finds its document.
+package textmatch
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// MinTitleSimilarity is the similarity a title needs to match a query that
+// has no prefix hit.
+const MinTitleSimilarity = 0.4
+
+// Weights of a prefix hit in the title and in the description.
+const (
+	titleWeight       = 1.0
+	descriptionWeight = 0.4
+)
+
+// Rank reports whether query matches the title or description, and how
+// well. Higher ranks are better matches.
+func Rank(query, title, description string) (float64, bool) {
+	rank, ok := prefixRank(Words(query), title, description)
+	if similarity := TitleSimilarity(query, title); similarity >= MinTitleSimilarity {
+		ok = true
+		if similarity > rank {
+			rank = similarity
+		}
+	}
+	return rank, ok
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Words splits text into lowercase words of letters and digits.
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !isWordRune(r) })
+}
+
+// prefixRank reports whether every query word is a prefix of a word of the
+// title or description, and weighs the hits by where they are.
+func prefixRank(queryWords []string, title, description string) (float64, bool) {
+	if len(queryWords) == 0 {
+		return 0, false
+	}
+	titleWords := Words(title)
+	descriptionWords := Words(description)
+
+	var rank float64
+	for _, word := range queryWords {
+		switch {
+		case hasPrefixWord(titleWords, word):
+			rank += titleWeight
+		case hasPrefixWord(descriptionWords, word):
+			rank += descriptionWeight
+		default:
+			return 0, false
+		}
+	}
+	return rank / float64(len(queryWords)), true
+}
+
+func hasPrefixWord(words []string, prefix string) bool {
+	for _, word := range words {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// TitleSimilarity is the share of the query's trigrams found in the most
+// similar word of the title, a simplified pg_trgm word_similarity.
+func TitleSimilarity(query, title string) float64 {
+	want := trigrams(query)
+	if len(want) == 0 {
+		return 0
+	}
+
+	best := 0
+	for _, word := range Words(title) {
+		have := trigrams(word)
+		shared := 0
+		for trigram := range want {
+			if have[trigram] {
+				shared++
+			}
+		}
+		if shared > best {
+			best = shared
+		}
+	}
+	return float64(best) / float64(len(want))
+}
+
+// trigrams returns the trigrams of each word of text, padded the way
+// pg_trgm pads them.
+func trigrams(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range Words(text) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// Highlight returns text as HTML, escaped, with the words that start with
+// a word of query wrapped in <mark></mark>, the same snippet the Postgres
+// search returns.
+func Highlight(text, query string) string {
+	queryWords := Words(query)
+
+	var b strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		end := i
+		for end < len(runes) && isWordRune(runes[end]) == isWordRune(runes[i]) {
+			end++
+		}
+		run := html.EscapeString(string(runes[i:end]))
+		if isWordRune(runes[i]) && matchesAny(strings.ToLower(run), queryWords) {
+			b.WriteString("<mark>" + run + "</mark>")
+		} else {
+			b.WriteString(run)
+		}
+		i = end
+	}
+	return b.String()
+}
+
+func matchesAny(word string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
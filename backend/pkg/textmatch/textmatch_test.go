@@ -0,0 +1,21 @@
+package textmatch
+
+import "testing"
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		text, query, want string
+	}{
+		{"Buy milk", "mil", "Buy <mark>milk</mark>"},
+		{"Buy milk", "bread", "Buy milk"},
+		{"Milk, then more milk", "milk", "<mark>Milk</mark>, then more <mark>milk</mark>"},
+		{"<script>alert(1)</script>", "alert", "&lt;script&gt;<mark>alert</mark>(1)&lt;/script&gt;"},
+		{`Tom & "Jerry's"`, "jerry", `Tom &amp; &#34;<mark>Jerry</mark>&#39;s&#34;`},
+		{"Café au lait", "caf", "<mark>Café</mark> au lait"},
+	}
+	for _, tt := range tests {
+		if got := Highlight(tt.text, tt.query); got != tt.want {
+			t.Errorf("Highlight(%q, %q) = %q, want %q", tt.text, tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/ChaiyawutTar/MyList/internal/adapters/repositories/conformance"
+	"github.com/ChaiyawutTar/MyList/internal/adapters/repositories/memory"
+	"github.com/ChaiyawutTar/MyList/internal/adapters/repositories/postgres"
+	"github.com/ChaiyawutTar/MyList/internal/adapters/repositories/sqlite"
+	"github.com/ChaiyawutTar/MyList/internal/core/ports"
+	"github.com/ChaiyawutTar/MyList/pkg/migrate"
+)
+
+// storage is the set of repositories the services run on.
+type storage struct {
+	users         ports.UserRepository
+	refreshTokens ports.RefreshTokenRepository
+	revokedTokens ports.RevokedTokenRepository
+	sessions      ports.SessionRepository
+	oneTimeTokens ports.OneTimeTokenRepository
+	mfa           ports.MFARepository
+	recoveryCodes ports.RecoveryCodeRepository
+	todos         ports.TodoRepository
+	revisions     ports.TodoRevisionRepository
+	searcher      ports.TodoSearcher
+	lists         ports.ListRepository
+	tags          ports.TagRepository
+	checklist     ports.ChecklistRepository
+	workflows     ports.WorkflowRepository
+	images        ports.ImageRepository
+	transactor    ports.Transactor
+}
+
+// database is the SQL database that DATABASE_URL names. A sqlite: URL
+// opens a SQLite file; anything else is handed to the Postgres driver.
+type database struct {
+	*sql.DB
+	sqlite bool
+}
+
+func openDatabase(databaseURL string) (*database, error) {
+	d := &database{sqlite: strings.HasPrefix(databaseURL, "sqlite:")}
+	var err error
+	if d.sqlite {
+		d.DB, err = sqlite.Open(databaseURL)
+	} else {
+		d.DB, err = sql.Open("postgres", databaseURL)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := d.Ping(); err != nil {
+		d.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *database) migrator() (*migrate.Migrator, error) {
+	if d.sqlite {
+		return sqlite.NewMigrator(d.DB)
+	}
+	return postgres.NewMigrator(d.DB)
+}
+
+func (d *database) storage() *storage {
+	if d.sqlite {
+		return sqliteStorage(d.DB)
+	}
+	return postgresStorage(d.DB)
+}
+
+func postgresStorage(db *sql.DB) *storage {
+	return &storage{
+		users:         postgres.NewUserRepository(db),
+		refreshTokens: postgres.NewRefreshTokenRepository(db),
+		revokedTokens: postgres.NewRevokedTokenRepository(db),
+		sessions:      postgres.NewSessionRepository(db),
+		oneTimeTokens: postgres.NewOneTimeTokenRepository(db),
+		mfa:           postgres.NewMFARepository(db),
+		recoveryCodes: postgres.NewRecoveryCodeRepository(db),
+		todos:         postgres.NewTodoRepository(db),
+		revisions:     postgres.NewTodoRevisionRepository(db),
+		searcher:      postgres.NewTodoSearcher(db),
+		lists:         postgres.NewListRepository(db),
+		tags:          postgres.NewTagRepository(db),
+		checklist:     postgres.NewChecklistRepository(db),
+		workflows:     postgres.NewWorkflowRepository(db),
+		images:        postgres.NewImageRepository(db),
+		transactor:    postgres.NewTransactor(db),
+	}
+}
+
+func sqliteStorage(db *sql.DB) *storage {
+	return &storage{
+		users:         sqlite.NewUserRepository(db),
+		refreshTokens: sqlite.NewRefreshTokenRepository(db),
+		revokedTokens: sqlite.NewRevokedTokenRepository(db),
+		sessions:      sqlite.NewSessionRepository(db),
+		oneTimeTokens: sqlite.NewOneTimeTokenRepository(db),
+		mfa:           sqlite.NewMFARepository(db),
+		recoveryCodes: sqlite.NewRecoveryCodeRepository(db),
+		todos:         sqlite.NewTodoRepository(db),
+		revisions:     sqlite.NewTodoRevisionRepository(db),
+		searcher:      sqlite.NewTodoSearcher(db),
+		lists:         sqlite.NewListRepository(db),
+		tags:          sqlite.NewTagRepository(db),
+		checklist:     sqlite.NewChecklistRepository(db),
+		workflows:     sqlite.NewWorkflowRepository(db),
+		images:        sqlite.NewImageRepository(db),
+		transactor:    sqlite.NewTransactor(db),
+	}
+}
+
+// memoryStorage returns empty in-memory repositories.
+func memoryStorage() *storage {
+	store := memory.NewStore()
+	return &storage{
+		users:         memory.NewUserRepository(store),
+		refreshTokens: memory.NewRefreshTokenRepository(store),
+		revokedTokens: memory.NewRevokedTokenRepository(store),
+		sessions:      memory.NewSessionRepository(store),
+		oneTimeTokens: memory.NewOneTimeTokenRepository(store),
+		mfa:           memory.NewMFARepository(store),
+		recoveryCodes: memory.NewRecoveryCodeRepository(store),
+		todos:         memory.NewTodoRepository(store),
+		revisions:     memory.NewTodoRevisionRepository(store),
+		searcher:      memory.NewTodoSearcher(store),
+		lists:         memory.NewListRepository(store),
+		tags:          memory.NewTagRepository(store),
+		checklist:     memory.NewChecklistRepository(store),
+		workflows:     memory.NewWorkflowRepository(store),
+		images:        memory.NewImageRepository(store),
+		transactor:    memory.NewTransactor(store),
+	}
+}
+
+func (s *storage) conformance() conformance.Repositories {
+	return conformance.Repositories{
+		Users:         s.users,
+		RefreshTokens: s.refreshTokens,
+		RevokedTokens: s.revokedTokens,
+		Sessions:      s.sessions,
+		OneTimeTokens: s.oneTimeTokens,
+		MFA:           s.mfa,
+		RecoveryCodes: s.recoveryCodes,
+		Todos:         s.todos,
+		Revisions:     s.revisions,
+		Searcher:      s.searcher,
+		Lists:         s.lists,
+		Tags:          s.tags,
+		Checklist:     s.checklist,
+		Workflows:     s.workflows,
+		Images:        s.images,
+		Transactor:    s.transactor,
+	}
+}